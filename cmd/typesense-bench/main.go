@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+
+	benchx "github.com/foomo/typesense/pkg/bench"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		server      = flag.String("server", "http://localhost:8108", "typesense server url")
+		apiKey      = flag.String("api-key", os.Getenv("TYPESENSE_API_KEY"), "typesense api key, defaults to $TYPESENSE_API_KEY")
+		schemaFile  = flag.String("schema", "", "path to a collection schema json file")
+		documents   = flag.Int("documents", 10000, "number of synthetic documents to import")
+		batchSize   = flag.Int("batch-size", 100, "number of documents per import request")
+		searches    = flag.Int("searches", 1000, "number of searches to run after the import")
+		concurrency = flag.Int("concurrency", 4, "number of parallel requests")
+		queryBy     = flag.String("query-by", "", "fields to search, defaults to all string fields")
+		seed        = flag.Uint64("seed", 1, "seed for the synthetic documents and queries")
+		keep        = flag.Bool("keep", false, "keep the benchmark collection")
+	)
+	flag.Parse()
+
+	l, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	if *schemaFile == "" {
+		l.Fatal("missing -schema")
+	}
+	data, err := os.ReadFile(*schemaFile)
+	if err != nil {
+		l.Fatal("failed to read schema", zap.Error(err))
+	}
+	var schema api.CollectionSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		l.Fatal("failed to parse schema", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := typesense.NewClient(
+		typesense.WithServer(*server),
+		typesense.WithAPIKey(*apiKey),
+	)
+
+	report, err := benchx.NewBench(l, client, benchx.Config{
+		Schema:         &schema,
+		Documents:      *documents,
+		BatchSize:      *batchSize,
+		Searches:       *searches,
+		Concurrency:    *concurrency,
+		QueryBy:        *queryBy,
+		Seed:           *seed,
+		KeepCollection: *keep,
+	}).Run(ctx)
+	if err != nil {
+		l.Fatal("benchmark failed", zap.Error(err))
+	}
+
+	l.Info("import",
+		zap.Int("documents", report.Documents),
+		zap.Float64("documents_per_second", report.DocumentsPerSecond),
+		zap.Any("stats", report.Import),
+	)
+	l.Info("search", zap.Any("stats", report.Search))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	devseedx "github.com/foomo/typesense/pkg/devseed"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		server = flag.String("server", "http://localhost:8108", "typesense server url")
+		apiKey = flag.String("api-key", os.Getenv("TYPESENSE_API_KEY"), "typesense api key, defaults to $TYPESENSE_API_KEY")
+		dir    = flag.String("dir", "fixtures", "directory containing <index>.schema.json and <index>.jsonl files")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := typesense.NewClient(
+		typesense.WithServer(*server),
+		typesense.WithAPIKey(*apiKey),
+	)
+
+	if err := devseedx.Seed(ctx, l, client, *dir); err != nil {
+		l.Fatal("failed to seed typesense", zap.Error(err))
+	}
+	l.Info("seeded typesense", zap.String("dir", *dir))
+}
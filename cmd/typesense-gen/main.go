@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	genx "github.com/foomo/typesense/pkg/gen"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		definition = flag.String("definition", "indices.yaml", "index definition file (.yaml, .yml or .json)")
+		out        = flag.String("out", "indices_gen.go", "generated Go file")
+		pkg        = flag.String("package", "", "overrides the package of the definition")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	def, err := genx.Load(*definition)
+	if err != nil {
+		l.Fatal("failed to load definition", zap.Error(err))
+	}
+	if *pkg != "" {
+		def.Package = *pkg
+	}
+
+	source, err := genx.Generate(def)
+	if err != nil {
+		l.Fatal("failed to generate code", zap.Error(err))
+	}
+	if err := os.WriteFile(*out, source, 0o600); err != nil {
+		l.Fatal("failed to write generated code", zap.Error(err))
+	}
+	l.Info("generated index definitions", zap.String("definition", *definition), zap.String("out", *out))
+}
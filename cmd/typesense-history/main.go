@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	indexingx "github.com/foomo/typesense/pkg/indexing"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		server  = flag.String("server", "http://localhost:8108", "typesense server url")
+		apiKey  = flag.String("api-key", os.Getenv("TYPESENSE_API_KEY"), "typesense api key, defaults to $TYPESENSE_API_KEY")
+		limit   = flag.Int("limit", 20, "number of runs to list, at most 250")
+		index   = flag.String("index", "", "only list runs of the given index with its document counts")
+		success = flag.Bool("success", false, "only list successful runs")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := typesense.NewClient(
+		typesense.WithServer(*server),
+		typesense.WithAPIKey(*apiKey),
+	)
+
+	runs, err := indexingx.NewCollectionRunHistory(l, client).History(ctx, *limit)
+	if err != nil {
+		l.Fatal("failed to retrieve run history", zap.Error(err))
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "STARTED\tSTATUS\tREVISION\tDURATION\tDOCUMENTS\tFAILED\tERROR")
+	for _, run := range runs {
+		if *success && !run.Succeeded() {
+			continue
+		}
+		documents, failed := run.Documents, run.Failed
+		if *index != "" {
+			report, ok := run.Index(pkgx.IndexID(*index))
+			if !ok {
+				continue
+			}
+			documents, failed = report.Upserted, report.Failed
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			time.Unix(run.StartedAt, 0).Format(time.DateTime),
+			run.Status,
+			run.RevisionID,
+			run.Duration.Round(time.Second),
+			documents,
+			failed,
+			run.Error,
+		)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	genx "github.com/foomo/typesense/pkg/gen"
+	schemax "github.com/foomo/typesense/pkg/schema"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		definition = flag.String("definition", "indices.yaml", "index definition file (.yaml, .yml or .json)")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	def, err := genx.Load(*definition)
+	if err != nil {
+		l.Fatal("failed to load definition", zap.Error(err))
+	}
+	schemas, err := def.Schemas()
+	if err != nil {
+		l.Fatal("failed to build schemas", zap.Error(err))
+	}
+	presets, err := def.Presets()
+	if err != nil {
+		l.Fatal("failed to build presets", zap.Error(err))
+	}
+
+	findings := schemax.Lint(schemas, presets)
+	for _, finding := range findings {
+		fmt.Println(finding.String())
+	}
+	if len(findings) > 0 {
+		_ = l.Sync()
+		os.Exit(1)
+	}
+	l.Info("no lint findings", zap.String("definition", *definition))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	apix "github.com/foomo/typesense/pkg/api"
+	genx "github.com/foomo/typesense/pkg/gen"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		server     = flag.String("server", "http://localhost:8108", "typesense server url")
+		apiKey     = flag.String("api-key", os.Getenv("TYPESENSE_API_KEY"), "typesense api key, defaults to $TYPESENSE_API_KEY")
+		definition = flag.String("definition", "indices.yaml", "index definition file (.yaml, .yml or .json)")
+		canary     = flag.Bool("canary", false, "expect canary aliases")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	def, err := genx.Load(*definition)
+	if err != nil {
+		l.Fatal("failed to load definition", zap.Error(err))
+	}
+	schemas, err := def.Schemas()
+	if err != nil {
+		l.Fatal("failed to build schemas", zap.Error(err))
+	}
+	presets, err := def.Presets()
+	if err != nil {
+		l.Fatal("failed to build presets", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	collections := make(map[pkgx.IndexID]*api.CollectionSchema, len(schemas))
+	for name, schema := range schemas {
+		collections[pkgx.IndexID(name)] = schema
+	}
+
+	var opts []apix.Option
+	if *canary {
+		opts = append(opts, apix.WithCanaryAliases())
+	}
+	typesenseAPI := apix.NewBaseAPI[map[string]any, map[string]any](
+		l,
+		typesense.NewClient(
+			typesense.WithServer(*server),
+			typesense.WithAPIKey(*apiKey),
+		),
+		collections,
+		presets,
+		func(document map[string]any) map[string]any { return document },
+		opts...,
+	)
+	if err := typesenseAPI.Preflight(ctx); err != nil {
+		l.Fatal("preflight failed", zap.Error(err))
+	}
+	l.Info("preflight succeeded", zap.String("definition", *definition))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	apix "github.com/foomo/typesense/pkg/api"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		server     = flag.String("server", "http://localhost:8108", "typesense server url")
+		apiKey     = flag.String("api-key", os.Getenv("TYPESENSE_API_KEY"), "typesense api key, defaults to $TYPESENSE_API_KEY")
+		revisionID = flag.String("revision", "", "revision to point the aliases at, e.g. 2025-01-01-12-00")
+		indices    = flag.String("indices", "", "comma separated indices to repoint, defaults to all existing aliases")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	if *revisionID == "" {
+		l.Fatal("missing -revision")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := typesense.NewClient(
+		typesense.WithServer(*server),
+		typesense.WithAPIKey(*apiKey),
+	)
+
+	var indexIDs []string
+	if *indices != "" {
+		indexIDs = strings.Split(*indices, ",")
+	} else {
+		aliases, err := client.Aliases().Retrieve(ctx)
+		if err != nil {
+			l.Fatal("failed to retrieve aliases", zap.Error(err))
+		}
+		for _, alias := range aliases {
+			if alias.Name != nil && !strings.HasSuffix(*alias.Name, "-canary") {
+				indexIDs = append(indexIDs, *alias.Name)
+			}
+		}
+	}
+
+	collections := make(map[pkgx.IndexID]*api.CollectionSchema, len(indexIDs))
+	for _, indexID := range indexIDs {
+		collections[pkgx.IndexID(strings.TrimSpace(indexID))] = &api.CollectionSchema{}
+	}
+
+	typesenseAPI := apix.NewBaseAPI[map[string]any, map[string]any](
+		l,
+		client,
+		collections,
+		nil,
+		func(document map[string]any) map[string]any { return document },
+	)
+	if err := typesenseAPI.RepointAllAliases(ctx, pkgx.RevisionID(*revisionID)); err != nil {
+		l.Fatal("failed to repoint aliases", zap.Error(err))
+	}
+	l.Info("repointed aliases", zap.String("revision", *revisionID), zap.Strings("indices", indexIDs))
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	apix "github.com/foomo/typesense/pkg/api"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+func main() {
+	var (
+		server     = flag.String("server", "http://localhost:8108", "typesense server url")
+		apiKey     = flag.String("api-key", os.Getenv("TYPESENSE_API_KEY"), "typesense api key, defaults to $TYPESENSE_API_KEY")
+		indexID    = flag.String("index", "", "index of the slot, e.g. www-de")
+		slot       = flag.String("slot", "", "slot to assign or promote, e.g. a")
+		revisionID = flag.String("revision", "", "revision whose collection is assigned to the slot, e.g. 2025-01-01-12-00")
+		promote    = flag.Bool("promote", false, "point the main alias at the collection of the slot")
+		canary     = flag.Bool("canary", false, "realign the canary alias on promotion")
+	)
+	flag.Parse()
+
+	l, err := zap.NewDevelopment()
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = l.Sync() }()
+
+	if *indexID == "" || *slot == "" {
+		l.Fatal("missing -index or -slot")
+	}
+	if *promote == (*revisionID != "") {
+		l.Fatal("either -revision or -promote is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	index := pkgx.IndexID(*indexID)
+	opts := []apix.Option{apix.WithIndexSlots(map[pkgx.IndexID][]string{index: {*slot}})}
+	if *canary {
+		opts = append(opts, apix.WithCanaryAliases())
+	}
+	typesenseAPI := apix.NewBaseAPI[map[string]any, map[string]any](
+		l,
+		typesense.NewClient(
+			typesense.WithServer(*server),
+			typesense.WithAPIKey(*apiKey),
+		),
+		map[pkgx.IndexID]*api.CollectionSchema{index: {}},
+		nil,
+		func(document map[string]any) map[string]any { return document },
+		opts...,
+	)
+
+	if *promote {
+		if err := typesenseAPI.PromoteSlot(ctx, index, *slot); err != nil {
+			l.Fatal("failed to promote slot", zap.Error(err))
+		}
+		return
+	}
+	if err := typesenseAPI.AssignSlot(ctx, index, *slot, pkgx.RevisionID(*revisionID)); err != nil {
+		l.Fatal("failed to assign slot", zap.Error(err))
+	}
+}
@@ -0,0 +1,84 @@
+package typesense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMissingRoles is returned by the ACLFilterProvider for callers without roles if no default roles are configured
+	ErrMissingRoles = errors.New("missing roles")
+	// ErrIndexNotAllowed is returned by the ACLFilterProvider for indices it neither restricts nor lists as public
+	ErrIndexNotAllowed = errors.New("index not allowed")
+)
+
+type rolesContextKey struct{}
+
+// WithRoles attaches the roles or groups of the caller to the context, e.g. in the authentication middleware
+func WithRoles(ctx context.Context, roles ...string) context.Context {
+	return context.WithValue(ctx, rolesContextKey{}, roles)
+}
+
+// Roles returns the roles attached to the context
+func Roles(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesContextKey{}).([]string)
+	return roles
+}
+
+// ACLFilterProvider is an AccessFilterProvider restricting searches to documents whose access control list field,
+// e.g. typesenseschema.ACLField, contains one of the roles attached to the context with WithRoles.
+// Documents are only found if the provider lists at least one role of the caller in their ACL.
+type ACLFilterProvider struct {
+	field        string
+	defaultRoles []string
+	indices      map[IndexID]bool
+	public       map[IndexID]bool
+}
+
+// NewACLFilterProvider creates a provider for the given ACL field. Callers without roles get the default roles,
+// e.g. "public", and their searches fail with ErrMissingRoles if there are none. If indices are given, only they
+// are restricted and searches of other indices fail with ErrIndexNotAllowed unless they are listed with Public.
+func NewACLFilterProvider(field string, defaultRoles []string, indices ...IndexID) *ACLFilterProvider {
+	p := &ACLFilterProvider{
+		field:        field,
+		defaultRoles: defaultRoles,
+		public:       map[IndexID]bool{},
+	}
+	if len(indices) > 0 {
+		p.indices = make(map[IndexID]bool, len(indices))
+		for _, indexID := range indices {
+			p.indices[indexID] = true
+		}
+	}
+	return p
+}
+
+// Public lists indices whose searches are not restricted, e.g. public dimensions next to restricted ones
+func (p *ACLFilterProvider) Public(indices ...IndexID) *ACLFilterProvider {
+	for _, indexID := range indices {
+		p.public[indexID] = true
+	}
+	return p
+}
+
+func (p *ACLFilterProvider) AccessFilter(ctx context.Context, indexID IndexID) (string, error) {
+	if p.public[indexID] {
+		return "", nil
+	}
+	if p.indices != nil && !p.indices[indexID] {
+		return "", fmt.Errorf("%w: %s", ErrIndexNotAllowed, indexID)
+	}
+	roles := Roles(ctx)
+	if len(roles) == 0 {
+		roles = p.defaultRoles
+	}
+	if len(roles) == 0 {
+		return "", ErrMissingRoles
+	}
+	values := make([]any, len(roles))
+	for i, role := range roles {
+		values[i] = role
+	}
+	return NewFilterBuilder().In(p.field, values...).Build(), nil
+}
@@ -0,0 +1,355 @@
+package typesenseanalytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+const DefaultCollectionName = "search-analytics"
+
+// retrieveBatchSize limits the number of ids fetched with one filter query
+const retrieveBatchSize = 100
+
+type Event string
+
+const (
+	EventSearch     Event = "search"
+	EventZeroResult Event = "zero_result"
+	EventClick      Event = "click"
+)
+
+// Stat is a single aggregated analytics entry as stored in the analytics collection
+type Stat struct {
+	ID         string          `json:"id"`
+	Event      Event           `json:"event"`
+	IndexID    pkgx.IndexID    `json:"index"`
+	Query      string          `json:"query"`
+	DocumentID pkgx.DocumentID `json:"document_id"`
+	Count      int             `json:"count"`
+	LastSeen   int64           `json:"last_seen"`
+	// PositionSum is the sum of the clicked result positions, see AveragePosition
+	PositionSum int64 `json:"position_sum,omitempty"`
+}
+
+// AveragePosition returns the average position of the clicked result
+func (s Stat) AveragePosition() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.PositionSum) / float64(s.Count)
+}
+
+// Analytics aggregates search and click events in memory and persists them
+// into a dedicated typesense collection on Flush. Typesense can't increment counts atomically, Flush reads the stored
+// counts and writes the sums while holding a lock, configure a cluster wide Locker with WithLocker if several
+// replicas flush into the same collection.
+type Analytics struct {
+	l              *zap.Logger
+	client         *typesense.Client
+	collectionName string
+	opts           options
+	flushMu        sync.Mutex
+	mu             sync.Mutex
+	pending        map[string]*Stat
+}
+
+func NewAnalytics(
+	l *zap.Logger,
+	client *typesense.Client,
+	collectionName string,
+	opts ...Option,
+) *Analytics {
+	if collectionName == "" {
+		collectionName = DefaultCollectionName
+	}
+	return &Analytics{
+		l:              l,
+		client:         client,
+		collectionName: collectionName,
+		opts:           newOptions(opts...),
+		pending:        map[string]*Stat{},
+	}
+}
+
+// Initialize creates the analytics collection if it does not exist yet
+func (a *Analytics) Initialize(ctx context.Context) error {
+	collections, err := a.client.Collections().Retrieve(ctx)
+	if err != nil {
+		a.l.Error("failed to retrieve collections", zap.Error(err))
+		return err
+	}
+	for _, col := range collections {
+		if col.Name == a.collectionName {
+			return nil
+		}
+	}
+
+	_, err = a.client.Collections().Create(ctx, &api.CollectionSchema{
+		Name: a.collectionName,
+		Fields: []api.Field{
+			{Name: "event", Type: "string", Facet: pointer.True()},
+			{Name: "index", Type: "string", Facet: pointer.True()},
+			{Name: "query", Type: "string"},
+			{Name: "document_id", Type: "string", Facet: pointer.True()},
+			{Name: "count", Type: "int32", Sort: pointer.True()},
+			{Name: "last_seen", Type: "int64", Sort: pointer.True()},
+		},
+		DefaultSortingField: pointer.String("count"),
+	})
+	if err != nil {
+		a.l.Error("failed to create analytics collection", zap.String("collection", a.collectionName), zap.Error(err))
+		return err
+	}
+	a.l.Info("created analytics collection", zap.String("collection", a.collectionName))
+	return nil
+}
+
+// RecordSearch aggregates a search query, queries without hits are additionally tracked as zero result queries
+func (a *Analytics) RecordSearch(_ context.Context, indexID pkgx.IndexID, query string, found int) {
+	query = normalizeQuery(query)
+	if query == "" || query == "*" {
+		return
+	}
+	a.record(EventSearch, indexID, query, "", 0)
+	if found == 0 {
+		a.record(EventZeroResult, indexID, query, "", 0)
+	}
+}
+
+// RecordClick aggregates a click on the search result at the given position
+func (a *Analytics) RecordClick(_ context.Context, indexID pkgx.IndexID, query string, documentID pkgx.DocumentID, position int) error {
+	if documentID == "" {
+		return errors.New("documentID must not be empty")
+	}
+	if position < 0 {
+		return errors.New("position must not be negative")
+	}
+	a.record(EventClick, indexID, normalizeQuery(query), documentID, position)
+	return nil
+}
+
+// Flush merges all pending events into the analytics collection, events that could not be written are requeued
+// for the next flush
+func (a *Analytics) Flush(ctx context.Context) error {
+	a.flushMu.Lock()
+	defer a.flushMu.Unlock()
+
+	a.mu.Lock()
+	pending := a.pending
+	a.pending = map[string]*Stat{}
+	a.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if a.opts.locker != nil {
+		unlock, err := a.opts.locker.Lock(ctx, "analytics-"+a.collectionName)
+		if err != nil {
+			a.l.Error("failed to lock analytics", zap.String("collection", a.collectionName), zap.Error(err))
+			a.requeue(pending)
+			return err
+		}
+		defer unlock()
+	}
+
+	ids := make([]string, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+
+	existing, err := a.retrieve(ctx, ids)
+	if err != nil {
+		a.requeue(pending)
+		return err
+	}
+
+	// the pending stats keep their deltas, so they can be requeued
+	documents := make([]interface{}, 0, len(pending))
+	for _, id := range ids {
+		merged := *pending[id]
+		if current, ok := existing[id]; ok {
+			merged.Count += current.Count
+			merged.PositionSum += current.PositionSum
+			merged.LastSeen = max(merged.LastSeen, current.LastSeen)
+		}
+		documents = append(documents, &merged)
+	}
+
+	results, err := a.client.Collection(a.collectionName).Documents().Import(ctx, documents, &api.ImportDocumentsParams{
+		Action: (*api.IndexAction)(pointer.String("upsert")),
+	})
+	if err != nil {
+		a.l.Error("failed to flush analytics", zap.String("collection", a.collectionName), zap.Error(err))
+		a.requeue(pending)
+		return err
+	}
+	failed := map[string]*Stat{}
+	for i, result := range results {
+		if !result.Success && i < len(ids) {
+			a.l.Warn("analytics document failed to upsert", zap.String("error", result.Error))
+			failed[ids[i]] = pending[ids[i]]
+		}
+	}
+	if len(failed) > 0 {
+		a.requeue(failed)
+		return fmt.Errorf("failed to flush %d of %d analytics documents", len(failed), len(documents))
+	}
+	a.l.Debug("flushed analytics", zap.Int("documents", len(documents)))
+	return nil
+}
+
+// FlushEvery flushes the pending events in the given interval until the context is done
+func (a *Analytics) FlushEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := a.Flush(context.WithoutCancel(ctx)); err != nil {
+				a.l.Error("failed to flush analytics on shutdown", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+			if err := a.Flush(ctx); err != nil {
+				a.l.Error("failed to flush analytics", zap.Error(err))
+			}
+		}
+	}
+}
+
+// TopQueries returns the most frequent search queries for the given index
+func (a *Analytics) TopQueries(ctx context.Context, indexID pkgx.IndexID, limit int) ([]Stat, error) {
+	return a.query(ctx, EventSearch, indexID, limit)
+}
+
+// ZeroResultQueries returns the most frequent search queries without any hits for the given index
+func (a *Analytics) ZeroResultQueries(ctx context.Context, indexID pkgx.IndexID, limit int) ([]Stat, error) {
+	return a.query(ctx, EventZeroResult, indexID, limit)
+}
+
+// TopClicks returns the most clicked documents per query for the given index
+func (a *Analytics) TopClicks(ctx context.Context, indexID pkgx.IndexID, limit int) ([]Stat, error) {
+	return a.query(ctx, EventClick, indexID, limit)
+}
+
+func (a *Analytics) query(ctx context.Context, event Event, indexID pkgx.IndexID, limit int) ([]Stat, error) {
+	if limit < 1 {
+		limit = 10
+	}
+	filterBy := fmt.Sprintf("event:=%s", event)
+	if indexID != "" {
+		filterBy += fmt.Sprintf(" && index:=`%s`", indexID)
+	}
+	return a.search(ctx, &api.SearchCollectionParams{
+		Q:        pointer.String("*"),
+		FilterBy: pointer.String(filterBy),
+		SortBy:   pointer.String("count:desc"),
+		PerPage:  pointer.Int(limit),
+	})
+}
+
+func (a *Analytics) retrieve(ctx context.Context, ids []string) (map[string]Stat, error) {
+	existing := make(map[string]Stat, len(ids))
+	for start := 0; start < len(ids); start += retrieveBatchSize {
+		end := min(start+retrieveBatchSize, len(ids))
+		stats, err := a.search(ctx, &api.SearchCollectionParams{
+			Q:        pointer.String("*"),
+			FilterBy: pointer.String("id:[" + strings.Join(ids[start:end], ",") + "]"),
+			PerPage:  pointer.Int(retrieveBatchSize),
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, stat := range stats {
+			existing[stat.ID] = stat
+		}
+	}
+	return existing, nil
+}
+
+func (a *Analytics) search(ctx context.Context, params *api.SearchCollectionParams) ([]Stat, error) {
+	response, err := a.client.Collection(a.collectionName).Documents().Search(ctx, params)
+	if err != nil {
+		a.l.Error("failed to search analytics", zap.String("collection", a.collectionName), zap.Error(err))
+		return nil, err
+	}
+	if response.Hits == nil {
+		return nil, nil
+	}
+
+	stats := make([]Stat, 0, len(*response.Hits))
+	for _, hit := range *response.Hits {
+		if hit.Document == nil {
+			continue
+		}
+		hitJSON, err := json.Marshal(*hit.Document)
+		if err != nil {
+			return nil, err
+		}
+		var stat Stat
+		if err := json.Unmarshal(hitJSON, &stat); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (a *Analytics) record(event Event, indexID pkgx.IndexID, query string, documentID pkgx.DocumentID, position int) {
+	id := statID(event, indexID, query, documentID)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stat, ok := a.pending[id]
+	if !ok {
+		stat = &Stat{
+			ID:         id,
+			Event:      event,
+			IndexID:    indexID,
+			Query:      query,
+			DocumentID: documentID,
+		}
+		a.pending[id] = stat
+	}
+	stat.Count++
+	stat.PositionSum += int64(position)
+	stat.LastSeen = a.opts.clock.Now().Unix()
+}
+
+// requeue puts back events that could not be flushed
+func (a *Analytics) requeue(stats map[string]*Stat) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for id, stat := range stats {
+		if current, ok := a.pending[id]; ok {
+			current.Count += stat.Count
+			current.PositionSum += stat.PositionSum
+			current.LastSeen = max(current.LastSeen, stat.LastSeen)
+		} else {
+			a.pending[id] = stat
+		}
+	}
+}
+
+func statID(event Event, indexID pkgx.IndexID, query string, documentID pkgx.DocumentID) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{string(event), string(indexID), query, string(documentID)}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
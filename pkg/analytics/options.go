@@ -0,0 +1,39 @@
+package typesenseanalytics
+
+import (
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// Option configures the Analytics
+type Option func(o *options)
+
+type options struct {
+	clock  pkgx.Clock
+	locker pkgx.Locker
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		clock: pkgx.SystemClock,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithClock replaces the system clock setting the last seen time of the events, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithLocker serializes the flushes of several replicas with a cluster wide lock, e.g. the typesenseapi.BaseAPI
+func WithLocker(locker pkgx.Locker) Option {
+	return func(o *options) {
+		o.locker = locker
+	}
+}
@@ -0,0 +1,53 @@
+package typesenseapi
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// AliasSwitch describes an alias that has been moved to the collection of another revision
+type AliasSwitch struct {
+	IndexID pkgx.IndexID
+	// OldCollection is the collection served before, "" if it is unknown
+	OldCollection string
+	NewCollection string
+	RevisionID    pkgx.RevisionID
+}
+
+// AliasSwitchFunc is called after an alias has been moved, e.g. to invalidate HTTP caches or CDN keys of search
+// responses. Errors are logged only, the alias stays moved.
+type AliasSwitchFunc func(ctx context.Context, event AliasSwitch) error
+
+// previousCollection returns the collection currently served by the alias if a callback needs it, "" otherwise
+func (b *BaseAPI[indexDocument, returnType]) previousCollection(ctx context.Context, indexID pkgx.IndexID) string {
+	if b.opts.onAliasSwitched == nil {
+		return ""
+	}
+	alias, err := b.client.Alias(string(indexID)).Retrieve(ctx)
+	if err != nil {
+		return ""
+	}
+	return alias.CollectionName
+}
+
+// aliasSwitched notifies the callback about the moved alias, aliases that kept their collection are skipped
+func (b *BaseAPI[indexDocument, returnType]) aliasSwitched(ctx context.Context, indexID pkgx.IndexID, oldCollection, newCollection string) {
+	if b.opts.onAliasSwitched == nil || oldCollection == newCollection {
+		return
+	}
+	event := AliasSwitch{
+		IndexID:       indexID,
+		OldCollection: oldCollection,
+		NewCollection: newCollection,
+		RevisionID:    extractRevisionID(newCollection, string(indexID)),
+	}
+	if err := b.opts.onAliasSwitched(ctx, event); err != nil {
+		pkgx.Logger(ctx, b.l).Warn("alias switch callback failed",
+			zap.String("alias", string(indexID)),
+			zap.String("collection", newCollection),
+			zap.Error(err),
+		)
+	}
+}
@@ -2,9 +2,11 @@ package typesenseapi
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	pkgx "github.com/foomo/typesense/pkg"
@@ -14,8 +16,23 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrRevisionExists is returned by NewRevision if collections of the generated revision ID exist already, e.g. for
+// a second run within the same minute
+var ErrRevisionExists = errors.New("revision exists")
+
 type DocumentConverter[indexDocument any, returnType any] func(indexDocument) returnType
 
+// DocumentSerializer converts an index document into the fields imported into typesense, e.g. to drop empty values,
+// rename fields or redact fields instead of relying on the JSON tags of the document
+type DocumentSerializer func(indexID pkgx.IndexID, document any) (map[string]any, error)
+
+// HitTransformer modifies the raw document of a search hit before it is decoded, e.g. to decrypt protected fields
+type HitTransformer func(document map[string]any) error
+
+// HitMetadataFunc returns auxiliary metadata of a search hit, e.g. badges or tracking payloads derived from the raw
+// document, without changing the returnType of the DocumentConverter
+type HitMetadataFunc func(ctx context.Context, indexID pkgx.IndexID, hit api.SearchResultHit) pkgx.Metadata
+
 type BaseAPI[indexDocument any, returnType any] struct {
 	l                 *zap.Logger
 	client            *typesense.Client
@@ -23,6 +40,14 @@ type BaseAPI[indexDocument any, returnType any] struct {
 	presets           map[string]*api.PresetUpsertSchema
 	revisionID        pkgx.RevisionID
 	documentConverter DocumentConverter[indexDocument, returnType]
+	decoder           *documentDecoder[indexDocument]
+	compatibility     sync.Map // pkgx.IndexID → error of the schema compatibility check
+	hashes            contentHashes
+	// deprecationWarnings remembers the deprecated fields whose usage has been logged by index
+	deprecationWarnings sync.Map
+	// exportJobs holds the running export jobs by ID
+	exportJobs sync.Map
+	opts       options
 }
 
 func NewBaseAPI[indexDocument any, returnType any](
@@ -31,13 +56,17 @@ func NewBaseAPI[indexDocument any, returnType any](
 	collections map[pkgx.IndexID]*api.CollectionSchema,
 	presets map[string]*api.PresetUpsertSchema,
 	documentConverter DocumentConverter[indexDocument, returnType],
+	opts ...Option,
 ) *BaseAPI[indexDocument, returnType] {
+	o := newOptions(opts...)
 	return &BaseAPI[indexDocument, returnType]{
 		l:                 l,
 		client:            client,
 		collections:       collections,
-		presets:           presets,
+		presets:           mergeIndexPresets(presets, o.indexPresets),
 		documentConverter: documentConverter,
+		decoder:           newDocumentDecoder[indexDocument](),
+		opts:              o,
 	}
 }
 
@@ -62,8 +91,8 @@ func (b *BaseAPI[indexDocument, returnType]) Indices() ([]pkgx.IndexID, error) {
 }
 
 // Initialize
-// This function ensures that a new collection is created for each alias on every run
-// and updates aliases to point to the latest revision.
+// This function attaches to the revision currently served by the aliases without creating a new one,
+// indices without an alias are bootstrapped with an empty collection. New revisions are created with NewRevision.
 //
 // example:
 //
@@ -82,23 +111,33 @@ func (b *BaseAPI[indexDocument, returnType]) Indices() ([]pkgx.IndexID, error) {
 //	   The revision ID is a timestamp in the format "YYYY-MM-DD-HH". If multiple collections are available,
 //	   the latest revision ID can be identified by the latest timestamp value.
 //
-// Additionally, ensure that the configured search preset is present.
+// Additionally, ensure that the configured stopwords sets and search presets, including the presets of the indices,
+// are present.
 // The system is considered valid if there is one alias for each collection and the collections
 // are correctly linked to their respective aliases.
 // The function sets the revisionID that is currently linked to the aliases internally.
+// Replicas starting at the same time are serialized by a cluster wide lock, so they converge on the same state.
 func (b *BaseAPI[indexDocument, returnType]) Initialize(ctx context.Context) (pkgx.RevisionID, error) {
-	b.l.Info("initializing typesense collections and aliases...")
+	l := pkgx.Logger(ctx, b.l)
+	l.Info("initializing typesense collections and aliases...")
 
 	// Step 1: Check Typesense connection
 	if _, err := b.client.Health(ctx, 5*time.Second); err != nil {
-		b.l.Error("typesense health check failed", zap.Error(err))
+		l.Error("typesense health check failed", zap.Error(err))
+		return "", err
+	}
+
+	// Step 2: Serialize the initialization of replicas starting at the same time
+	// and retrieve existing aliases and collections once holding the lock
+	release, err := b.acquireLock(ctx, initializeLockName)
+	if err != nil {
 		return "", err
 	}
+	defer release()
 
-	// Step 2: Retrieve existing aliases and collections
 	aliases, err := b.client.Aliases().Retrieve(ctx)
 	if err != nil {
-		b.l.Error("failed to retrieve aliases", zap.Error(err))
+		l.Error("failed to retrieve aliases", zap.Error(err))
 		return "", err
 	}
 
@@ -114,6 +153,10 @@ func (b *BaseAPI[indexDocument, returnType]) Initialize(ctx context.Context) (pk
 	for _, alias := range aliases {
 		collectionName := alias.CollectionName
 		indexID := pkgx.IndexID(*alias.Name)
+		// aliases of other indices, canaries, slots and auxiliary collections like vocabularies are not served
+		if _, ok := b.collections[indexID]; !ok {
+			continue
+		}
 		revisionID := extractRevisionID(collectionName, string(indexID))
 
 		// Ensure alias points to an existing collection
@@ -121,97 +164,255 @@ func (b *BaseAPI[indexDocument, returnType]) Initialize(ctx context.Context) (pk
 			latestRevisions[indexID] = revisionID
 			aliasMappings[indexID] = collectionName
 		} else {
-			b.l.Warn("alias points to missing collection, resetting", zap.String("alias", string(indexID)))
+			l.Warn("alias points to missing collection, resetting", zap.String("alias", string(indexID)))
 		}
 	}
 
-	// Step 4: Ensure all aliases are correctly mapped to collections and create a new revision
-	newRevisionID := b.generateRevisionID()
-	b.l.Info("generated new revision", zap.String("revisionID", string(newRevisionID)))
-
-	for indexID, schema := range b.collections {
-		collectionName := formatCollectionName(indexID, newRevisionID)
-
-		b.l.Warn("creating new collection & alias",
-			zap.String("index", string(indexID)),
-			zap.String("new_collection", collectionName),
-		)
+	// Step 4: Bootstrap indices without a served collection with an empty revision
+	var missing []pkgx.IndexID
+	for _, indexID := range b.creationOrder() {
+		if _, ok := aliasMappings[indexID]; !ok {
+			missing = append(missing, indexID)
+		}
+	}
+	if len(missing) > 0 {
+		bootstrapRevisionID := b.generateRevisionID()
+		for _, indexID := range missing {
+			collectionName, err := b.createRevisionCollection(ctx, indexID, bootstrapRevisionID)
+			if err != nil {
+				return "", err
+			}
+			l.Warn("bootstrapping alias", zap.String("index", string(indexID)), zap.String("collection", collectionName))
+			if err := b.ensureAliasMapping(ctx, indexID, collectionName); err != nil {
+				return "", err
+			}
+			latestRevisions[indexID] = bootstrapRevisionID
+			aliasMappings[indexID] = collectionName
+		}
+	}
+	if b.opts.canary {
+		for indexID, collectionName := range aliasMappings {
+			if _, ok := b.collections[indexID]; !ok {
+				continue
+			}
+			if err := b.ensureAliasMapping(ctx, CanaryIndexID(indexID), collectionName); err != nil {
+				return "", err
+			}
+		}
+	}
 
-		// Create new collection
-		if err := b.createCollectionIfNotExists(ctx, schema, collectionName); err != nil {
-			return "", err
+	// Step 5: Attach to the latest served revision
+	for indexID, revisionID := range latestRevisions {
+		if _, ok := b.collections[indexID]; ok && revisionID > b.revisionID {
+			b.revisionID = revisionID
 		}
+	}
 
-		// Update alias to point to new collection
-		if err := b.ensureAliasMapping(ctx, indexID, collectionName); err != nil {
+	// Step 6: ensure stopwords sets and search presets are present
+	for name, stopwords := range b.opts.stopwords {
+		if stopwords == nil {
+			continue
+		}
+		if _, err := b.client.Stopwords().Upsert(ctx, name, stopwords); err != nil {
+			l.Error("failed to upsert stopwords", zap.String("name", name), zap.Error(err))
 			return "", err
 		}
 	}
-
-	// Step 5: Set the latest revision ID and return
-	b.revisionID = newRevisionID
-
-	// Step 6: ensure search presets are present
 	for name, preset := range b.presets {
 		_, err := b.client.Presets().Upsert(ctx, name, preset)
 		if err != nil {
-			b.l.Error("failed to upsert preset", zap.String("name", name), zap.Error(err))
+			l.Error("failed to upsert preset", zap.String("name", name), zap.Error(err))
 			return "", err
 		}
 	}
 
-	b.l.Info("initialization completed", zap.String("revisionID", string(b.revisionID)))
+	// Step 7: sync curation overrides and redirect rules
+	for indexID, collectionName := range aliasMappings {
+		if _, ok := b.collections[indexID]; !ok {
+			continue
+		}
+		if err := b.syncSearchOverrides(ctx, indexID, collectionName); err != nil {
+			return "", err
+		}
+	}
+	if err := b.reloadCurator(ctx); err != nil {
+		return "", err
+	}
+
+	l.Info("initialization completed", zap.String("revisionID", string(b.revisionID)))
 
 	return b.revisionID, nil
 }
 
+// NewRevision creates the collections of a new revision for all indices, documents are upserted into them and
+// CommitRevision moves the aliases
+func (b *BaseAPI[indexDocument, returnType]) NewRevision(ctx context.Context) (pkgx.RevisionID, error) {
+	l := pkgx.Logger(ctx, b.l)
+	revisionID := b.generateRevisionID()
+
+	// revision IDs have minute granularity, a second run within the same minute must not reuse the collections of
+	// the first one, they may be served already
+	existingCollections, err := b.fetchExistingCollections(ctx)
+	if err != nil {
+		return "", err
+	}
+	for indexID := range b.collections {
+		if collectionName := formatCollectionName(indexID, revisionID); existingCollections[collectionName] {
+			l.Error("revision already exists", zap.String("collection", collectionName))
+			return "", fmt.Errorf("%w: %s", ErrRevisionExists, collectionName)
+		}
+	}
+
+	for _, indexID := range b.creationOrder() {
+		if _, err := b.createRevisionCollection(ctx, indexID, revisionID); err != nil {
+			return "", err
+		}
+	}
+	l.Info("created new revision", zap.String("revisionID", string(revisionID)))
+	return revisionID, nil
+}
+
+// createRevisionCollection creates the collection of the index for the revision and records its build metadata
+func (b *BaseAPI[indexDocument, returnType]) createRevisionCollection(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	revisionID pkgx.RevisionID,
+) (string, error) {
+	l := pkgx.Logger(ctx, b.l)
+	collectionName := formatCollectionName(indexID, revisionID)
+	schema, err := b.revisionSchema(ctx, indexID, revisionID)
+	if err != nil {
+		return "", err
+	}
+	if err := b.createCollectionIfNotExists(ctx, indexID, schema, collectionName); err != nil {
+		return "", err
+	}
+	if err := b.writeCollectionMetadata(ctx, indexID, revisionID, schema); err != nil {
+		l.Warn("failed to record collection metadata", zap.String("collection", collectionName), zap.Error(err))
+	}
+	return collectionName, nil
+}
+
 func (b *BaseAPI[indexDocument, returnType]) UpsertDocuments(
 	ctx context.Context,
 	revisionID pkgx.RevisionID,
 	indexID pkgx.IndexID,
 	documents []*indexDocument,
 ) error {
+	return b.importRevisionDocuments(ctx, revisionID, indexID, documents, "upsert")
+}
+
+// importRevisionDocuments imports the documents into the collection of the revision with the given import action
+func (b *BaseAPI[indexDocument, returnType]) importRevisionDocuments(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+	action string,
+) error {
+	l := pkgx.Logger(ctx, b.l)
 	if len(documents) == 0 {
-		b.l.Warn("no documents provided for upsert", zap.String("index", string(indexID)))
+		l.Warn("no documents provided for upsert", zap.String("index", string(indexID)))
 		return nil
 	}
 
+	if b.opts.documentSerializer == nil {
+		if err := b.checkSchemaCompatibility(indexID); err != nil {
+			return err
+		}
+	}
+
 	collectionName := formatCollectionName(indexID, revisionID)
 
 	// Convert []indexDocument to []interface{} to satisfy Import() method
-	docInterfaces := make([]interface{}, len(documents))
-	for i, doc := range documents {
-		b.l.Info("doc", zap.Any("doc", doc))
-		docInterfaces[i] = doc
+	rules := b.opts.fieldRules[indexID]
+	docInterfaces := make([]interface{}, 0, len(documents))
+	for _, doc := range documents {
+		if doc == nil {
+			// providers return nil for documents that could not be created
+			continue
+		}
+		var document interface{} = doc
+		if b.opts.documentSerializer != nil {
+			fields, err := b.opts.documentSerializer(indexID, doc)
+			if err != nil {
+				l.Warn("skipping document failing serialization", zap.String("index", string(indexID)), zap.Error(err))
+				continue
+			}
+			document = fields
+		}
+		if len(rules) == 0 {
+			docInterfaces = append(docInterfaces, document)
+			continue
+		}
+		fields, err := applyFieldRules(document, rules)
+		if err != nil {
+			l.Warn("skipping document violating field rules", zap.String("index", string(indexID)), zap.Error(err))
+			continue
+		}
+		docInterfaces = append(docInterfaces, fields)
+	}
+
+	// Skip documents that are unchanged in the collection, e.g. when re-running a partially imported revision
+	var hashes map[string]string
+	if b.opts.upsertDeduplication {
+		pending, pendingHashes, err := b.deduplicateDocuments(ctx, indexID, collectionName, docInterfaces)
+		if err != nil {
+			return err
+		}
+		if skipped := len(docInterfaces) - len(pending); skipped > 0 {
+			l.Info("skipping unchanged documents", zap.String("collection", collectionName), zap.Int("skipped_documents", skipped))
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		docInterfaces, hashes = pending, pendingHashes
+	}
+
+	if b.opts.documentVersioning {
+		stamped, err := stampDocuments(docInterfaces, revisionID, b.opts.clock.Now())
+		if err != nil {
+			l.Error("failed to stamp documents", zap.String("collection", collectionName), zap.Error(err))
+			return err
+		}
+		docInterfaces = stamped
 	}
 
 	// Perform bulk upsert using Import()
 	params := &api.ImportDocumentsParams{
-		Action: (*api.IndexAction)(pointer.String("upsert")),
+		Action: (*api.IndexAction)(pointer.String(action)),
 	}
 
-	importResults, err := b.client.Collection(collectionName).Documents().Import(ctx, docInterfaces, params)
+	importResults, err := b.importDocuments(ctx, collectionName, docInterfaces, params)
 	if err != nil {
-		b.l.Error("failed to bulk upsert documents", zap.String("collection", collectionName), zap.Error(err))
+		l.Error("failed to bulk upsert documents", zap.String("collection", collectionName), zap.Error(err))
 		return err
 	}
 
 	// Log success and failure counts
 	successCount, failureCount := 0, 0
-	for _, result := range importResults {
+	for i, result := range importResults {
 		if result.Success {
 			successCount++
 		} else {
 			failureCount++
-			b.l.Warn("document failed to upsert",
+			if hashes != nil && i < len(docInterfaces) {
+				fields, _ := docInterfaces[i].(map[string]any)
+				if id, ok := fieldDocumentID(fields, b.idField(indexID)); ok {
+					delete(hashes, string(id))
+				}
+			}
+			l.Warn("document failed to upsert",
 				zap.String("collection", collectionName),
 				zap.String("error", result.Error),
 			)
 		}
 	}
 
-	b.l.Info("bulk upsert completed",
+	b.recordHashes(collectionName, hashes)
+	l.Info("bulk upsert completed",
 		zap.String("collection", collectionName),
+		zap.String("action", action),
 		zap.Int("successful_documents", successCount),
 		zap.Int("failed_documents", failureCount),
 	)
@@ -223,44 +424,134 @@ func (b *BaseAPI[indexDocument, returnType]) UpsertDocuments(
 // additionally it will remove all old collections that are not linked to an alias
 // keeping only the latest revision and the one before
 func (b *BaseAPI[indexDocument, returnType]) CommitRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	l := pkgx.Logger(ctx, b.l)
+	var errs []error
+	var pinned []pkgx.IndexID
+	var indices []pkgx.IndexID
 	for indexID := range b.collections {
+		newCollectionName := formatCollectionName(indexID, revisionID)
+
+		// Step 0: Pinned indices keep serving their revision, the new collection is dropped
+		if err := b.checkPinned(ctx, indexID); err != nil {
+			l.Warn("refusing to move alias of pinned index", zap.String("alias", string(indexID)), zap.Error(err))
+			if errors.Is(err, ErrRevisionPinned) {
+				pinned = append(pinned, indexID)
+			} else {
+				errs = append(errs, err)
+			}
+			b.dropCollection(ctx, newCollectionName)
+			continue
+		}
+
+		// Step 1: Carry the curation overrides over to the new collections before any alias is moved
+		if err := b.syncSearchOverrides(ctx, indexID, newCollectionName); err != nil {
+			return err
+		}
+		indices = append(indices, indexID)
+	}
+
+	for _, indexID := range indices {
 		alias := string(indexID)
 		newCollectionName := formatCollectionName(indexID, revisionID)
 
-		// Step 1: Update the alias to point to the new collection
+		// Step 2: Update the alias to point to the new collection
+		oldCollectionName := b.previousCollection(ctx, indexID)
 		_, err := b.client.Aliases().Upsert(ctx, alias,
 			&api.CollectionAliasSchema{
 				CollectionName: newCollectionName,
 			})
 		if err != nil {
-			b.l.Error("failed to update alias", zap.String("alias", alias), zap.Error(err))
+			l.Error("failed to update alias", zap.String("alias", alias), zap.Error(err))
 			return err
 		}
-		b.l.Info("updated alias", zap.String("alias", alias), zap.String("collection", newCollectionName))
+		l.Info("updated alias", zap.String("alias", alias), zap.String("collection", newCollectionName))
+		b.aliasSwitched(ctx, indexID, oldCollectionName, newCollectionName)
+
+		if b.opts.canary {
+			if err := b.ensureAliasMapping(ctx, CanaryIndexID(indexID), newCollectionName); err != nil {
+				return err
+			}
+		}
+
+		b.forgetHashes(newCollectionName)
 
-		// Step 2: Clean up old collections (keep only the last two)
+		// Step 3: Clean up old collections exceeding the retention count
 		err = b.pruneOldCollections(ctx, alias, newCollectionName)
 		if err != nil {
-			b.l.Error("failed to clean up old collections", zap.String("alias", alias), zap.Error(err))
+			l.Error("failed to clean up old collections", zap.String("alias", alias), zap.Error(err))
 		}
 	}
 
-	return nil
+	if len(pinned) > 0 {
+		slices.Sort(pinned)
+		errs = append(errs, &pkgx.PinnedError{Indices: pinned})
+	}
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// dropCollection deletes the collection of a revision that is not committed
+func (b *BaseAPI[indexDocument, returnType]) dropCollection(ctx context.Context, collectionName string) {
+	l := pkgx.Logger(ctx, b.l)
+	if err := DeleteCollection(ctx, b.client, collectionName); err != nil {
+		l.Error("failed to delete collection", zap.String("collection", collectionName), zap.Error(err))
+		return
+	}
+	b.deleteCollectionMetadata(ctx, collectionName)
+	b.forgetHashes(collectionName)
+	l.Info("deleted uncommitted collection", zap.String("collection", collectionName))
+}
+
+// aliasedCollections returns the collections aliases point to by collection name
+func (b *BaseAPI[indexDocument, returnType]) aliasedCollections(ctx context.Context) (map[string]string, error) {
+	l := pkgx.Logger(ctx, b.l)
+	aliases, err := b.client.Aliases().Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve aliases", zap.Error(err))
+		return nil, err
+	}
+	aliased := make(map[string]string, len(aliases))
+	for _, alias := range aliases {
+		if alias.Name != nil {
+			aliased[alias.CollectionName] = *alias.Name
+		}
+	}
+	return aliased, nil
 }
 
 // RevertRevision will remove the collections created for the given revisionID
 func (b *BaseAPI[indexDocument, returnType]) RevertRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	l := pkgx.Logger(ctx, b.l)
 	for indexID := range b.collections {
 		collectionName := formatCollectionName(indexID, revisionID)
 
+		// Step 0: Route canary traffic back to the served revision
+		if err := b.alignCanary(ctx, indexID); err != nil {
+			l.Warn("failed to realign canary alias", zap.String("index", string(indexID)), zap.Error(err))
+		}
+
+		// Collections served by an alias are never deleted, e.g. if the revision has been committed in the meantime
+		aliased, err := b.aliasedCollections(ctx)
+		if err != nil {
+			return err
+		}
+		if alias, ok := aliased[collectionName]; ok {
+			l.Warn("keeping collection served by alias", zap.String("collection", collectionName), zap.String("alias", alias))
+			continue
+		}
+
 		// Step 1: Delete the collection safely
-		_, err := b.client.Collection(collectionName).Delete(ctx)
+		_, err = b.client.Collection(collectionName).Delete(ctx)
 		if err != nil {
-			b.l.Error("failed to delete collection", zap.String("collection", collectionName), zap.Error(err))
+			l.Error("failed to delete collection", zap.String("collection", collectionName), zap.Error(err))
 			return err
 		}
 
-		b.l.Info("reverted and deleted collection", zap.String("collection", collectionName))
+		b.deleteCollectionMetadata(ctx, collectionName)
+		b.forgetHashes(collectionName)
+		l.Info("reverted and deleted collection", zap.String("collection", collectionName))
 	}
 
 	return nil
@@ -272,10 +563,27 @@ func (b *BaseAPI[indexDocument, returnType]) SimpleSearch(
 	index pkgx.IndexID,
 	parameters *pkgx.SearchParameters,
 ) ([]returnType, pkgx.Scores, int, error) {
-	searchParams := buildSearchParams(parameters)
+	searchParams, err := b.simpleSearchParams(index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
 	return b.ExpertSearch(ctx, index, searchParams)
 }
 
+// SimpleSearchResult will perform a search operation on the given index using basic SearchParameters input
+// and returns the full SearchResult
+func (b *BaseAPI[indexDocument, returnType]) SimpleSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*pkgx.SearchResult[returnType], error) {
+	searchParams, err := b.simpleSearchParams(index, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return b.ExpertSearchResult(ctx, index, searchParams)
+}
+
 // ExpertSearch performs a search operation on the given index
 // It returns the converted documents, scores, and totalResults
 func (b *BaseAPI[indexDocument, returnType]) ExpertSearch(
@@ -283,83 +591,309 @@ func (b *BaseAPI[indexDocument, returnType]) ExpertSearch(
 	indexID pkgx.IndexID,
 	parameters *api.SearchCollectionParams,
 ) ([]returnType, pkgx.Scores, int, error) {
+	result, err := b.ExpertSearchResult(ctx, indexID, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+// ExpertSearchResult performs a search operation on the given index
+// It returns the converted documents, scores, totalResults and spelling suggestions wrapped in a SearchResult
+func (b *BaseAPI[indexDocument, returnType]) ExpertSearchResult(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*pkgx.SearchResult[returnType], error) {
+	return expertSearchResult(ctx, b, indexID, parameters, b.documentConverter)
+}
+
+// preparedSearch holds the parameters of a search after redirects, defaults, curation and enforced filters
+// have been applied
+type preparedSearch struct {
+	indexID        pkgx.IndexID
+	collectionName string
+	// parameters are the curated parameters, searchParameters additionally contain the enforced filter
+	parameters       *api.SearchCollectionParams
+	searchParameters *api.SearchCollectionParams
+	filter           string
+	page             int
+	perPage          int
+	redirect         string
+	// start is the time the search was prepared, fallback searches get the rest of the latency budget
+	start time.Time
+}
+
+// prepareSearch applies redirects, search defaults, curation, the page limit, the enforced filter and the latency
+// budget to the parameters of a search on the index
+func (b *BaseAPI[indexDocument, returnType]) prepareSearch(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*preparedSearch, error) {
+	l := pkgx.Logger(ctx, b.l)
 	if parameters == nil {
-		b.l.Error("search parameters are nil")
-		return nil, nil, 0, errors.New("search parameters cannot be nil")
+		l.Error("search parameters are nil")
+		return nil, errors.New("search parameters cannot be nil")
+	}
+	if err := b.checkIndex(indexID); err != nil {
+		return nil, err
 	}
 
-	collectionName := string(indexID) // digital-bks-at-de
-	searchResponse, err := b.client.Collection(collectionName).Documents().Search(ctx, parameters)
+	if url, ok := b.redirect(ctx, indexID, parameters); ok {
+		l.Info("redirecting search", zap.String("index", string(indexID)), zap.String("redirect", url))
+		return &preparedSearch{indexID: indexID, redirect: url}, nil
+	}
+
+	parameters, err := b.curate(ctx, indexID, b.applySearchDefaults(indexID, parameters))
 	if err != nil {
-		b.l.Error("failed to perform search", zap.String("index", collectionName), zap.Error(err))
-		return nil, nil, 0, err
+		return nil, err
+	}
+
+	page, perPage := searchPage(parameters)
+	if b.opts.maxHits > 0 && (page-1)*perPage >= b.opts.maxHits {
+		l.Warn("requested page exceeds max hits", zap.String("index", string(indexID)), zap.Int("page", page))
+		return nil, ErrPageOutOfRange
+	}
+
+	filter, err := b.enforcedFilter(ctx, indexID)
+	if err != nil {
+		return nil, err
+	}
+	b.logDeprecatedUsage(ctx, indexID, parameters)
+
+	return &preparedSearch{
+		indexID:          indexID,
+		collectionName:   b.searchCollection(ctx, indexID), // digital-bks-at-de
+		parameters:       parameters,
+		searchParameters: b.withLatencyBudget(ctx, withFilter(parameters, filter)),
+		filter:           filter,
+		page:             page,
+		perPage:          perPage,
+		start:            time.Now(),
+	}, nil
+}
+
+// expertSearchResult performs the search and converts the hits with the given converter
+func expertSearchResult[indexDocument any, returnType any, T any](
+	ctx context.Context,
+	b *BaseAPI[indexDocument, returnType],
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+	convert DocumentConverter[indexDocument, T],
+) (*pkgx.SearchResult[T], error) {
+	l := pkgx.Logger(ctx, b.l)
+	search, err := b.prepareSearch(ctx, indexID, parameters)
+	if err != nil {
+		return nil, err
+	}
+	if search.redirect != "" {
+		return &pkgx.SearchResult[T]{Redirect: search.redirect}, nil
+	}
+
+	searchStart := time.Now()
+	searchResponse, err := b.client.Collection(search.collectionName).Documents().Search(ctx, search.searchParameters)
+	if err != nil {
+		l.Error("failed to perform search", zap.String("index", search.collectionName), zap.Error(err))
+		return nil, err
 	}
+	return searchResult(ctx, b, search, searchResponse, time.Since(searchStart), convert), nil
+}
+
+// searchResult converts the response of the prepared search, searches without hits run the fallbacks
+func searchResult[indexDocument any, returnType any, T any](
+	ctx context.Context,
+	b *BaseAPI[indexDocument, returnType],
+	search *preparedSearch,
+	searchResponse *api.SearchResult,
+	duration time.Duration,
+	convert DocumentConverter[indexDocument, T],
+) *pkgx.SearchResult[T] {
+	l := pkgx.Logger(ctx, b.l)
+	indexID, collectionName, parameters := search.indexID, search.collectionName, search.parameters
+	page, perPage := search.page, search.perPage
 
 	// Extract totalResults from the search response
-	totalResults := *searchResponse.Found
+	totalResults := 0
+	if searchResponse.Found != nil {
+		totalResults = *searchResponse.Found
+	}
+	result := &pkgx.SearchResult[T]{}
+
+	if b.opts.searchRecorder != nil && parameters.Q != nil {
+		b.opts.searchRecorder.RecordSearch(ctx, indexID, *parameters.Q, totalResults)
+	}
+
+	if b.opts.spellingSuggester != nil && totalResults <= b.opts.spellingThreshold {
+		result.Suggestions = b.suggestSpellings(ctx, indexID, parameters)
+	}
+
+	var fallbacks []pkgx.FallbackDebug
+	if totalResults == 0 {
+		searchResponse, result.Fallback, fallbacks = b.searchFallbacks(ctx, search, searchResponse)
+		if searchResponse.Found != nil {
+			totalResults = *searchResponse.Found
+		}
+	}
+	result.Total = totalResults
+	result.Partial = searchResponse.SearchCutoff != nil && *searchResponse.SearchCutoff
+	if result.Partial {
+		l.Warn("search exceeded the latency budget, returning partial results", zap.String("index", collectionName))
+	}
+	result.Facets = facetsByField(convertFacets(searchResponse.FacetCounts))
+	if IsSearchDebug(ctx) {
+		result.Debug = searchDebug(collectionName, search.searchParameters, searchResponse, duration)
+		result.Debug.Fallbacks = fallbacks
+		logSearchDebug(l, result.Debug)
+	}
+	if searchResponse.RequestParams != nil && searchResponse.RequestParams.PerPage > 0 {
+		perPage = searchResponse.RequestParams.PerPage
+	}
+	result.Pagination = pkgx.NewPagination(page, perPage, totalResults, b.opts.maxHits)
 
 	// Ensure Hits is not empty before proceeding
 	if searchResponse.Hits == nil || len(*searchResponse.Hits) == 0 {
-		b.l.Warn("search response contains no hits", zap.String("index", collectionName))
-		return nil, nil, totalResults, nil
+		l.Warn("search response contains no hits", zap.String("index", collectionName))
+		return result
 	}
 
-	results := make([]returnType, len(*searchResponse.Hits))
+	b.hydrateHits(ctx, indexID, *searchResponse.Hits)
+	results := make([]T, 0, len(*searchResponse.Hits))
 	scores := make(pkgx.Scores)
 
+	hitScores := make([]pkgx.Score, 0, len(*searchResponse.Hits))
+
 	for i, hit := range *searchResponse.Hits {
-		if hit.Document == nil {
-			b.l.Warn("hit document is nil", zap.String("index", collectionName))
+		convertedDoc, score, err := convertHitAs(b, indexID, collectionName, hit, convert)
+		if err != nil {
+			result.ConversionErrors = append(result.ConversionErrors, conversionError(i, hit, err))
 			continue
 		}
+		results = append(results, convertedDoc)
+		hitScores = append(hitScores, score)
+		if highlights := hitHighlights(hit); highlights != nil {
+			if result.Highlights == nil {
+				result.Highlights = map[pkgx.DocumentID]map[string][]string{}
+			}
+			result.Highlights[score.ID] = highlights
+		}
+		if b.opts.hitMetadata != nil {
+			for key, value := range b.opts.hitMetadata(ctx, indexID, hit) {
+				result.AttachMetadata(score.ID, key, value)
+			}
+		}
+	}
 
-		docMap := *hit.Document
+	normalizeScores(hitScores, b.opts.scoreNormalization)
+	for _, score := range hitScores {
+		scores[score.ID] = score
+	}
 
-		// Extract document ID safely
-		docID, ok := docMap["id"].(string)
-		if !ok {
-			b.l.Warn("missing or invalid document ID in search result")
-			continue
-		}
+	l.Info("search completed",
+		zap.String("index", string(indexID)),
+		zap.String("alias", collectionName),
+		zap.String("slot", Slot(ctx)),
+		zap.Int("results_count", len(results)),
+		zap.Int("total_results", totalResults),
+		zap.Int("conversion_errors", len(result.ConversionErrors)),
+	)
 
-		// Convert raw document (map) to indexDocument struct
-		hitJSON, err := json.Marshal(docMap)
-		if err != nil {
-			b.l.Warn("failed to marshal document to JSON", zap.String("index", collectionName), zap.Error(err))
-			continue
-		}
+	result.Results = results
+	result.Scores = scores
+	return result
+}
 
-		var rawDoc indexDocument
-		if err := json.Unmarshal(hitJSON, &rawDoc); err != nil {
-			b.l.Warn("failed to unmarshal JSON into indexDocument", zap.String("index", collectionName), zap.Error(err))
-			continue
-		}
+// convertHit converts the document of a search hit of the index using the documentConverter
+// and extracts the document ID and score
+func (b *BaseAPI[indexDocument, returnType]) convertHit(
+	indexID pkgx.IndexID,
+	collectionName string,
+	hit api.SearchResultHit,
+) (returnType, pkgx.Score, error) {
+	return convertHitAs(b, indexID, collectionName, hit, b.documentConverter)
+}
 
-		// Convert the raw document using documentConverter
-		convertedDoc := b.documentConverter(rawDoc)
-		results[i] = convertedDoc
+// convertHitAs converts the document of a search hit using the given converter, the document ID is extracted with
+// the rules of the index, the collection may be any alias or collection of the index, e.g. the canary alias
+func convertHitAs[indexDocument any, returnType any, T any](
+	b *BaseAPI[indexDocument, returnType],
+	indexID pkgx.IndexID,
+	collectionName string,
+	hit api.SearchResultHit,
+	convert DocumentConverter[indexDocument, T],
+) (T, pkgx.Score, error) {
+	var convertedDoc T
+	if hit.Document == nil {
+		b.l.Warn("hit document is nil", zap.String("index", collectionName))
+		return convertedDoc, pkgx.Score{}, ErrMissingDocument
+	}
 
-		// Extract search score
-		index := 0
-		if hit.TextMatchInfo != nil && hit.TextMatchInfo.Score != nil {
-			if score, err := strconv.Atoi(*hit.TextMatchInfo.Score); err == nil {
-				index = score
-			} else {
-				b.l.Warn("invalid score value", zap.String("score", *hit.TextMatchInfo.Score), zap.Error(err))
-			}
+	docMap := *hit.Document
+	if b.opts.hitTransformer != nil {
+		if err := b.opts.hitTransformer(docMap); err != nil {
+			b.l.Warn("failed to transform document", zap.String("index", collectionName), zap.Error(err))
+			return convertedDoc, pkgx.Score{}, err
 		}
+	}
 
-		scores[pkgx.DocumentID(docID)] = pkgx.Score{
-			ID:    pkgx.DocumentID(docID),
-			Index: index,
+	// Decode the raw document (map) into the indexDocument struct
+	rawDoc, err := b.decoder.decode(docMap)
+	if err != nil {
+		b.l.Warn("failed to decode document", zap.String("index", collectionName), zap.Error(err))
+		return convertedDoc, pkgx.Score{}, err
+	}
+
+	// Extract document ID, preferring the document's own identity
+	docID, ok := b.extractDocumentID(indexID, docMap, &rawDoc)
+	if !ok {
+		b.l.Warn("missing or invalid document ID in search result", zap.String("index", collectionName))
+		return convertedDoc, pkgx.Score{}, ErrMissingDocumentID
+	}
+
+	// Convert the raw document using documentConverter
+	convertedDoc = convert(rawDoc)
+
+	// Extract search score
+	index := 0
+	if hit.TextMatchInfo != nil && hit.TextMatchInfo.Score != nil {
+		if score, err := strconv.Atoi(*hit.TextMatchInfo.Score); err == nil {
+			index = score
+		} else {
+			b.l.Warn("invalid score value", zap.String("score", *hit.TextMatchInfo.Score), zap.Error(err))
 		}
 	}
 
-	b.l.Info("search completed",
-		zap.String("index", collectionName),
-		zap.Int("results_count", len(results)),
-		zap.Int("total_results", totalResults),
-	)
+	return convertedDoc, pkgx.Score{
+		ID:             docID,
+		Index:          index,
+		Version:        documentVersion(docMap),
+		VectorDistance: hit.VectorDistance,
+	}, nil
+}
 
-	return results, scores, totalResults, nil
+// conversionError describes the failed conversion of the hit at the given position
+func conversionError(position int, hit api.SearchResultHit, err error) pkgx.ConversionError {
+	conversionErr := pkgx.ConversionError{Position: position, Err: err}
+	if hit.Document != nil {
+		if id, ok := (*hit.Document)["id"].(string); ok {
+			conversionErr.DocumentID = pkgx.DocumentID(id)
+		}
+	}
+	return conversionErr
+}
+
+// extractDocumentID resolves the document ID from the Identifiable document, the configured IDExtractor,
+// the configured ID field of the index or the "id" field in this order
+func (b *BaseAPI[indexDocument, returnType]) extractDocumentID(
+	indexID pkgx.IndexID,
+	docMap map[string]any,
+	rawDoc *indexDocument,
+) (pkgx.DocumentID, bool) {
+	if docID, ok := documentID(rawDoc); ok {
+		return docID, true
+	}
+	if b.opts.idExtractor != nil {
+		return b.opts.idExtractor(indexID, docMap)
+	}
+	return fieldDocumentID(docMap, b.idField(indexID))
 }
@@ -0,0 +1,79 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+const canarySuffix = "-canary"
+
+type canaryContextKey struct{}
+
+// CanaryIndexID returns the name of the canary alias of the index
+func CanaryIndexID(indexID pkgx.IndexID) pkgx.IndexID {
+	return indexID + canarySuffix
+}
+
+// WithCanary routes the searches of the returned context to the canary aliases,
+// e.g. for the share of traffic that validates a new revision
+func WithCanary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, canaryContextKey{}, true)
+}
+
+// IsCanary checks if searches of the context are routed to the canary aliases
+func IsCanary(ctx context.Context) bool {
+	canary, _ := ctx.Value(canaryContextKey{}).(bool)
+	return canary
+}
+
+// DeployCanary points the canary aliases of all indices at the collections of the given revision while the main
+// aliases keep serving the current revision. CommitRevision and RevertRevision realign the canary aliases.
+func (b *BaseAPI[indexDocument, returnType]) DeployCanary(ctx context.Context, revisionID pkgx.RevisionID) error {
+	l := pkgx.Logger(ctx, b.l)
+	if !b.opts.canary {
+		return errors.New("canary aliases are not enabled")
+	}
+	for indexID := range b.collections {
+		if err := b.checkPinned(ctx, indexID); err != nil {
+			l.Warn("skipping canary of pinned index", zap.String("index", string(indexID)), zap.Error(err))
+			continue
+		}
+		if err := b.ensureAliasMapping(ctx, CanaryIndexID(indexID), formatCollectionName(indexID, revisionID)); err != nil {
+			return err
+		}
+		l.Info("deployed canary",
+			zap.String("alias", string(CanaryIndexID(indexID))),
+			zap.String("revision", string(revisionID)),
+		)
+	}
+	return nil
+}
+
+// alignCanary points the canary alias at the collection currently served by the main alias
+func (b *BaseAPI[indexDocument, returnType]) alignCanary(ctx context.Context, indexID pkgx.IndexID) error {
+	l := pkgx.Logger(ctx, b.l)
+	if !b.opts.canary {
+		return nil
+	}
+	alias, err := b.client.Alias(string(indexID)).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve alias", zap.String("alias", string(indexID)), zap.Error(err))
+		return err
+	}
+	return b.ensureAliasMapping(ctx, CanaryIndexID(indexID), alias.CollectionName)
+}
+
+// searchCollection returns the alias searched for the index, the slot or canary alias if requested by the context.
+// The alias is not an index ID, hits are converted with the index ID, see convertHitAs.
+func (b *BaseAPI[indexDocument, returnType]) searchCollection(ctx context.Context, indexID pkgx.IndexID) string {
+	if slot := Slot(ctx); b.hasSlot(indexID, slot) {
+		return string(SlotIndexID(indexID, slot))
+	}
+	if b.opts.canary && IsCanary(ctx) {
+		return string(CanaryIndexID(indexID))
+	}
+	return string(indexID)
+}
@@ -0,0 +1,97 @@
+package typesenseapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// importDocuments imports the documents in chunks of at most maxImportPayloadSize bytes of JSONL,
+// chunks rejected as too large by the server are split in half and retried
+func (b *BaseAPI[indexDocument, returnType]) importDocuments(
+	ctx context.Context,
+	collectionName string,
+	documents []interface{},
+	params *api.ImportDocumentsParams,
+) ([]*api.ImportDocumentResponse, error) {
+	chunks, err := chunkByPayloadSize(documents, b.opts.maxImportPayloadSize)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*api.ImportDocumentResponse, 0, len(documents))
+	for _, chunk := range chunks {
+		chunkResults, err := b.importChunk(ctx, collectionName, chunk, params)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunkResults...)
+	}
+	return results, nil
+}
+
+// importChunk imports the documents and bisects them on 413 responses
+func (b *BaseAPI[indexDocument, returnType]) importChunk(
+	ctx context.Context,
+	collectionName string,
+	documents []interface{},
+	params *api.ImportDocumentsParams,
+) ([]*api.ImportDocumentResponse, error) {
+	results, err := b.client.Collection(collectionName).Documents().Import(ctx, documents, params)
+	if !isPayloadTooLarge(err) || len(documents) < 2 {
+		return results, err
+	}
+
+	pkgx.Logger(ctx, b.l).Warn("import payload too large, splitting chunk",
+		zap.String("collection", collectionName),
+		zap.Int("documents", len(documents)),
+	)
+	half := len(documents) / 2
+	head, err := b.importChunk(ctx, collectionName, documents[:half], params)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := b.importChunk(ctx, collectionName, documents[half:], params)
+	if err != nil {
+		return nil, err
+	}
+	return append(head, tail...), nil
+}
+
+// chunkByPayloadSize splits the documents into chunks whose JSONL encoding does not exceed maxSize bytes,
+// a document larger than maxSize is sent in a chunk of its own. A maxSize of 0 returns a single chunk.
+func chunkByPayloadSize(documents []interface{}, maxSize int) ([][]interface{}, error) {
+	if maxSize <= 0 {
+		return [][]interface{}{documents}, nil
+	}
+
+	var chunks [][]interface{}
+	start, size := 0, 0
+	for i, document := range documents {
+		data, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		documentSize := len(data) + 1 // newline
+		if i > start && size+documentSize > maxSize {
+			chunks = append(chunks, documents[start:i])
+			start, size = i, 0
+		}
+		size += documentSize
+	}
+	if start < len(documents) {
+		chunks = append(chunks, documents[start:])
+	}
+	return chunks, nil
+}
+
+// isPayloadTooLarge checks if the typesense request failed with 413
+func isPayloadTooLarge(err error) bool {
+	var httpErr *typesense.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == http.StatusRequestEntityTooLarge
+}
@@ -0,0 +1,218 @@
+package typesenseapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// SchemaCompatibilityError lists the required schema fields the index document type can't provide
+type SchemaCompatibilityError struct {
+	IndexID    pkgx.IndexID
+	Type       string
+	Mismatches []string
+}
+
+func (e *SchemaCompatibilityError) Error() string {
+	return fmt.Sprintf("document type %s is incompatible with the schema of index %q: %s",
+		e.Type, e.IndexID, strings.Join(e.Mismatches, "; "))
+}
+
+// CheckSchemaCompatibility verifies via reflection that the JSON fields of the index document type provide all
+// required fields of every configured schema with compatible types. Documents that are not structs are not checked.
+func (b *BaseAPI[indexDocument, returnType]) CheckSchemaCompatibility() error {
+	indexIDs := make([]string, 0, len(b.collections))
+	for indexID := range b.collections {
+		indexIDs = append(indexIDs, string(indexID))
+	}
+	sort.Strings(indexIDs)
+
+	var errs []error
+	for _, indexID := range indexIDs {
+		if err := b.checkSchemaCompatibility(pkgx.IndexID(indexID)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkSchemaCompatibility checks the schema of the index once and returns the cached result afterwards
+func (b *BaseAPI[indexDocument, returnType]) checkSchemaCompatibility(indexID pkgx.IndexID) error {
+	if result, ok := b.compatibility.Load(indexID); ok {
+		err, _ := result.(error)
+		return err
+	}
+
+	var err error
+	if schema, ok := b.collections[indexID]; ok {
+		err = schemaCompatibility(reflect.TypeFor[indexDocument](), indexID, schema, b.opts.fieldRules[indexID])
+	}
+	if err != nil {
+		b.l.Error("document type is incompatible with schema", zap.String("index", string(indexID)), zap.Error(err))
+		b.compatibility.Store(indexID, err)
+		return err
+	}
+	b.compatibility.Store(indexID, nil)
+	return nil
+}
+
+// schemaCompatibility compares the required fields of the schema with the JSON fields of the document type.
+// Fields defaulted or coerced by field rules, auto-embedding and wildcard fields are skipped.
+func schemaCompatibility(t reflect.Type, indexID pkgx.IndexID, schema *api.CollectionSchema, rules []FieldRule) error {
+	t = derefType(t)
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	defaulted := map[string]bool{}
+	coerced := map[string]bool{}
+	for _, rule := range rules {
+		if rule.FromField != "" || rule.Value != nil {
+			defaulted[rule.Field] = true
+		}
+		if rule.Coerce != "" {
+			coerced[rule.Field] = true
+		}
+	}
+
+	var mismatches []string
+	for _, field := range schema.Fields {
+		if field.Name == "id" || field.Embed != nil || strings.ContainsAny(field.Name, "*^$[") {
+			continue
+		}
+		optional := field.Optional != nil && *field.Optional
+		fieldType, omitEmpty, found, resolved := lookupJSONField(t, field.Name)
+		switch {
+		case !found && !optional && !defaulted[field.Name]:
+			mismatches = append(mismatches, fmt.Sprintf("missing required field %q (%s)", field.Name, field.Type))
+		case !found || !resolved:
+			continue
+		case !coerced[field.Name] && !compatibleType(fieldType, field.Type):
+			mismatches = append(mismatches, fmt.Sprintf("field %q is %s, schema expects %s", field.Name, fieldType, field.Type))
+		case omitEmpty && !optional && !defaulted[field.Name]:
+			mismatches = append(mismatches, fmt.Sprintf("required field %q is omitted when empty", field.Name))
+		}
+	}
+
+	if len(mismatches) > 0 {
+		return &SchemaCompatibilityError{
+			IndexID:    indexID,
+			Type:       t.String(),
+			Mismatches: mismatches,
+		}
+	}
+	return nil
+}
+
+// lookupJSONField resolves the field by its JSON name, nested names like "address.city" are resolved through
+// nested structs. Fields below maps, interfaces or slices are found but can't be resolved to a type.
+func lookupJSONField(t reflect.Type, name string) (fieldType reflect.Type, omitEmpty, found, resolved bool) {
+	if fieldType, omitEmpty, ok := jsonField(t, name); ok {
+		return fieldType, omitEmpty, true, true
+	}
+	parent, child, ok := strings.Cut(name, ".")
+	if !ok {
+		return nil, false, false, false
+	}
+	parentType, _, ok := jsonField(t, parent)
+	if !ok {
+		return nil, false, false, false
+	}
+	parentType = derefType(parentType)
+	switch parentType.Kind() {
+	case reflect.Struct:
+		return lookupJSONField(parentType, child)
+	case reflect.Map, reflect.Interface, reflect.Slice, reflect.Array:
+		return nil, false, true, false
+	default:
+		return nil, false, false, false
+	}
+}
+
+// jsonField returns the type of the struct field encoded with the given JSON name
+func jsonField(t reflect.Type, name string) (reflect.Type, bool, bool) {
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || (field.Anonymous && field.Tag.Get("json") == "") {
+			continue
+		}
+		tagName, tagOptions, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tagName == "-" && tagOptions == "" {
+			continue
+		}
+		if tagName == "" {
+			tagName = field.Name
+		}
+		if tagName == name {
+			return field.Type, strings.Contains(","+tagOptions+",", ",omitempty,"), true
+		}
+	}
+	return nil, false, false
+}
+
+// compatibleType checks if values of the Go type are encoded as the given typesense field type
+func compatibleType(t reflect.Type, fieldType string) bool {
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		// custom encodings can't be verified
+		return true
+	}
+	t = derefType(t)
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+
+	if elementType, ok := strings.CutSuffix(fieldType, "[]"); ok {
+		if (t.Kind() != reflect.Slice && t.Kind() != reflect.Array) || t.Elem().Kind() == reflect.Uint8 {
+			return false
+		}
+		return compatibleType(t.Elem(), elementType)
+	}
+
+	switch fieldType {
+	case "string", "image":
+		return t.Kind() == reflect.String
+	case "int32", "int64":
+		return isInteger(t.Kind())
+	case "float":
+		return isInteger(t.Kind()) || t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64
+	case "bool":
+		return t.Kind() == reflect.Bool
+	case "geopoint":
+		return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && compatibleType(t.Elem(), "float")
+	case "object":
+		return t.Kind() == reflect.Struct || t.Kind() == reflect.Map
+	default:
+		// auto, string* and unknown types accept any value
+		return true
+	}
+}
+
+func isInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
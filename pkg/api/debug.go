@@ -0,0 +1,95 @@
+package typesenseapi
+
+import (
+	"context"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+type searchDebugContextKey struct{}
+
+// WithSearchDebug makes the searches of the returned context report the parameters sent to typesense together with
+// the timing and the ranking information of every hit in SearchResult.Debug, they are logged at debug level as well
+func WithSearchDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, searchDebugContextKey{}, true)
+}
+
+// IsSearchDebug checks if searches of the context are debugged
+func IsSearchDebug(ctx context.Context) bool {
+	debug, _ := ctx.Value(searchDebugContextKey{}).(bool)
+	return debug
+}
+
+// searchDebug describes the executed search and the ranking of its hits
+func searchDebug(
+	collectionName string,
+	parameters *api.SearchCollectionParams,
+	response *api.SearchResult,
+	duration time.Duration,
+) *pkgx.SearchDebug {
+	debug := &pkgx.SearchDebug{
+		Collection: collectionName,
+		Parameters: parameters,
+		Duration:   duration,
+	}
+	if response.SearchTimeMs != nil {
+		debug.SearchTime = time.Duration(*response.SearchTimeMs) * time.Millisecond
+	}
+	if response.SearchCutoff != nil {
+		debug.Cutoff = *response.SearchCutoff
+	}
+	if response.Hits == nil {
+		return debug
+	}
+
+	debug.Hits = make([]pkgx.HitDebug, len(*response.Hits))
+	for i, hit := range *response.Hits {
+		hitDebug := pkgx.HitDebug{
+			Position:       i,
+			VectorDistance: hit.VectorDistance,
+		}
+		if hit.Document != nil {
+			if id, ok := (*hit.Document)["id"].(string); ok {
+				hitDebug.DocumentID = pkgx.DocumentID(id)
+			}
+		}
+		if hit.TextMatch != nil {
+			hitDebug.TextMatch = *hit.TextMatch
+		}
+		if hit.GeoDistanceMeters != nil {
+			hitDebug.GeoDistanceMeters = *hit.GeoDistanceMeters
+		}
+		if info := hit.TextMatchInfo; info != nil {
+			hitDebug.BestFieldScore = pointerValue(info.BestFieldScore)
+			hitDebug.BestFieldWeight = pointerValue(info.BestFieldWeight)
+			hitDebug.FieldsMatched = pointerValue(info.FieldsMatched)
+			hitDebug.TokensMatched = pointerValue(info.TokensMatched)
+			hitDebug.TypoPrefixScore = pointerValue(info.TypoPrefixScore)
+			hitDebug.NumTokensDropped = pointerValue(info.NumTokensDropped)
+		}
+		debug.Hits[i] = hitDebug
+	}
+	return debug
+}
+
+// logSearchDebug logs the debug information of a search
+func logSearchDebug(l *zap.Logger, debug *pkgx.SearchDebug) {
+	l.Debug("search debug",
+		zap.String("collection", debug.Collection),
+		zap.Any("parameters", debug.Parameters),
+		zap.Duration("search_time", debug.SearchTime),
+		zap.Duration("duration", debug.Duration),
+		zap.Any("hits", debug.Hits),
+	)
+}
+
+func pointerValue[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
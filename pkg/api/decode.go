@@ -0,0 +1,229 @@
+package typesenseapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// IDExtractor returns the document ID of a raw search hit document
+type IDExtractor func(indexID pkgx.IndexID, document map[string]any) (pkgx.DocumentID, bool)
+
+var jsonUnmarshalerType = reflect.TypeFor[json.Unmarshaler]()
+
+// documentDecoder decodes search hits into indexDocument. For struct documents the json field names are
+// mapped to the struct fields once, so hit values are assigned directly instead of taking a JSON round trip.
+// Values that can't be assigned directly, e.g. nested objects or types implementing json.Unmarshaler,
+// are decoded with encoding/json. Keys match the field names exactly first and case-insensitively otherwise.
+type documentDecoder[indexDocument any] struct {
+	fields      map[string][]int
+	foldedNames map[string]string
+	// quoted holds the fields tagged with the ",string" option, their values are JSON encoded strings
+	quoted map[string]bool
+}
+
+func newDocumentDecoder[indexDocument any]() *documentDecoder[indexDocument] {
+	d := &documentDecoder[indexDocument]{}
+	t := reflect.TypeFor[indexDocument]()
+	if t.Kind() != reflect.Struct {
+		return d
+	}
+
+	fields := map[string][]int{}
+	foldedNames := map[string]string{}
+	quoted := map[string]bool{}
+	for _, field := range reflect.VisibleFields(t) {
+		if field.Anonymous && field.Type.Kind() == reflect.Pointer {
+			// embedded pointers would have to be allocated, leave them to encoding/json
+			return d
+		}
+		if !field.IsExported() || (field.Anonymous && field.Tag.Get("json") == "") {
+			continue
+		}
+		name, tagOptions, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && tagOptions == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if _, ok := fields[name]; ok && len(field.Index) > len(fields[name]) {
+			// shallower fields take precedence like in encoding/json
+			continue
+		}
+		fields[name] = field.Index
+		if _, ok := foldedNames[strings.ToLower(name)]; !ok {
+			foldedNames[strings.ToLower(name)] = name
+		}
+		if slices.Contains(strings.Split(tagOptions, ","), "string") && quotable(field.Type) {
+			quoted[name] = true
+		}
+	}
+	d.fields = fields
+	d.foldedNames = foldedNames
+	d.quoted = quoted
+	return d
+}
+
+// quotable checks if the ",string" option applies to the type like in encoding/json
+func quotable(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer && t.Name() == "" {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// decode converts the document of a hit into indexDocument
+func (d *documentDecoder[indexDocument]) decode(document map[string]any) (indexDocument, error) {
+	var doc indexDocument
+	if d.fields == nil {
+		if v, ok := any(document).(indexDocument); ok {
+			return v, nil
+		}
+		data, err := json.Marshal(document)
+		if err != nil {
+			return doc, err
+		}
+		err = json.Unmarshal(data, &doc)
+		return doc, err
+	}
+
+	v := reflect.ValueOf(&doc).Elem()
+	var folded []string
+	for key, value := range document {
+		if _, ok := d.fields[key]; !ok {
+			folded = append(folded, key)
+			continue
+		}
+		if err := d.decodeField(v, key, value); err != nil {
+			return doc, err
+		}
+	}
+	// keys matching case-insensitively only fill fields without an exact match, in a stable order
+	sort.Strings(folded)
+	filled := map[string]bool{}
+	for _, key := range folded {
+		name, ok := d.foldedNames[strings.ToLower(key)]
+		if !ok || filled[name] {
+			continue
+		}
+		if _, exact := document[name]; exact {
+			continue
+		}
+		if err := d.decodeField(v, name, document[key]); err != nil {
+			return doc, err
+		}
+		filled[name] = true
+	}
+	return doc, nil
+}
+
+// decodeField decodes the value into the field with the given json name
+func (d *documentDecoder[indexDocument]) decodeField(v reflect.Value, name string, value any) error {
+	if value == nil {
+		return nil
+	}
+	field := v.FieldByIndex(d.fields[name])
+	if d.quoted[name] {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("json: invalid use of ,string struct tag, trying to unmarshal %T into %s", value, field.Type())
+		}
+		return json.Unmarshal([]byte(s), field.Addr().Interface())
+	}
+	if assignValue(field, value) {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, field.Addr().Interface())
+}
+
+// assignValue sets scalar JSON values directly and reports whether it succeeded
+func assignValue(field reflect.Value, value any) bool {
+	if field.Addr().Type().Implements(jsonUnmarshalerType) {
+		return false
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(field.Type()) {
+		field.Set(rv)
+		return true
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			field.SetString(s)
+			return true
+		}
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			field.SetBool(b)
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if f, ok := value.(float64); ok && f == math.Trunc(f) && !field.OverflowInt(int64(f)) {
+			field.SetInt(int64(f))
+			return true
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if f, ok := value.(float64); ok && f >= 0 && f == math.Trunc(f) && !field.OverflowUint(uint64(f)) {
+			field.SetUint(uint64(f))
+			return true
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, ok := value.(float64); ok && !field.OverflowFloat(f) {
+			field.SetFloat(f)
+			return true
+		}
+	default:
+		return false
+	}
+	return false
+}
+
+// documentID returns the ID of a decoded document if it implements pkgx.Identifiable
+func documentID[indexDocument any](doc *indexDocument) (pkgx.DocumentID, bool) {
+	if identifiable, ok := any(doc).(pkgx.Identifiable); ok {
+		return identifiable.DocumentID(), true
+	}
+	if identifiable, ok := any(*doc).(pkgx.Identifiable); ok {
+		return identifiable.DocumentID(), true
+	}
+	return "", false
+}
+
+// fieldDocumentID reads the document ID from the given field, numeric IDs are formatted as strings
+func fieldDocumentID(document map[string]any, field string) (pkgx.DocumentID, bool) {
+	switch id := document[field].(type) {
+	case string:
+		return pkgx.DocumentID(id), id != ""
+	case float64:
+		return pkgx.DocumentID(strconv.FormatFloat(id, 'f', -1, 64)), true
+	case json.Number:
+		return pkgx.DocumentID(id.String()), true
+	case int:
+		return pkgx.DocumentID(strconv.Itoa(id)), true
+	case int64:
+		return pkgx.DocumentID(strconv.FormatInt(id, 10)), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,163 @@
+package typesenseapi
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// contentHashField stores the hash of the imported document, it is not part of the schema and not indexed
+const contentHashField = "_content_hash"
+
+// maxExportLineSize limits the size of a single exported document
+const maxExportLineSize = 1 << 20
+
+// contentHashes caches the document hashes of the collections of uncommitted revisions by collection name
+type contentHashes struct {
+	mu          sync.Mutex
+	collections map[string]map[string]string
+}
+
+// deduplicateDocuments removes documents whose content hash matches the document already stored in the collection
+// and adds the content hash to the remaining ones. It returns the documents to import and their hashes by the
+// configured ID field of the index.
+func (b *BaseAPI[indexDocument, returnType]) deduplicateDocuments(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	collectionName string,
+	documents []interface{},
+) ([]interface{}, map[string]string, error) {
+	idField := b.idField(indexID)
+	existing, err := b.existingHashes(ctx, collectionName, idField)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pending := make([]interface{}, 0, len(documents))
+	hashes := make(map[string]string, len(documents))
+	for _, document := range documents {
+		fields, ok := document.(map[string]any)
+		if !ok {
+			data, err := json.Marshal(document)
+			if err != nil {
+				return nil, nil, err
+			}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, nil, err
+			}
+		}
+		if fields == nil {
+			// nil documents are not imported
+			continue
+		}
+		delete(fields, contentHashField)
+
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, nil, err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+
+		if id, ok := fieldDocumentID(fields, idField); ok {
+			b.hashes.mu.Lock()
+			unchanged := existing[string(id)] == hash
+			b.hashes.mu.Unlock()
+			if unchanged {
+				continue
+			}
+			hashes[string(id)] = hash
+		}
+		fields[contentHashField] = hash
+		pending = append(pending, fields)
+	}
+	return pending, hashes, nil
+}
+
+// existingHashes exports the IDs and content hashes of the collection once per collection,
+// the IDs are read from the given ID field
+func (b *BaseAPI[indexDocument, returnType]) existingHashes(
+	ctx context.Context,
+	collectionName string,
+	idField string,
+) (map[string]string, error) {
+	l := pkgx.Logger(ctx, b.l)
+	b.hashes.mu.Lock()
+	existing, ok := b.hashes.collections[collectionName]
+	b.hashes.mu.Unlock()
+	if ok {
+		return existing, nil
+	}
+
+	body, err := b.client.Collection(collectionName).Documents().Export(ctx, &api.ExportDocumentsParams{
+		IncludeFields: pointer.String(idField + "," + contentHashField),
+	})
+	if err != nil {
+		l.Error("failed to export document hashes", zap.String("collection", collectionName), zap.Error(err))
+		return nil, err
+	}
+	defer body.Close()
+
+	existing = map[string]string{}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxExportLineSize)
+	for scanner.Scan() {
+		var document map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+			continue
+		}
+		id, ok := fieldDocumentID(document, idField)
+		if hash, _ := document[contentHashField].(string); ok && hash != "" {
+			existing[string(id)] = hash
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		l.Error("failed to read document hashes", zap.String("collection", collectionName), zap.Error(err))
+		return nil, err
+	}
+
+	b.hashes.mu.Lock()
+	defer b.hashes.mu.Unlock()
+	if b.hashes.collections == nil {
+		b.hashes.collections = map[string]map[string]string{}
+	}
+	b.hashes.collections[collectionName] = existing
+	l.Info("loaded document hashes", zap.String("collection", collectionName), zap.Int("documents", len(existing)))
+	return existing, nil
+}
+
+// recordHashes remembers the hashes of successfully imported documents
+func (b *BaseAPI[indexDocument, returnType]) recordHashes(collectionName string, hashes map[string]string) {
+	b.hashes.mu.Lock()
+	defer b.hashes.mu.Unlock()
+	existing, ok := b.hashes.collections[collectionName]
+	if !ok {
+		return
+	}
+	for id, hash := range hashes {
+		existing[id] = hash
+	}
+}
+
+// forgetHashes drops the cached hashes of a committed or reverted collection
+func (b *BaseAPI[indexDocument, returnType]) forgetHashes(collectionName string) {
+	b.hashes.mu.Lock()
+	defer b.hashes.mu.Unlock()
+	delete(b.hashes.collections, collectionName)
+}
+
+// idField returns the field holding the document ID of the index, see WithIDFields
+func (b *BaseAPI[indexDocument, returnType]) idField(indexID pkgx.IndexID) string {
+	if field, ok := b.opts.idFields[indexID]; ok {
+		return field
+	}
+	return "id"
+}
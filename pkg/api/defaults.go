@@ -0,0 +1,204 @@
+package typesenseapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// FieldRule adjusts a single field of a document before it is imported
+type FieldRule struct {
+	Field string
+	// FromField is copied into Field if Field is empty
+	FromField string
+	// Value is set if Field is still empty
+	Value any
+	// Coerce converts the value to the given typesense field type, e.g. "int32" or "string[]"
+	Coerce string
+}
+
+// DefaultFromField copies the value of another field if the field is empty
+func DefaultFromField(field, fromField string) FieldRule {
+	return FieldRule{Field: field, FromField: fromField}
+}
+
+// DefaultValue sets a constant value if the field is empty
+func DefaultValue(field string, value any) FieldRule {
+	return FieldRule{Field: field, Value: value}
+}
+
+// CoerceType converts the field value to the given typesense field type
+func CoerceType(field, fieldType string) FieldRule {
+	return FieldRule{Field: field, Coerce: fieldType}
+}
+
+// applyFieldRules converts the document into a map and applies the rules in order.
+// Numbers are kept as json.Number, so large integers are imported without losing precision.
+func applyFieldRules(document any, rules []FieldRule) (map[string]any, error) {
+	b, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if isEmptyValue(fields[rule.Field]) && rule.FromField != "" && !isEmptyValue(fields[rule.FromField]) {
+			fields[rule.Field] = fields[rule.FromField]
+		}
+		if isEmptyValue(fields[rule.Field]) && rule.Value != nil {
+			fields[rule.Field] = rule.Value
+		}
+		if rule.Coerce != "" && fields[rule.Field] != nil {
+			value, err := coerceValue(fields[rule.Field], rule.Coerce)
+			if err != nil {
+				return nil, fmt.Errorf("failed to coerce field %q: %w", rule.Field, err)
+			}
+			fields[rule.Field] = value
+		}
+	}
+	return fields, nil
+}
+
+func isEmptyValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
+func coerceValue(value any, fieldType string) (any, error) {
+	if elementType, ok := strings.CutSuffix(fieldType, "[]"); ok {
+		values, isSlice := value.([]any)
+		if !isSlice {
+			values = []any{value}
+		}
+		coerced := make([]any, 0, len(values))
+		for _, v := range values {
+			c, err := coerceValue(v, elementType)
+			if err != nil {
+				return nil, err
+			}
+			coerced = append(coerced, c)
+		}
+		return coerced, nil
+	}
+
+	switch fieldType {
+	case "string":
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case json.Number:
+			return v.String(), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case int:
+			return strconv.Itoa(v), nil
+		case int64:
+			return strconv.FormatInt(v, 10), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+	case "int32", "int64":
+		var (
+			i   int64
+			err error
+		)
+		switch v := value.(type) {
+		case json.Number:
+			i, err = parseInt(v.String())
+		case string:
+			i, err = parseInt(strings.TrimSpace(v))
+		case float64:
+			i, err = integral(v)
+		case int:
+			i = int64(v)
+		case int64:
+			i = v
+		case bool:
+			if v {
+				i = 1
+			}
+		default:
+			return nil, fmt.Errorf("can't convert %T to %s", value, fieldType)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fieldType == "int32" && (i < math.MinInt32 || i > math.MaxInt32) {
+			return nil, fmt.Errorf("%d overflows int32", i)
+		}
+		return i, nil
+	case "float":
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case json.Number:
+			return v.Float64()
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case string:
+			return strconv.ParseFloat(strings.TrimSpace(v), 64)
+		}
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			return strconv.ParseBool(strings.TrimSpace(v))
+		case json.Number:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return f != 0, nil
+		case float64:
+			return v != 0, nil
+		case int:
+			return v != 0, nil
+		case int64:
+			return v != 0, nil
+		}
+	default:
+		return nil, fmt.Errorf("unsupported field type %q", fieldType)
+	}
+	return nil, fmt.Errorf("can't convert %T to %s", value, fieldType)
+}
+
+// parseInt parses an integer, numbers with a fraction or exponent are accepted if they are integral, e.g. "2.0"
+func parseInt(s string) (int64, error) {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return integral(f)
+}
+
+// integral converts the float to an integer, it fails instead of truncating a fraction
+func integral(f float64) (int64, error) {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%v is not an integer", f)
+	}
+	return int64(f), nil
+}
@@ -0,0 +1,50 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// DeleteDocuments deletes the documents matching the filter from the collection of the revision, e.g. expired
+// campaigns from the served revision without a full reindex. It returns the number of deleted documents.
+func (b *BaseAPI[indexDocument, returnType]) DeleteDocuments(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	filterBy string,
+) (int, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if filterBy == "" {
+		return 0, errors.New("filter must not be empty")
+	}
+	if _, ok := b.collections[indexID]; !ok {
+		return 0, fmt.Errorf("index %s is not configured", indexID)
+	}
+
+	collectionName := formatCollectionName(indexID, revisionID)
+	deleted, err := b.client.Collection(collectionName).Documents().Delete(ctx, &api.DeleteDocumentsParams{
+		FilterBy: pointer.String(filterBy),
+	})
+	// cached content hashes would skip the deleted documents on their next upsert
+	b.forgetHashes(collectionName)
+	if err != nil {
+		l.Error("failed to delete documents",
+			zap.String("collection", collectionName),
+			zap.String("filter", filterBy),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	l.Info("deleted documents",
+		zap.String("collection", collectionName),
+		zap.String("filter", filterBy),
+		zap.Int("count", deleted),
+	)
+	return deleted, nil
+}
@@ -0,0 +1,156 @@
+package typesenseapi
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// deprecationsCollectionName is the registry collection counting the revisions created since a field was deprecated,
+// one document per index and field
+const deprecationsCollectionName = "typesense-deprecations"
+
+// fieldDeprecation is the registry document of a deprecated field
+type fieldDeprecation struct {
+	ID        string          `json:"id"`
+	IndexID   pkgx.IndexID    `json:"index"`
+	Field     string          `json:"field"`
+	Since     pkgx.RevisionID `json:"since"`
+	Revisions int             `json:"revisions"`
+}
+
+// revisionSchema returns the schema of the collection of the index for the revision. Deprecated fields are kept
+// until the configured number of revisions has been created since their deprecation and dropped afterwards.
+func (b *BaseAPI[indexDocument, returnType]) revisionSchema(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	revisionID pkgx.RevisionID,
+) (*api.CollectionSchema, error) {
+	schema := b.collections[indexID]
+	deprecated := b.opts.deprecatedFields[indexID]
+	if len(deprecated) == 0 || schema == nil {
+		return schema, nil
+	}
+	l := pkgx.Logger(ctx, b.l)
+	if err := b.createRegistryCollection(ctx, deprecationsCollectionName, []api.Field{
+		{Name: "index", Type: "string"},
+	}); err != nil {
+		return nil, err
+	}
+
+	var dropped []string
+	for field, keepRevisions := range deprecated {
+		if !slices.ContainsFunc(schema.Fields, func(f api.Field) bool { return f.Name == field }) {
+			continue
+		}
+		deprecation, err := b.fieldDeprecation(ctx, indexID, field, revisionID)
+		if err != nil {
+			return nil, err
+		}
+		if deprecation.Revisions >= keepRevisions {
+			dropped = append(dropped, field)
+			l.Warn("dropping deprecated field",
+				zap.String("index", string(indexID)),
+				zap.String("field", field),
+				zap.String("since", string(deprecation.Since)),
+			)
+			continue
+		}
+		deprecation.Revisions++
+		if _, err := b.client.Collection(deprecationsCollectionName).Documents().Upsert(ctx, deprecation, &api.DocumentIndexParameters{}); err != nil {
+			l.Error("failed to record deprecated field", zap.String("index", string(indexID)), zap.String("field", field), zap.Error(err))
+			return nil, err
+		}
+		l.Info("keeping deprecated field",
+			zap.String("index", string(indexID)),
+			zap.String("field", field),
+			zap.Int("revisions_left", keepRevisions-deprecation.Revisions),
+		)
+	}
+	if len(dropped) == 0 {
+		return schema, nil
+	}
+
+	revision := *schema
+	revision.Fields = slices.DeleteFunc(slices.Clone(schema.Fields), func(f api.Field) bool {
+		return slices.Contains(dropped, f.Name)
+	})
+	return &revision, nil
+}
+
+// fieldDeprecation returns the registry document of the deprecated field, starting with the given revision
+func (b *BaseAPI[indexDocument, returnType]) fieldDeprecation(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	field string,
+	revisionID pkgx.RevisionID,
+) (*fieldDeprecation, error) {
+	deprecation := &fieldDeprecation{
+		ID:      string(indexID) + "." + field,
+		IndexID: indexID,
+		Field:   field,
+		Since:   revisionID,
+	}
+	document, err := b.client.Collection(deprecationsCollectionName).Document(deprecation.ID).Retrieve(ctx)
+	if isNotFound(err) {
+		return deprecation, nil
+	}
+	if err != nil {
+		pkgx.Logger(ctx, b.l).Error("failed to retrieve deprecated field", zap.String("id", deprecation.ID), zap.Error(err))
+		return nil, err
+	}
+	if since, ok := document["since"].(string); ok {
+		deprecation.Since = pkgx.RevisionID(since)
+	}
+	if revisions, ok := document["revisions"].(float64); ok {
+		deprecation.Revisions = int(revisions)
+	}
+	return deprecation, nil
+}
+
+// logDeprecatedUsage warns about deprecated fields used by the search parameters, the first usage of a field is
+// logged as warning and later ones as debug messages
+func (b *BaseAPI[indexDocument, returnType]) logDeprecatedUsage(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) {
+	deprecated := b.opts.deprecatedFields[indexID]
+	if len(deprecated) == 0 {
+		return
+	}
+	used := map[string]bool{}
+	for _, value := range []*string{
+		parameters.QueryBy,
+		parameters.FilterBy,
+		parameters.SortBy,
+		parameters.FacetBy,
+		parameters.GroupBy,
+		parameters.IncludeFields,
+	} {
+		if value == nil {
+			continue
+		}
+		for _, token := range strings.FieldsFunc(*value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.'
+		}) {
+			if _, ok := deprecated[token]; ok {
+				used[token] = true
+			}
+		}
+	}
+
+	l := pkgx.Logger(ctx, b.l)
+	for field := range used {
+		if _, logged := b.deprecationWarnings.LoadOrStore(string(indexID)+"."+field, true); logged {
+			l.Debug("search uses deprecated field", zap.String("index", string(indexID)), zap.String("field", field))
+			continue
+		}
+		l.Warn("search uses deprecated field", zap.String("index", string(indexID)), zap.String("field", field))
+	}
+}
@@ -0,0 +1,56 @@
+package typesenseapi
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// existenceBatchSize limits the number of IDs per existence query, it matches the maximum page size of typesense
+const existenceBatchSize = 250
+
+// ExistingIDs returns which of the given document IDs exist in the collection currently served by the alias of
+// the index, e.g. for delta pipelines computing deletions. The IDs are checked with batched filter queries.
+func (b *BaseAPI[indexDocument, returnType]) ExistingIDs(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	ids []pkgx.DocumentID,
+) (map[pkgx.DocumentID]bool, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if err := b.checkIndex(indexID); err != nil {
+		return nil, err
+	}
+	existing := make(map[pkgx.DocumentID]bool, len(ids))
+	for start := 0; start < len(ids); start += existenceBatchSize {
+		batch := ids[start:min(start+existenceBatchSize, len(ids))]
+		values := make([]any, len(batch))
+		for i, id := range batch {
+			values[i] = string(id)
+		}
+		response, err := b.client.Collection(string(indexID)).Documents().Search(ctx, &api.SearchCollectionParams{
+			Q:             pointer.String("*"),
+			FilterBy:      pointer.String(pkgx.NewFilterBuilder().In("id", values...).Build()),
+			IncludeFields: pointer.String("id"),
+			PerPage:       pointer.Int(len(batch)),
+		})
+		if err != nil {
+			l.Error("failed to check document ids", zap.String("index", string(indexID)), zap.Error(err))
+			return nil, err
+		}
+		if response.Hits == nil {
+			continue
+		}
+		for _, hit := range *response.Hits {
+			if hit.Document == nil {
+				continue
+			}
+			if id, ok := (*hit.Document)["id"].(string); ok {
+				existing[pkgx.DocumentID(id)] = true
+			}
+		}
+	}
+	return existing, nil
+}
@@ -0,0 +1,311 @@
+package typesenseapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultExportChunkSize = 1000
+	// exportChunkBuffer is the number of chunks read ahead of the consumer
+	exportChunkBuffer = 4
+	// defaultExportIdleTimeout is the time a job waits for Next before it is cancelled
+	defaultExportIdleTimeout = 5 * time.Minute
+)
+
+var (
+	// ErrExportJobNotFound is returned for unknown, finished or cancelled export jobs
+	ErrExportJobNotFound = errors.New("export job not found")
+	// ErrExportJobIdle is the error of export jobs cancelled because Next was not called within the idle timeout
+	ErrExportJobIdle = errors.New("export job idle")
+)
+
+// ExportOption configures an export job
+type ExportOption func(o *exportOptions)
+
+type exportOptions struct {
+	revisionID    pkgx.RevisionID
+	chunkSize     int
+	filterBy      string
+	includeFields []string
+	idleTimeout   time.Duration
+}
+
+// ExportRevision exports the collection of the revision instead of the collection served by the alias
+func ExportRevision(revisionID pkgx.RevisionID) ExportOption {
+	return func(o *exportOptions) {
+		o.revisionID = revisionID
+	}
+}
+
+// ExportChunkSize sets the number of documents per chunk
+func ExportChunkSize(size int) ExportOption {
+	return func(o *exportOptions) {
+		o.chunkSize = size
+	}
+}
+
+// ExportFilter exports only the documents matching the filter
+func ExportFilter(filterBy string) ExportOption {
+	return func(o *exportOptions) {
+		o.filterBy = filterBy
+	}
+}
+
+// ExportIncludeFields exports only the given fields of the documents
+func ExportIncludeFields(fields ...string) ExportOption {
+	return func(o *exportOptions) {
+		o.includeFields = fields
+	}
+}
+
+// ExportIdleTimeout cancels the job if Next is not called within the timeout, e.g. because the consumer is gone,
+// so abandoned jobs don't hold their export open forever
+func ExportIdleTimeout(timeout time.Duration) ExportOption {
+	return func(o *exportOptions) {
+		o.idleTimeout = timeout
+	}
+}
+
+// ExportProgress describes the state of an export job
+type ExportProgress struct {
+	JobID      string       `json:"job_id"`
+	IndexID    pkgx.IndexID `json:"index"`
+	Collection string       `json:"collection"`
+	StartedAt  time.Time    `json:"started_at"`
+	// Total is the number of documents of the collection when the export started, filtered exports may return less
+	Total int `json:"total"`
+	// Exported is the number of documents read from typesense so far
+	Exported int    `json:"exported"`
+	Chunks   int    `json:"chunks"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ExportJob exports a collection in the background and hands out the documents in chunks of JSON lines, so admin
+// tooling can poll the progress and fetch the chunks with short requests instead of one request for the whole export.
+// Chunks are read ahead only a few at a time, the export waits for the consumer until the idle timeout.
+type ExportJob struct {
+	l           *zap.Logger
+	chunks      chan []byte
+	cancel      context.CancelCauseFunc
+	remove      func()
+	idle        *time.Timer
+	idleTimeout time.Duration
+
+	mu       sync.Mutex
+	progress ExportProgress
+	err      error
+}
+
+// StartExport starts an export job of the index, the job runs until all chunks are read with Next, it fails or is
+// cancelled. Running jobs can be looked up by their ID with ExportJob.
+func (b *BaseAPI[indexDocument, returnType]) StartExport(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	opts ...ExportOption,
+) (*ExportJob, error) {
+	l := pkgx.Logger(ctx, b.l)
+	o := exportOptions{chunkSize: defaultExportChunkSize, idleTimeout: defaultExportIdleTimeout}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.chunkSize < 1 {
+		return nil, errors.New("export chunk size must be positive")
+	}
+	if o.idleTimeout <= 0 {
+		return nil, errors.New("export idle timeout must be positive")
+	}
+	if _, ok := b.collections[indexID]; !ok {
+		return nil, fmt.Errorf("index %s is not configured", indexID)
+	}
+
+	collectionName := string(indexID)
+	if o.revisionID != "" {
+		collectionName = formatCollectionName(indexID, o.revisionID)
+	}
+	collection, err := b.client.Collection(collectionName).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve collection", zap.String("collection", collectionName), zap.Error(err))
+		return nil, err
+	}
+
+	params := &api.ExportDocumentsParams{}
+	if o.filterBy != "" {
+		params.FilterBy = pointer.String(o.filterBy)
+	}
+	if len(o.includeFields) > 0 {
+		params.IncludeFields = pointer.String(strings.Join(o.includeFields, ","))
+	}
+
+	startedAt := b.opts.clock.Now()
+	jobID := fmt.Sprintf("%s-%d", indexID, startedAt.UnixNano())
+	// the job outlives the request starting it
+	jobCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	job := &ExportJob{
+		l:           l,
+		chunks:      make(chan []byte, exportChunkBuffer),
+		cancel:      cancel,
+		remove:      func() { b.exportJobs.Delete(jobID) },
+		idleTimeout: o.idleTimeout,
+		progress: ExportProgress{
+			JobID:      jobID,
+			IndexID:    indexID,
+			Collection: collection.Name,
+			StartedAt:  startedAt,
+		},
+	}
+	if collection.NumDocuments != nil {
+		job.progress.Total = int(*collection.NumDocuments)
+	}
+	job.idle = time.AfterFunc(o.idleTimeout, func() {
+		job.l.Warn("cancelling idle export", zap.String("job", jobID), zap.Duration("timeout", o.idleTimeout))
+		job.cancel(ErrExportJobIdle)
+		job.remove()
+	})
+	b.exportJobs.Store(jobID, job)
+
+	go job.run(jobCtx, b.client.Collection(collectionName).Documents(), params, o.chunkSize)
+	l.Info("started export",
+		zap.String("job", jobID),
+		zap.String("collection", collection.Name),
+		zap.Int("documents", job.progress.Total),
+	)
+	return job, nil
+}
+
+// ExportJob returns the running export job with the given ID
+func (b *BaseAPI[indexDocument, returnType]) ExportJob(jobID string) (*ExportJob, error) {
+	job, ok := b.exportJobs.Load(jobID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrExportJobNotFound, jobID)
+	}
+	return job.(*ExportJob), nil //nolint:forcetypeassert // only export jobs are stored
+}
+
+// ID returns the ID of the job
+func (j *ExportJob) ID() string {
+	return j.progress.JobID
+}
+
+// Progress returns the current state of the job
+func (j *ExportJob) Progress() ExportProgress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.progress
+}
+
+// Next returns the next chunk of documents as JSON lines, io.EOF after the last chunk
+// or the error the export failed with
+func (j *ExportJob) Next(ctx context.Context) ([]byte, error) {
+	j.idle.Reset(j.idleTimeout)
+	select {
+	case chunk, ok := <-j.chunks:
+		if ok {
+			return chunk, nil
+		}
+		j.idle.Stop()
+		j.remove()
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if j.err != nil {
+			return nil, j.err
+		}
+		return nil, io.EOF
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Cancel stops the export and releases the job
+func (j *ExportJob) Cancel() {
+	j.idle.Stop()
+	j.cancel(context.Canceled)
+	j.remove()
+}
+
+// run reads the export and sends the documents in chunks until the export is read or the job is cancelled
+func (j *ExportJob) run(
+	ctx context.Context,
+	documents typesense.DocumentsInterface,
+	params *api.ExportDocumentsParams,
+	chunkSize int,
+) {
+	defer close(j.chunks)
+	defer j.cancel(nil)
+
+	err := func() error {
+		body, err := documents.Export(ctx, params)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxExportLineSize)
+		var chunk bytes.Buffer
+		count := 0
+		for scanner.Scan() {
+			chunk.Write(scanner.Bytes())
+			chunk.WriteByte('\n')
+			if count++; count == chunkSize {
+				if err := j.send(ctx, &chunk, count); err != nil {
+					return err
+				}
+				count = 0
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return err
+		}
+		if count > 0 {
+			return j.send(ctx, &chunk, count)
+		}
+		return nil
+	}()
+	if cause := context.Cause(ctx); err != nil && errors.Is(cause, ErrExportJobIdle) {
+		err = cause
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.Done = true
+	if err != nil {
+		j.err = err
+		j.progress.Error = err.Error()
+		j.l.Error("export failed", zap.String("job", j.progress.JobID), zap.Error(err))
+		return
+	}
+	j.l.Info("export completed", zap.String("job", j.progress.JobID), zap.Int("documents", j.progress.Exported))
+}
+
+// send hands the chunk to the consumer and resets it
+func (j *ExportJob) send(ctx context.Context, chunk *bytes.Buffer, count int) error {
+	data := bytes.Clone(chunk.Bytes())
+	chunk.Reset()
+	select {
+	case j.chunks <- data:
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.progress.Exported += count
+	j.progress.Chunks++
+	return nil
+}
@@ -0,0 +1,115 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// Facets returns only the facet counts of the given fields for the documents matching the filter,
+// no hits are requested so e.g. filter sidebars can be refreshed cheaply. Without filter the default filter of the
+// index is used, enforced filters are always applied.
+func (b *BaseAPI[indexDocument, returnType]) Facets(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	facetFields []string,
+	filter string,
+) ([]pkgx.Facet, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if len(facetFields) == 0 {
+		return nil, errors.New("facet fields cannot be empty")
+	}
+	if err := b.checkIndex(indexID); err != nil {
+		return nil, err
+	}
+
+	enforcedFilter, err := b.enforcedFilter(ctx, indexID)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := &api.SearchCollectionParams{
+		Q:       pointer.String("*"),
+		FacetBy: pointer.String(strings.Join(facetFields, ",")),
+		PerPage: pointer.Int(0),
+	}
+	if filter == "" {
+		filter = b.opts.searchDefaults[indexID].FilterBy
+	}
+	if filter != "" {
+		parameters.FilterBy = pointer.String(filter)
+	}
+
+	collectionName := b.searchCollection(ctx, indexID)
+	searchResponse, err := b.client.Collection(collectionName).Documents().Search(ctx, withFilter(parameters, enforcedFilter))
+	if err != nil {
+		l.Error("failed to retrieve facets", zap.String("index", collectionName), zap.Error(err))
+		return nil, err
+	}
+	return convertFacets(searchResponse.FacetCounts), nil
+}
+
+// convertFacets converts the facet counts of a search response
+func convertFacets(facetCounts *[]api.FacetCounts) []pkgx.Facet {
+	if facetCounts == nil {
+		return nil
+	}
+	facets := make([]pkgx.Facet, 0, len(*facetCounts))
+	for _, facetCount := range *facetCounts {
+		facet := pkgx.Facet{
+			Values: []pkgx.FacetValue{},
+		}
+		if facetCount.FieldName != nil {
+			facet.Field = *facetCount.FieldName
+		}
+		if facetCount.Counts != nil {
+			for _, count := range *facetCount.Counts {
+				value := pkgx.FacetValue{}
+				if count.Value != nil {
+					value.Value = *count.Value
+				}
+				if count.Count != nil {
+					value.Count = *count.Count
+				}
+				facet.Values = append(facet.Values, value)
+			}
+		}
+		if stats := facetCount.Stats; stats != nil && (stats.Min != nil || stats.Max != nil) {
+			facet.Stats = &pkgx.FacetStats{}
+			if stats.TotalValues != nil {
+				facet.Stats.TotalValues = *stats.TotalValues
+			}
+			if stats.Min != nil {
+				facet.Stats.Min = *stats.Min
+			}
+			if stats.Max != nil {
+				facet.Stats.Max = *stats.Max
+			}
+			if stats.Avg != nil {
+				facet.Stats.Avg = *stats.Avg
+			}
+			if stats.Sum != nil {
+				facet.Stats.Sum = *stats.Sum
+			}
+		}
+		facets = append(facets, facet)
+	}
+	return facets
+}
+
+// facetsByField indexes the facets by their field name, it returns nil without facets
+func facetsByField(facets []pkgx.Facet) map[string]pkgx.Facet {
+	if len(facets) == 0 {
+		return nil
+	}
+	byField := make(map[string]pkgx.Facet, len(facets))
+	for _, facet := range facets {
+		byField[facet.Field] = facet
+	}
+	return byField
+}
@@ -0,0 +1,87 @@
+package typesenseapi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// dropTokensThreshold is high enough to make typesense drop tokens for any result count
+const dropTokensThreshold = 1000
+
+// Fallback derives relaxed search parameters from the ones that returned zero hits, the parameters include the
+// relaxations of the previous fallbacks of the chain.
+// Apply works on a copy of the parameters and returns nil if the fallback is not applicable.
+type Fallback struct {
+	Name  string
+	Apply func(params *api.SearchCollectionParams) *api.SearchCollectionParams
+}
+
+// FallbackRelaxFilters removes the filter_by expression
+func FallbackRelaxFilters() Fallback {
+	return Fallback{
+		Name: "relax_filters",
+		Apply: func(params *api.SearchCollectionParams) *api.SearchCollectionParams {
+			if params.FilterBy == nil || *params.FilterBy == "" {
+				return nil
+			}
+			params.FilterBy = nil
+			return params
+		},
+	}
+}
+
+// FallbackWidenTypos raises the number of allowed typos
+func FallbackWidenTypos(numTypos int) Fallback {
+	return Fallback{
+		Name: "widen_typos",
+		Apply: func(params *api.SearchCollectionParams) *api.SearchCollectionParams {
+			params.NumTypos = pointer.String(strconv.Itoa(numTypos))
+			params.TypoTokensThreshold = pointer.Int(dropTokensThreshold)
+			return params
+		},
+	}
+}
+
+// FallbackDropTokens lets typesense drop query tokens from both sides until documents match
+func FallbackDropTokens() Fallback {
+	return Fallback{
+		Name: "drop_tokens",
+		Apply: func(params *api.SearchCollectionParams) *api.SearchCollectionParams {
+			if params.Q == nil || !strings.Contains(strings.TrimSpace(*params.Q), " ") {
+				return nil
+			}
+			mode := api.BothSides3
+			params.DropTokensMode = &mode
+			params.DropTokensThreshold = pointer.Int(dropTokensThreshold)
+			return params
+		},
+	}
+}
+
+// FallbackPopularContent replaces the query with a wildcard query sorted by the given expression, e.g. "popularity:desc"
+func FallbackPopularContent(sortBy, filterBy string) Fallback {
+	return Fallback{
+		Name: "popular_content",
+		Apply: func(params *api.SearchCollectionParams) *api.SearchCollectionParams {
+			params.Q = pointer.String("*")
+			params.SortBy = pointer.String(sortBy)
+			params.FilterBy = nil
+			if filterBy != "" {
+				params.FilterBy = pointer.String(filterBy)
+			}
+			return params
+		},
+	}
+}
+
+// applyFallback runs the fallback on a shallow copy, leaving the given parameters untouched
+func applyFallback(fallback Fallback, params *api.SearchCollectionParams) *api.SearchCollectionParams {
+	if fallback.Apply == nil {
+		return nil
+	}
+	paramsCopy := *params
+	return fallback.Apply(&paramsCopy)
+}
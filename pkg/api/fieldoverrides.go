@@ -0,0 +1,63 @@
+package typesenseapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// createOverriddenCollection creates the collection with the raw properties merged into its fields, see
+// WithFieldOverrides. The schema is sent as raw JSON, because api.Field drops properties it does not model.
+func (b *BaseAPI[indexDocument, returnType]) createOverriddenCollection(
+	ctx context.Context,
+	schema *api.CollectionSchema,
+	overrides map[string]map[string]any,
+) error {
+	if b.opts.schemaClient == nil {
+		return errors.New("field overrides require a schema client")
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return err
+	}
+	raw := map[string]any{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	fields, _ := raw["fields"].([]any)
+	overridden := 0
+	for _, field := range fields {
+		properties, ok := field.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := properties["name"].(string)
+		if override, ok := overrides[name]; ok {
+			maps.Copy(properties, override)
+			overridden++
+		}
+	}
+	if overridden != len(overrides) {
+		return fmt.Errorf("field overrides reference fields missing in the schema of %s", schema.Name)
+	}
+
+	body, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	resp, err := b.opts.schemaClient.CreateCollectionWithBodyWithResponse(ctx, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return &typesense.HTTPError{Status: resp.StatusCode(), Body: resp.Body}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package typesenseapi
+
+import (
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// hitHighlights returns the highlighted snippets of the hit by field. The snippets of the highlights list are
+// preferred, the highlight object of newer typesense versions is used for fields missing in it.
+func hitHighlights(hit api.SearchResultHit) map[string][]string {
+	highlights := map[string][]string{}
+	if hit.Highlights != nil {
+		for _, highlight := range *hit.Highlights {
+			if highlight.Field == nil {
+				continue
+			}
+			switch {
+			case highlight.Snippet != nil:
+				highlights[*highlight.Field] = []string{*highlight.Snippet}
+			case highlight.Snippets != nil && len(*highlight.Snippets) > 0:
+				highlights[*highlight.Field] = *highlight.Snippets
+			}
+		}
+	}
+	if hit.Highlight != nil {
+		for field, value := range *hit.Highlight {
+			if _, ok := highlights[field]; ok {
+				continue
+			}
+			if snippets := highlightSnippets(value); len(snippets) > 0 {
+				highlights[field] = snippets
+			}
+		}
+	}
+	if len(highlights) == 0 {
+		return nil
+	}
+	return highlights
+}
+
+// highlightSnippets extracts the snippets of a field of the highlight object, array fields hold one object per
+// value and only values with matched tokens are returned
+func highlightSnippets(value any) []string {
+	switch v := value.(type) {
+	case map[string]any:
+		if snippet, ok := v["snippet"].(string); ok {
+			if tokens, ok := v["matched_tokens"].([]any); !ok || len(tokens) > 0 {
+				return []string{snippet}
+			}
+		}
+	case []any:
+		var snippets []string
+		for _, item := range v {
+			snippets = append(snippets, highlightSnippets(item)...)
+		}
+		return snippets
+	}
+	return nil
+}
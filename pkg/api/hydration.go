@@ -0,0 +1,73 @@
+package typesenseapi
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// FieldHydrator fetches the values of fields that are indexed but not stored, see typesenseschema.NoStore,
+// from their source by the IDs of the hit documents
+type FieldHydrator func(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	fields []string,
+	documentIDs []pkgx.DocumentID,
+) (map[pkgx.DocumentID]map[string]any, error)
+
+// hydrateHits adds the values of the non-stored fields of the index to the hit documents before they are converted,
+// failures are logged and the hits are converted without them
+func (b *BaseAPI[indexDocument, returnType]) hydrateHits(ctx context.Context, indexID pkgx.IndexID, hits []api.SearchResultHit) {
+	if b.opts.fieldHydrator == nil || len(hits) == 0 {
+		return
+	}
+	fields := b.nonStoredFields(indexID)
+	if len(fields) == 0 {
+		return
+	}
+	l := pkgx.Logger(ctx, b.l)
+
+	documentIDs := make([]pkgx.DocumentID, 0, len(hits))
+	for _, hit := range hits {
+		if hit.Document == nil {
+			continue
+		}
+		if id, ok := (*hit.Document)["id"].(string); ok {
+			documentIDs = append(documentIDs, pkgx.DocumentID(id))
+		}
+	}
+	values, err := b.opts.fieldHydrator(ctx, indexID, fields, documentIDs)
+	if err != nil {
+		l.Warn("failed to hydrate non-stored fields", zap.String("index", string(indexID)), zap.Error(err))
+		return
+	}
+
+	for _, hit := range hits {
+		if hit.Document == nil {
+			continue
+		}
+		id, _ := (*hit.Document)["id"].(string)
+		for field, value := range values[pkgx.DocumentID(id)] {
+			if _, ok := (*hit.Document)[field]; !ok {
+				(*hit.Document)[field] = value
+			}
+		}
+	}
+}
+
+// nonStoredFields returns the fields of the index schema that are not stored
+func (b *BaseAPI[indexDocument, returnType]) nonStoredFields(indexID pkgx.IndexID) []string {
+	schema, ok := b.collections[indexID]
+	if !ok || schema == nil {
+		return nil
+	}
+	var fields []string
+	for _, field := range schema.Fields {
+		if field.Store != nil && !*field.Store {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
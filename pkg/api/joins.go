@@ -0,0 +1,77 @@
+package typesenseapi
+
+import (
+	"sort"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// applyJoins adds the join filters and included reference fields of the search to the parameters
+func applyJoins(joins []pkgx.Join, parameters *api.SearchCollectionParams) {
+	filters := make([]string, 0, len(joins))
+	includes := make([]string, 0, len(joins))
+	for _, join := range joins {
+		if join.FilterBy != "" {
+			filters = append(filters, "$"+string(join.IndexID)+"("+join.FilterBy+")")
+		}
+		if len(join.IncludeFields) > 0 {
+			includes = append(includes, "$"+string(join.IndexID)+"("+strings.Join(join.IncludeFields, ",")+")")
+		}
+	}
+	if len(filters) > 0 {
+		if parameters.FilterBy != nil && *parameters.FilterBy != "" {
+			filters = append([]string{"(" + *parameters.FilterBy + ")"}, filters...)
+		}
+		parameters.FilterBy = pointer.String(strings.Join(filters, " && "))
+	}
+	if len(includes) > 0 {
+		addIncludeFields(parameters, includes...)
+	}
+}
+
+// addIncludeFields appends the fields to the included fields of the parameters
+func addIncludeFields(parameters *api.SearchCollectionParams, fields ...string) {
+	if parameters.IncludeFields != nil && *parameters.IncludeFields != "" {
+		fields = append([]string{*parameters.IncludeFields}, fields...)
+	}
+	parameters.IncludeFields = pointer.String(strings.Join(fields, ","))
+}
+
+// creationOrder returns the configured indices sorted by name with referenced indices first,
+// so their aliases exist when the collections referencing them are created
+func (b *BaseAPI[indexDocument, returnType]) creationOrder() []pkgx.IndexID {
+	names := make([]string, 0, len(b.collections))
+	for indexID := range b.collections {
+		names = append(names, string(indexID))
+	}
+	sort.Strings(names)
+
+	ordered := make([]pkgx.IndexID, 0, len(names))
+	visited := make(map[pkgx.IndexID]bool, len(names))
+	var visit func(indexID pkgx.IndexID)
+	visit = func(indexID pkgx.IndexID) {
+		schema, ok := b.collections[indexID]
+		if visited[indexID] || !ok {
+			return
+		}
+		visited[indexID] = true
+		if schema != nil {
+			for _, field := range schema.Fields {
+				if field.Reference == nil {
+					continue
+				}
+				if referenced, _, ok := strings.Cut(*field.Reference, "."); ok {
+					visit(pkgx.IndexID(referenced))
+				}
+			}
+		}
+		ordered = append(ordered, indexID)
+	}
+	for _, name := range names {
+		visit(pkgx.IndexID(name))
+	}
+	return ordered
+}
@@ -0,0 +1,51 @@
+package typesenseapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+type latencyBudgetContextKey struct{}
+
+// WithLatencyBudget sets the search cutoff of the searches of the returned context, typesense returns the hits found
+// until the budget is spent and the result is marked as partial, see pkg.SearchResult.Partial
+func WithLatencyBudget(ctx context.Context, budget time.Duration) context.Context {
+	return context.WithValue(ctx, latencyBudgetContextKey{}, budget)
+}
+
+// LatencyBudget returns the latency budget of the searches of the context, 0 if none is set
+func LatencyBudget(ctx context.Context) time.Duration {
+	budget, _ := ctx.Value(latencyBudgetContextKey{}).(time.Duration)
+	return budget
+}
+
+// withLatencyBudget sets the search cutoff of the parameters to the latency budget of the context or the default
+// budget, an explicit search_cutoff_ms of the parameters is kept
+func (b *BaseAPI[indexDocument, returnType]) withLatencyBudget(
+	ctx context.Context,
+	parameters *api.SearchCollectionParams,
+) *api.SearchCollectionParams {
+	return withSearchCutoff(parameters, b.latencyBudget(ctx))
+}
+
+// latencyBudget returns the latency budget of the context or the default budget
+func (b *BaseAPI[indexDocument, returnType]) latencyBudget(ctx context.Context) time.Duration {
+	if budget := LatencyBudget(ctx); budget > 0 {
+		return budget
+	}
+	return b.opts.latencyBudget
+}
+
+// withSearchCutoff sets the search cutoff of the parameters to the given budget, an explicit search_cutoff_ms of
+// the parameters is kept
+func withSearchCutoff(parameters *api.SearchCollectionParams, budget time.Duration) *api.SearchCollectionParams {
+	if budget <= 0 || parameters.SearchCutoffMs != nil {
+		return parameters
+	}
+	params := *parameters
+	params.SearchCutoffMs = pointer.Int(max(int(budget.Milliseconds()), 1))
+	return &params
+}
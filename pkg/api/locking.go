@@ -0,0 +1,161 @@
+package typesenseapi
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+const (
+	// locksCollectionName is the registry collection holding one document per held lock,
+	// creating a document with an existing ID fails, so only one replica holds a lock at a time
+	locksCollectionName = "typesense-locks"
+	initializeLockName  = "initialize"
+
+	defaultLockTTL     = time.Minute
+	defaultLockTimeout = 2 * time.Minute
+	lockRetryInterval  = 500 * time.Millisecond
+)
+
+// ErrLockTimeout is returned if a lock could not be acquired within the lock timeout
+var ErrLockTimeout = errors.New("timeout acquiring lock")
+
+type lock struct {
+	ID        string `json:"id"`
+	Owner     string `json:"owner"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// Lock acquires the cluster wide lock with the given name, see pkgx.Locker
+func (b *BaseAPI[indexDocument, returnType]) Lock(ctx context.Context, name string) (func(), error) {
+	return b.acquireLock(ctx, name)
+}
+
+// acquireLock acquires the cluster wide lock with the given name and returns the function releasing it.
+// Locks expire after the lock TTL, so a crashed replica doesn't block the others forever, held locks are extended
+// until they are released.
+func (b *BaseAPI[indexDocument, returnType]) acquireLock(ctx context.Context, name string) (func(), error) {
+	l := pkgx.Logger(ctx, b.l)
+	if err := b.createRegistryCollection(ctx, locksCollectionName, []api.Field{
+		{Name: "owner", Type: "string"},
+		{Name: "expires_at", Type: "int64"},
+	}); err != nil {
+		return nil, err
+	}
+
+	owner := lockOwner()
+	ctx, cancel := context.WithTimeout(ctx, b.opts.lockTimeout)
+	defer cancel()
+	for {
+		expiresAt := b.opts.clock.Now().Add(b.opts.lockTTL).Unix()
+		_, err := b.client.Collection(locksCollectionName).Documents().Create(ctx, &lock{
+			ID:        name,
+			Owner:     owner,
+			ExpiresAt: expiresAt,
+		}, &api.DocumentIndexParameters{})
+		if err == nil {
+			l.Debug("acquired lock", zap.String("lock", name), zap.String("owner", owner))
+			renewalCtx, stopRenewal := context.WithCancel(context.WithoutCancel(ctx))
+			go b.renewLock(renewalCtx, name, owner)
+			return func() {
+				stopRenewal()
+				b.deleteLock(context.WithoutCancel(ctx), name, pkgx.NewFilterBuilder().Eq("owner", owner))
+			}, nil
+		}
+		if !isConflict(err) {
+			l.Error("failed to acquire lock", zap.String("lock", name), zap.Error(err))
+			return nil, err
+		}
+
+		// Break locks of crashed replicas, unless the lock has been extended or taken over since it was read
+		if held, err := b.client.Collection(locksCollectionName).Document(name).Retrieve(ctx); err == nil {
+			heldOwner, _ := held["owner"].(string)
+			if heldExpiresAt, ok := held["expires_at"].(float64); ok && int64(heldExpiresAt) < b.opts.clock.Now().Unix() {
+				l.Warn("breaking expired lock", zap.String("lock", name), zap.String("owner", heldOwner))
+				b.deleteLock(ctx, name, pkgx.NewFilterBuilder().Eq("owner", heldOwner).Eq("expires_at", int64(heldExpiresAt)))
+				continue
+			}
+		}
+
+		l.Info("waiting for lock", zap.String("lock", name))
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w %s: %w", ErrLockTimeout, name, ctx.Err())
+		case <-time.After(lockRetryInterval):
+		}
+	}
+}
+
+// renewLock extends the expiry of the held lock every third of the lock TTL until the context is cancelled
+// or the lock has been lost, e.g. because it was broken while the replica was unresponsive
+func (b *BaseAPI[indexDocument, returnType]) renewLock(ctx context.Context, name, owner string) {
+	l := pkgx.Logger(ctx, b.l)
+	ticker := time.NewTicker(max(b.opts.lockTTL/3, time.Second))
+	defer ticker.Stop()
+	filterBy := pkgx.NewFilterBuilder().Eq("id", name).Eq("owner", owner).Build()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		updated, err := b.client.Collection(locksCollectionName).Documents().Update(ctx, map[string]any{
+			"expires_at": b.opts.clock.Now().Add(b.opts.lockTTL).Unix(),
+		}, &api.UpdateDocumentsParams{FilterBy: pointer.String(filterBy)})
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			l.Warn("failed to extend lock", zap.String("lock", name), zap.Error(err))
+		case updated == 0:
+			l.Warn("lost lock", zap.String("lock", name), zap.String("owner", owner))
+			return
+		}
+	}
+}
+
+// deleteLock deletes the lock document if it matches the filter, e.g. the owner, so a lock is never deleted
+// after another replica took it over
+func (b *BaseAPI[indexDocument, returnType]) deleteLock(ctx context.Context, name string, filter *pkgx.FilterBuilder) {
+	filterBy := pkgx.NewFilterBuilder().Eq("id", name).And(filter).Build()
+	if _, err := b.client.Collection(locksCollectionName).Documents().Delete(ctx, &api.DeleteDocumentsParams{
+		FilterBy: pointer.String(filterBy),
+	}); err != nil && !isNotFound(err) {
+		pkgx.Logger(ctx, b.l).Warn("failed to release lock", zap.String("lock", name), zap.Error(err))
+	}
+}
+
+// createRegistryCollection creates a registry collection unless it exists, tolerating concurrent creation
+func (b *BaseAPI[indexDocument, returnType]) createRegistryCollection(ctx context.Context, name string, fields []api.Field) error {
+	existingCollections, err := b.fetchExistingCollections(ctx)
+	if err != nil {
+		return err
+	}
+	if existingCollections[name] {
+		return nil
+	}
+	if _, err := b.client.Collections().Create(ctx, &api.CollectionSchema{
+		Name:   name,
+		Fields: fields,
+	}); err != nil && !isConflict(err) {
+		pkgx.Logger(ctx, b.l).Error("failed to create registry collection", zap.String("collection", name), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// lockOwner identifies the replica and acquisition holding a lock, the random suffix keeps concurrent
+// acquisitions of the same process from releasing or extending each other's locks
+func lockOwner() string {
+	hostname, _ := os.Hostname()
+	return hostname + "/" + strconv.Itoa(os.Getpid()) + "/" + rand.Text()
+}
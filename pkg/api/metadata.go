@@ -0,0 +1,206 @@
+package typesenseapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// metadataCollectionName is the registry collection holding one metadata document per created collection.
+// The typesense client does not support collection metadata yet, so it is stored alongside instead.
+const metadataCollectionName = "typesense-revisions"
+
+// metadataPageSize is the maximum page size of typesense
+const metadataPageSize = 250
+
+// ErrNoActiveRevision is returned by Stats if the alias of the index does not point to a revision
+var ErrNoActiveRevision = errors.New("no active revision")
+
+// CollectionMetadata describes the build that creates the collections
+type CollectionMetadata struct {
+	Source  string `json:"source,omitempty"`
+	GitSHA  string `json:"git_sha,omitempty"`
+	Creator string `json:"creator,omitempty"`
+}
+
+// RevisionInfo describes a single collection created for an index
+type RevisionInfo struct {
+	CollectionMetadata
+	ID         string          `json:"id"`
+	IndexID    pkgx.IndexID    `json:"index"`
+	RevisionID pkgx.RevisionID `json:"revision"`
+	SchemaHash string          `json:"schema_hash,omitempty"`
+	CreatedAt  int64           `json:"created_at"`
+	Documents  int64           `json:"-"`
+	Active     bool            `json:"-"`
+}
+
+// ListRevisions returns all collections of the given index, latest first, including the metadata
+// written when they were created and whether the alias currently points to them
+func (b *BaseAPI[indexDocument, returnType]) ListRevisions(ctx context.Context, indexID pkgx.IndexID) ([]RevisionInfo, error) {
+	l := pkgx.Logger(ctx, b.l)
+	collections, err := b.client.Collections().Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve collections", zap.Error(err))
+		return nil, err
+	}
+
+	activeCollection := ""
+	if alias, err := b.client.Alias(string(indexID)).Retrieve(ctx); err == nil {
+		activeCollection = alias.CollectionName
+	}
+
+	metadata := b.fetchCollectionMetadata(ctx, indexID)
+
+	var revisions []RevisionInfo
+	for _, col := range collections {
+		revisionID := extractRevisionID(col.Name, string(indexID))
+		if revisionID == "" {
+			continue
+		}
+		revision, ok := metadata[col.Name]
+		if !ok {
+			revision = RevisionInfo{
+				ID:         col.Name,
+				IndexID:    indexID,
+				RevisionID: revisionID,
+			}
+			if col.CreatedAt != nil {
+				revision.CreatedAt = *col.CreatedAt
+			}
+		}
+		if col.NumDocuments != nil {
+			revision.Documents = *col.NumDocuments
+		}
+		revision.Active = col.Name == activeCollection
+		revisions = append(revisions, revision)
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].RevisionID > revisions[j].RevisionID
+	})
+	return revisions, nil
+}
+
+// Stats returns the active revision of the given index, including its metadata and number of documents
+func (b *BaseAPI[indexDocument, returnType]) Stats(ctx context.Context, indexID pkgx.IndexID) (RevisionInfo, error) {
+	revisions, err := b.ListRevisions(ctx, indexID)
+	if err != nil {
+		return RevisionInfo{}, err
+	}
+	for _, revision := range revisions {
+		if revision.Active {
+			return revision, nil
+		}
+	}
+	return RevisionInfo{}, fmt.Errorf("%w: %s", ErrNoActiveRevision, indexID)
+}
+
+// writeCollectionMetadata stores the configured metadata for a newly created collection
+func (b *BaseAPI[indexDocument, returnType]) writeCollectionMetadata(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	revisionID pkgx.RevisionID,
+	schema *api.CollectionSchema,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	if b.opts.collectionMetadata == nil {
+		return nil
+	}
+
+	if err := b.createRegistryCollection(ctx, metadataCollectionName, []api.Field{
+		{Name: "index", Type: "string", Facet: pointer.True()},
+		{Name: "created_at", Type: "int64", Sort: pointer.True()},
+	}); err != nil {
+		return err
+	}
+
+	collectionName := formatCollectionName(indexID, revisionID)
+	_, err := b.client.Collection(metadataCollectionName).Documents().Upsert(ctx, &RevisionInfo{
+		CollectionMetadata: *b.opts.collectionMetadata,
+		ID:                 collectionName,
+		IndexID:            indexID,
+		RevisionID:         revisionID,
+		SchemaHash:         schemaHash(schema),
+		CreatedAt:          b.opts.clock.Now().Unix(),
+	}, &api.DocumentIndexParameters{})
+	if err != nil {
+		l.Error("failed to write collection metadata", zap.String("collection", collectionName), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// deleteCollectionMetadata removes the metadata of a deleted collection
+func (b *BaseAPI[indexDocument, returnType]) deleteCollectionMetadata(ctx context.Context, collectionName string) {
+	l := pkgx.Logger(ctx, b.l)
+	if b.opts.collectionMetadata == nil {
+		return
+	}
+	if _, err := b.client.Collection(metadataCollectionName).Document(collectionName).Delete(ctx); err != nil {
+		l.Debug("no collection metadata to delete", zap.String("collection", collectionName), zap.Error(err))
+	}
+}
+
+// fetchCollectionMetadata returns the stored metadata of all collections of the given index by collection name
+func (b *BaseAPI[indexDocument, returnType]) fetchCollectionMetadata(ctx context.Context, indexID pkgx.IndexID) map[string]RevisionInfo {
+	l := pkgx.Logger(ctx, b.l)
+	metadata := map[string]RevisionInfo{}
+
+	for page := 1; ; page++ {
+		response, err := b.client.Collection(metadataCollectionName).Documents().Search(ctx, &api.SearchCollectionParams{
+			Q:        pointer.String("*"),
+			FilterBy: pointer.String(pkgx.NewFilterBuilder().Eq("index", string(indexID)).Build()),
+			SortBy:   pointer.String("created_at:desc"),
+			Page:     pointer.Int(page),
+			PerPage:  pointer.Int(metadataPageSize),
+		})
+		if err != nil {
+			l.Debug("no collection metadata available", zap.String("index", string(indexID)), zap.Error(err))
+			return metadata
+		}
+		if response.Hits == nil || len(*response.Hits) == 0 {
+			return metadata
+		}
+
+		for _, hit := range *response.Hits {
+			if hit.Document == nil {
+				continue
+			}
+			data, err := json.Marshal(*hit.Document)
+			if err != nil {
+				continue
+			}
+			var revision RevisionInfo
+			if err := json.Unmarshal(data, &revision); err != nil {
+				l.Warn("invalid collection metadata", zap.String("index", string(indexID)), zap.Error(err))
+				continue
+			}
+			metadata[revision.ID] = revision
+		}
+		if response.Found == nil || page*metadataPageSize >= *response.Found {
+			return metadata
+		}
+	}
+}
+
+// schemaHash returns a stable hash of the schema, ignoring the collection name
+func schemaHash(schema *api.CollectionSchema) string {
+	s := *schema
+	s.Name = ""
+	data, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
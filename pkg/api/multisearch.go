@@ -0,0 +1,115 @@
+package typesenseapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// MultiSearch performs the searches on their indices in a single multi_search round trip, e.g. to query the www and
+// digital dimensions at once. Redirects, defaults, curation and enforced filters apply as for ExpertSearchResult.
+// Failing searches are logged and missing in the results, an error is only returned if all searches fail.
+func (b *BaseAPI[indexDocument, returnType]) MultiSearch(
+	ctx context.Context,
+	requests []pkgx.IndexedSearchRequest,
+) (map[pkgx.IndexID]*pkgx.SearchResult[returnType], error) {
+	l := pkgx.Logger(ctx, b.l)
+	results := make(map[pkgx.IndexID]*pkgx.SearchResult[returnType], len(requests))
+	var errs []error
+
+	searches := make([]*preparedSearch, 0, len(requests))
+	searchesParameter := api.MultiSearchSearchesParameter{}
+	for _, request := range requests {
+		if _, ok := results[request.IndexID]; ok {
+			return nil, fmt.Errorf("index %s is searched twice", request.IndexID)
+		}
+		results[request.IndexID] = nil
+
+		search, err := b.prepareSearch(ctx, request.IndexID, request.Parameters)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("index %s: %w", request.IndexID, err))
+			continue
+		}
+		if search.redirect != "" {
+			results[request.IndexID] = &pkgx.SearchResult[returnType]{Redirect: search.redirect}
+			continue
+		}
+		parameters, err := multiSearchParameters(search.collectionName, search.searchParameters)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("index %s: %w", request.IndexID, err))
+			continue
+		}
+		searches = append(searches, search)
+		searchesParameter.Searches = append(searchesParameter.Searches, parameters)
+	}
+
+	if len(searches) > 0 {
+		searchStart := time.Now()
+		response, err := b.client.MultiSearch.Perform(ctx, &api.MultiSearchParams{}, searchesParameter)
+		if err != nil {
+			l.Error("failed to perform multi search", zap.Int("searches", len(searches)), zap.Error(err))
+			return nil, err
+		}
+		duration := time.Since(searchStart)
+		if len(response.Results) != len(searches) {
+			return nil, fmt.Errorf("multi search returned %d results for %d searches", len(response.Results), len(searches))
+		}
+		for i, search := range searches {
+			item := response.Results[i]
+			if item.Error != nil {
+				l.Error("failed to perform search", zap.String("index", search.collectionName), zap.String("error", *item.Error))
+				errs = append(errs, fmt.Errorf("index %s: %s", search.indexID, *item.Error))
+				continue
+			}
+			results[search.indexID] = searchResult(ctx, b, search, multiSearchResult(item), duration, b.documentConverter)
+		}
+	}
+
+	for indexID, result := range results {
+		if result == nil {
+			delete(results, indexID)
+		}
+	}
+	if len(requests) > 0 && len(results) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return results, nil
+}
+
+// multiSearchParameters converts the parameters of a search on the collection to the parameters of a multi search
+func multiSearchParameters(collectionName string, parameters *api.SearchCollectionParams) (api.MultiSearchCollectionParameters, error) {
+	var multiSearchParameters api.MultiSearchCollectionParameters
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return multiSearchParameters, err
+	}
+	if err := json.Unmarshal(data, &multiSearchParameters); err != nil {
+		return multiSearchParameters, err
+	}
+	multiSearchParameters.Collection = pointer.String(collectionName)
+	return multiSearchParameters, nil
+}
+
+// multiSearchResult converts the result of a single search of a multi search
+func multiSearchResult(item api.MultiSearchResultItem) *api.SearchResult {
+	return &api.SearchResult{
+		Conversation:  item.Conversation,
+		FacetCounts:   item.FacetCounts,
+		Found:         item.Found,
+		FoundDocs:     item.FoundDocs,
+		GroupedHits:   item.GroupedHits,
+		Hits:          item.Hits,
+		OutOf:         item.OutOf,
+		Page:          item.Page,
+		RequestParams: item.RequestParams,
+		SearchCutoff:  item.SearchCutoff,
+		SearchTimeMs:  item.SearchTimeMs,
+	}
+}
@@ -0,0 +1,384 @@
+package typesenseapi
+
+import (
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// defaultRetentionCount keeps the served and the previous collection of every index
+const defaultRetentionCount = 2
+
+// defaultDeletionCap refuses to delete more than a fifth of the documents of a collection in one refresh
+const defaultDeletionCap = 0.2
+
+// Option configures optional behavior of the BaseAPI
+type Option func(o *options)
+
+type options struct {
+	searchRecorder pkgx.SearchRecorder
+	fallbacks      []Fallback
+
+	spellingSuggester pkgx.SpellingSuggester
+	spellingThreshold int
+
+	fieldRules map[pkgx.IndexID][]FieldRule
+
+	collectionMetadata *CollectionMetadata
+
+	clock pkgx.Clock
+
+	idFields    map[pkgx.IndexID]string
+	idExtractor IDExtractor
+
+	scoreNormalization ScoreNormalization
+
+	maxHits int
+
+	searchDefaults map[pkgx.IndexID]SearchDefaults
+
+	accessFilterProvider pkgx.AccessFilterProvider
+
+	weightField string
+
+	searchCurator pkgx.SearchCurator
+
+	canary bool
+
+	projectionProfiles map[pkgx.IndexID]map[string][]string
+
+	upsertDeduplication bool
+
+	documentVersioning bool
+
+	hitMetadata HitMetadataFunc
+
+	documentSerializer DocumentSerializer
+	hitTransformer     HitTransformer
+
+	maxImportPayloadSize int
+
+	pruneProtection time.Duration
+
+	deletionCap float64
+
+	retentionCount       int
+	indexRetentionCounts map[pkgx.IndexID]int
+
+	lockTTL     time.Duration
+	lockTimeout time.Duration
+
+	latencyBudget time.Duration
+
+	indexSlots map[pkgx.IndexID][]string
+
+	deprecatedFields map[pkgx.IndexID]map[string]int
+	indexPresets     map[pkgx.IndexID]*api.PresetUpsertSchema
+	fieldHydrator    FieldHydrator
+	searchOverrides  map[pkgx.IndexID]map[string]*api.SearchOverrideSchema
+	onAliasSwitched  AliasSwitchFunc
+	stopwords        map[string]*api.StopwordsSetUpsertSchema
+	fieldOverrides   map[pkgx.IndexID]map[string]map[string]any
+	schemaClient     api.ClientWithResponsesInterface
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		clock:          pkgx.SystemClock,
+		lockTTL:        defaultLockTTL,
+		lockTimeout:    defaultLockTimeout,
+		retentionCount: defaultRetentionCount,
+		deletionCap:    defaultDeletionCap,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithSearchRecorder reports every executed search query and its number of hits to the given recorder
+func WithSearchRecorder(recorder pkgx.SearchRecorder) Option {
+	return func(o *options) {
+		o.searchRecorder = recorder
+	}
+}
+
+// WithFallbacks configures the fallback chain that is executed in order when a search returns zero hits,
+// every fallback relaxes the parameters of the previous one, e.g. widened typos keep relaxed filters
+func WithFallbacks(fallbacks ...Fallback) Option {
+	return func(o *options) {
+		o.fallbacks = fallbacks
+	}
+}
+
+// WithSpellingSuggestions adds "did you mean" suggestions to search results with at most threshold hits
+func WithSpellingSuggestions(suggester pkgx.SpellingSuggester, threshold int) Option {
+	return func(o *options) {
+		o.spellingSuggester = suggester
+		o.spellingThreshold = threshold
+	}
+}
+
+// WithFieldRules configures per index defaulting and coercion rules applied to documents before import
+func WithFieldRules(rules map[pkgx.IndexID][]FieldRule) Option {
+	return func(o *options) {
+		o.fieldRules = rules
+	}
+}
+
+// WithCollectionMetadata records the given build metadata for every created collection, see ListRevisions
+func WithCollectionMetadata(metadata CollectionMetadata) Option {
+	return func(o *options) {
+		o.collectionMetadata = &metadata
+	}
+}
+
+// WithClock replaces the system clock used for revision IDs and timestamps, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithIDFields configures the field holding the document ID per index, e.g. "sku", defaults to "id"
+func WithIDFields(fields map[pkgx.IndexID]string) Option {
+	return func(o *options) {
+		o.idFields = fields
+	}
+}
+
+// WithIDExtractor extracts the document ID of search hits, it takes precedence over WithIDFields
+func WithIDExtractor(extractor IDExtractor) Option {
+	return func(o *options) {
+		o.idExtractor = extractor
+	}
+}
+
+// WithScoreNormalization fills Score.Normalized so scores of different indices and presets can be compared
+func WithScoreNormalization(normalization ScoreNormalization) Option {
+	return func(o *options) {
+		o.scoreNormalization = normalization
+	}
+}
+
+// WithMaxHits rejects searches for pages beyond the given number of hits with ErrPageOutOfRange
+// and limits the pagination to reachable pages
+func WithMaxHits(maxHits int) Option {
+	return func(o *options) {
+		o.maxHits = maxHits
+	}
+}
+
+// WithSearchDefaults configures default search settings per index that are merged into every search
+func WithSearchDefaults(defaults map[pkgx.IndexID]SearchDefaults) Option {
+	return func(o *options) {
+		o.searchDefaults = defaults
+	}
+}
+
+// WithAccessFilterProvider combines the filter of the given provider with every search,
+// searches fail if the provider returns an error
+func WithAccessFilterProvider(provider pkgx.AccessFilterProvider) Option {
+	return func(o *options) {
+		o.accessFilterProvider = provider
+	}
+}
+
+// WithWeightTiebreaker adds "<field>:desc" as last sort criterion of simple searches on indices whose schema
+// contains the given numeric field, e.g. typesenseschema.WeightField
+func WithWeightTiebreaker(field string) Option {
+	return func(o *options) {
+		o.weightField = field
+	}
+}
+
+// WithSearchCurator applies the given curator to every search after the search defaults, e.g. typesensecuration.Engine.
+// Curators implementing pkgx.SearchRedirector resolve redirects before searching,
+// curators with a Reload(ctx) error method are reloaded during Initialize.
+func WithSearchCurator(curator pkgx.SearchCurator) Option {
+	return func(o *options) {
+		o.searchCurator = curator
+	}
+}
+
+// WithCanaryAliases maintains a "<index>-canary" alias per index, see DeployCanary and WithCanary
+func WithCanaryAliases() Option {
+	return func(o *options) {
+		o.canary = true
+	}
+}
+
+// WithProjectionProfiles configures named field selections per index that searches select with
+// SearchParameters.Profile, e.g. "teaser" → title, url, image. An empty field list returns all fields.
+func WithProjectionProfiles(profiles map[pkgx.IndexID]map[string][]string) Option {
+	return func(o *options) {
+		o.projectionProfiles = profiles
+	}
+}
+
+// WithUpsertDeduplication stores a content hash with every document and skips upserting documents that are unchanged
+// in the revision's collection, making re-runs of a partially completed revision idempotent and fast
+func WithUpsertDeduplication() Option {
+	return func(o *options) {
+		o.upsertDeduplication = true
+	}
+}
+
+// WithMaxImportPayloadSize splits the documents of UpsertDocuments into import requests of at most maxBytes of JSONL,
+// e.g. to stay below the body size limit of a proxy in front of typesense when documents are large
+func WithMaxImportPayloadSize(maxBytes int) Option {
+	return func(o *options) {
+		o.maxImportPayloadSize = maxBytes
+	}
+}
+
+// WithPruneProtection keeps collections younger than the window when old revisions are pruned after a commit,
+// e.g. while caches still serve results of a just replaced revision
+func WithPruneProtection(window time.Duration) Option {
+	return func(o *options) {
+		o.pruneProtection = window
+	}
+}
+
+// WithInitializeLock configures the cluster wide lock serializing Initialize across replicas: locks of crashed
+// replicas expire after ttl and Initialize fails if the lock can't be acquired within timeout
+func WithInitializeLock(ttl, timeout time.Duration) Option {
+	return func(o *options) {
+		o.lockTTL = ttl
+		o.lockTimeout = timeout
+	}
+}
+
+// WithDocumentVersioning stamps every imported document with the import time and revision,
+// they are reported as the Version of the hit scores, e.g. to trace which indexing run produced a hit
+func WithDocumentVersioning() Option {
+	return func(o *options) {
+		o.documentVersioning = true
+	}
+}
+
+// WithHitMetadata attaches the metadata returned by the function to the hits of every search result,
+// see pkgx.SearchResult.Metadata
+func WithHitMetadata(fn HitMetadataFunc) Option {
+	return func(o *options) {
+		o.hitMetadata = fn
+	}
+}
+
+// WithDocumentSerializer converts the documents with the given serializer before they are imported, field rules are
+// applied to the serialized fields. The schema compatibility of the document type is not checked on upsert.
+func WithDocumentSerializer(serializer DocumentSerializer) Option {
+	return func(o *options) {
+		o.documentSerializer = serializer
+	}
+}
+
+// WithHitTransformer modifies the raw documents of search hits before they are decoded and converted,
+// hits failing the transformation are reported as conversion errors
+func WithHitTransformer(transformer HitTransformer) Option {
+	return func(o *options) {
+		o.hitTransformer = transformer
+	}
+}
+
+// WithRetentionCount sets the number of collections kept per index when old collections are pruned after a commit,
+// including the served one, e.g. to keep more revisions for rollbacks. The default keeps the latest two.
+func WithRetentionCount(count int) Option {
+	return func(o *options) {
+		o.retentionCount = count
+	}
+}
+
+// WithIndexRetentionCounts overrides the retention count of individual indices, see WithRetentionCount
+func WithIndexRetentionCounts(counts map[pkgx.IndexID]int) Option {
+	return func(o *options) {
+		o.indexRetentionCounts = counts
+	}
+}
+
+// WithDeletionCap aborts in-place refreshes and scope replacements before deleting vanished documents if more than
+// the given share of the existing documents vanished, e.g. 0.1 for 10%, to protect against provider outages.
+// It defaults to 0.2, 0 disables the cap.
+func WithDeletionCap(maxRatio float64) Option {
+	return func(o *options) {
+		o.deletionCap = maxRatio
+	}
+}
+
+// WithSearchLatencyBudget sets the default latency budget of searches, contexts with WithLatencyBudget override it
+func WithSearchLatencyBudget(budget time.Duration) Option {
+	return func(o *options) {
+		o.latencyBudget = budget
+	}
+}
+
+// WithIndexSlots configures the slots of the indices, e.g. {"www-de": {"a", "b"}}, whose "<index>@<slot>" aliases
+// serve experiment collections, see WithSlot, AssignSlot and PromoteSlot
+func WithIndexSlots(slots map[pkgx.IndexID][]string) Option {
+	return func(o *options) {
+		o.indexSlots = slots
+	}
+}
+
+// WithDeprecatedFields marks schema fields as deprecated by index, the values are the number of revisions the field
+// is kept in new collections before it is dropped from their schema. Searches using them are logged.
+func WithDeprecatedFields(fields map[pkgx.IndexID]map[string]int) Option {
+	return func(o *options) {
+		o.deprecatedFields = fields
+	}
+}
+
+// WithIndexPresets configures a search preset per index, e.g. with its own query_by fields and weights. Initialize
+// upserts them as IndexPresetName and simple searches without a PresetName use the preset of their index instead of
+// the "default" preset.
+func WithIndexPresets(presets map[pkgx.IndexID]*api.PresetUpsertSchema) Option {
+	return func(o *options) {
+		o.indexPresets = presets
+	}
+}
+
+// WithFieldHydrator fetches the values of non-stored fields from their source and adds them to the hit documents
+// before they are converted, so large text fields are searchable without bloating every search response
+func WithFieldHydrator(hydrator FieldHydrator) Option {
+	return func(o *options) {
+		o.fieldHydrator = hydrator
+	}
+}
+
+// WithSearchOverrides configures the typesense curation overrides of the indices by override ID, e.g. pinned and
+// hidden hits or metadata for queries. Initialize upserts them into the served collections and CommitRevision into
+// the collections of the new revision before the aliases are moved, so they survive revision swaps.
+func WithSearchOverrides(overrides map[pkgx.IndexID]map[string]*api.SearchOverrideSchema) Option {
+	return func(o *options) {
+		o.searchOverrides = overrides
+	}
+}
+
+// WithOnAliasSwitched registers a callback invoked after CommitRevision, RollbackRevision, RepointAllAliases or
+// PromoteSlot moved the alias of an index, including aliases restored after a failed rollback
+func WithOnAliasSwitched(callback AliasSwitchFunc) Option {
+	return func(o *options) {
+		o.onAliasSwitched = callback
+	}
+}
+
+// WithStopwords configures stopwords sets by name, e.g. {"de": {Locale: "de", Stopwords: []string{"der", "die"}}},
+// Initialize upserts them and searches reference them with pkg.SearchParameters.Stopwords or in presets
+func WithStopwords(sets map[string]*api.StopwordsSetUpsertSchema) Option {
+	return func(o *options) {
+		o.stopwords = sets
+	}
+}
+
+// WithFieldOverrides merges raw properties into the fields of the collections created for the indices, for field
+// parameters the typesense client does not model, e.g. typesenseschema.HNSWParams. These collections are created
+// with the given generated client, e.g. api.NewClientWithResponses(server, api.WithRequestEditorFn(...)).
+func WithFieldOverrides(client api.ClientWithResponsesInterface, overrides map[pkgx.IndexID]map[string]map[string]any) Option {
+	return func(o *options) {
+		o.schemaClient = client
+		o.fieldOverrides = overrides
+	}
+}
@@ -0,0 +1,109 @@
+package typesenseapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// ErrInvalidOverride is returned for simple searches with overrides of unknown search parameters or invalid values
+var ErrInvalidOverride = errors.New("invalid search parameter override")
+
+// searchParameterNames are the JSON names of the typesense search parameters
+var searchParameterNames = func() map[string]bool {
+	names := map[string]bool{}
+	t := reflect.TypeFor[api.SearchCollectionParams]()
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}()
+
+// applyOverrides merges the overrides of a simple search into its parameters. Precedence from low to high:
+// the preset, the overrides, the fields of pkg.SearchParameters and Modify. A filter_by override is combined
+// with the filter of the preset and the FilterBy of the search instead of replacing them.
+func (b *BaseAPI[indexDocument, returnType]) applyOverrides(
+	overrides map[string]any,
+	parameters *api.SearchCollectionParams,
+) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		if !searchParameterNames[name] {
+			return fmt.Errorf("%w: unknown search parameter %q", ErrInvalidOverride, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return err
+	}
+	values := map[string]any{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	filterBy := parameters.FilterBy
+	for _, name := range names {
+		if name == "filter_by" {
+			continue
+		}
+		if _, explicit := values[name]; !explicit {
+			values[name] = overrides[name]
+		}
+	}
+	if data, err = json.Marshal(values); err != nil {
+		return err
+	}
+	merged := api.SearchCollectionParams{}
+	if err := json.Unmarshal(data, &merged); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOverride, err)
+	}
+
+	if override, ok := overrides["filter_by"]; ok {
+		filter, ok := override.(string)
+		if !ok {
+			return fmt.Errorf("%w: filter_by must be a string", ErrInvalidOverride)
+		}
+		if presetFilter := b.presetFilter(merged.Preset); presetFilter != "" {
+			filter = combineFilters(presetFilter, pointer.String(filter))
+		}
+		merged.FilterBy = pointer.String(combineFilters(filter, filterBy))
+	}
+	*parameters = merged
+	return nil
+}
+
+// presetFilter returns the filter_by of the configured preset, "" if it has none or is unknown
+func (b *BaseAPI[indexDocument, returnType]) presetFilter(name *string) string {
+	if name == nil {
+		return ""
+	}
+	preset, ok := b.presets[*name]
+	if !ok || preset == nil {
+		return ""
+	}
+	raw, err := preset.Value.MarshalJSON()
+	if err != nil {
+		return ""
+	}
+	var value struct {
+		FilterBy string `json:"filter_by"`
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return ""
+	}
+	return value.FilterBy
+}
@@ -0,0 +1,117 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// pinsCollectionName is the registry collection holding one document per pinned index,
+// so all instances sharing the cluster respect the pin
+const pinsCollectionName = "typesense-pins"
+
+// ErrRevisionPinned is returned if the alias of an index can't be moved because it is pinned,
+// CommitRevision returns a *pkgx.PinnedError matching it instead
+var ErrRevisionPinned = pkgx.ErrPinned
+
+// PinnedRevision describes the revision an index is pinned to
+type PinnedRevision struct {
+	IndexID    pkgx.IndexID    `json:"id"`
+	Collection string          `json:"collection"`
+	RevisionID pkgx.RevisionID `json:"revision"`
+	PinnedAt   int64           `json:"pinned_at"`
+}
+
+// PinRevision pins the index to the currently served revision. While pinned, neither Initialize nor CommitRevision
+// move the alias and the pinned collection is not pruned, e.g. as an emergency brake during incidents.
+func (b *BaseAPI[indexDocument, returnType]) PinRevision(ctx context.Context, indexID pkgx.IndexID) (*PinnedRevision, error) {
+	l := pkgx.Logger(ctx, b.l)
+	alias, err := b.client.Alias(string(indexID)).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve alias", zap.String("alias", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+
+	if err := b.createRegistryCollection(ctx, pinsCollectionName, []api.Field{
+		{Name: "collection", Type: "string"},
+	}); err != nil {
+		return nil, err
+	}
+
+	pin := &PinnedRevision{
+		IndexID:    indexID,
+		Collection: alias.CollectionName,
+		RevisionID: extractRevisionID(alias.CollectionName, string(indexID)),
+		PinnedAt:   b.opts.clock.Now().Unix(),
+	}
+	if _, err := b.client.Collection(pinsCollectionName).Documents().Upsert(ctx, pin, &api.DocumentIndexParameters{}); err != nil {
+		l.Error("failed to pin revision", zap.String("index", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+	l.Warn("pinned revision", zap.String("index", string(indexID)), zap.String("collection", pin.Collection))
+	return pin, nil
+}
+
+// UnpinRevision releases the pin of the index, the next commit moves the alias again
+func (b *BaseAPI[indexDocument, returnType]) UnpinRevision(ctx context.Context, indexID pkgx.IndexID) error {
+	l := pkgx.Logger(ctx, b.l)
+	if _, err := b.client.Collection(pinsCollectionName).Document(string(indexID)).Delete(ctx); err != nil && !isNotFound(err) {
+		l.Error("failed to unpin revision", zap.String("index", string(indexID)), zap.Error(err))
+		return err
+	}
+	l.Info("unpinned revision", zap.String("index", string(indexID)))
+	return nil
+}
+
+// PinnedRevision returns the pin of the index or nil if it is not pinned
+func (b *BaseAPI[indexDocument, returnType]) PinnedRevision(ctx context.Context, indexID pkgx.IndexID) (*PinnedRevision, error) {
+	l := pkgx.Logger(ctx, b.l)
+	document, err := b.client.Collection(pinsCollectionName).Document(string(indexID)).Retrieve(ctx)
+	if isNotFound(err) {
+		return nil, nil //nolint:nilnil // not pinned
+	}
+	if err != nil {
+		l.Error("failed to retrieve pinned revision", zap.String("index", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+
+	pin := &PinnedRevision{IndexID: indexID}
+	if collection, ok := document["collection"].(string); ok {
+		pin.Collection = collection
+		pin.RevisionID = extractRevisionID(collection, string(indexID))
+	}
+	if pinnedAt, ok := document["pinned_at"].(float64); ok {
+		pin.PinnedAt = int64(pinnedAt)
+	}
+	return pin, nil
+}
+
+// checkPinned returns ErrRevisionPinned if the index is pinned, pins that can't be read are treated as pinned
+func (b *BaseAPI[indexDocument, returnType]) checkPinned(ctx context.Context, indexID pkgx.IndexID) error {
+	pin, err := b.PinnedRevision(ctx, indexID)
+	if err != nil {
+		return err
+	}
+	if pin != nil {
+		return fmt.Errorf("%w: %s is pinned to %s", ErrRevisionPinned, indexID, pin.Collection)
+	}
+	return nil
+}
+
+// isNotFound checks if the typesense request failed with 404
+func isNotFound(err error) bool {
+	var httpErr *typesense.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == http.StatusNotFound
+}
+
+// isConflict checks if the typesense request failed with 409, e.g. because the resource already exists
+func isConflict(err error) bool {
+	var httpErr *typesense.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == http.StatusConflict
+}
@@ -0,0 +1,171 @@
+package typesenseapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	schemax "github.com/foomo/typesense/pkg/schema"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// preflightHealthTimeout limits the health check of the cluster
+const preflightHealthTimeout = 5 * time.Second
+
+// Preflight validates the configuration against the cluster without modifying it, e.g. before the service reports
+// ready or in a deployment pipeline. It checks that the cluster is reachable, the api key may read collections,
+// aliases and presets, the schemas are valid and compatible with the document type, the presets only query
+// existing fields and every alias, including canary aliases, points to an existing collection. All problems are returned joined,
+// the findings of Lint are logged as warnings.
+func (b *BaseAPI[indexDocument, returnType]) Preflight(ctx context.Context) error {
+	l := pkgx.Logger(ctx, b.l)
+	var errs []error
+
+	// Reachable nodes
+	healthy, err := b.client.Health(ctx, preflightHealthTimeout)
+	if err != nil {
+		return fmt.Errorf("typesense is not reachable: %w", err)
+	}
+	if !healthy {
+		errs = append(errs, errors.New("typesense reports unhealthy nodes"))
+	}
+
+	// Schemas
+	indexIDs := make([]string, 0, len(b.collections))
+	for indexID := range b.collections {
+		indexIDs = append(indexIDs, string(indexID))
+	}
+	sort.Strings(indexIDs)
+	fields := map[string]bool{}
+	for _, id := range indexIDs {
+		schema := b.collections[pkgx.IndexID(id)]
+		if schema == nil {
+			errs = append(errs, fmt.Errorf("index %s: missing schema", id))
+			continue
+		}
+		if err := schemax.Validate(schema); err != nil {
+			errs = append(errs, fmt.Errorf("index %s: invalid schema: %w", id, err))
+		}
+		for _, field := range schema.Fields {
+			fields[field.Name] = true
+		}
+	}
+	if err := b.CheckSchemaCompatibility(); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Presets
+	presetNames := make([]string, 0, len(b.presets))
+	for name := range b.presets {
+		presetNames = append(presetNames, name)
+	}
+	sort.Strings(presetNames)
+	for _, name := range presetNames {
+		errs = append(errs, validatePreset(name, b.presets[name], fields)...)
+	}
+
+	// Likely mistakes are reported without failing the preflight
+	for _, finding := range b.Lint() {
+		l.Warn("lint finding", zap.String("rule", finding.Rule), zap.String("finding", finding.String()))
+	}
+
+	// API key permissions and alias consistency
+	collections, err := b.client.Collections().Retrieve(ctx)
+	if err != nil {
+		errs = append(errs, permissionError("list collections", err))
+	}
+	aliases, err := b.client.Aliases().Retrieve(ctx)
+	if err != nil {
+		errs = append(errs, permissionError("list aliases", err))
+	}
+	if _, err := b.client.Presets().Retrieve(ctx); err != nil {
+		errs = append(errs, permissionError("list presets", err))
+	}
+	if collections != nil && aliases != nil {
+		existing := make(map[string]bool, len(collections))
+		for _, collection := range collections {
+			existing[collection.Name] = true
+		}
+		served := make(map[string]string, len(aliases))
+		for _, alias := range aliases {
+			if alias.Name != nil {
+				served[*alias.Name] = alias.CollectionName
+			}
+		}
+		for _, id := range indexIDs {
+			collectionName, ok := served[id]
+			switch {
+			case !ok:
+				errs = append(errs, fmt.Errorf("index %s: alias is missing, Initialize bootstraps it", id))
+			case !existing[collectionName]:
+				errs = append(errs, fmt.Errorf("index %s: alias points to missing collection %s", id, collectionName))
+			case extractRevisionID(collectionName, id) == "":
+				errs = append(errs, fmt.Errorf("index %s: alias points to unmanaged collection %s", id, collectionName))
+			}
+			if !b.opts.canary {
+				continue
+			}
+			canaryID := string(CanaryIndexID(pkgx.IndexID(id)))
+			if collectionName, ok := served[canaryID]; !ok {
+				errs = append(errs, fmt.Errorf("index %s: canary alias %s is missing, Initialize bootstraps it", id, canaryID))
+			} else if !existing[collectionName] {
+				errs = append(errs, fmt.Errorf("index %s: canary alias points to missing collection %s", id, collectionName))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		l.Error("preflight failed", zap.Int("problems", len(errs)), zap.Error(err))
+		return err
+	}
+	l.Info("preflight succeeded", zap.Strings("indices", indexIDs))
+	return nil
+}
+
+// Lint checks the configured schemas and presets for common mistakes, see typesenseschema.Lint
+func (b *BaseAPI[indexDocument, returnType]) Lint() []schemax.LintFinding {
+	schemas := make(map[string]*api.CollectionSchema, len(b.collections))
+	for indexID, schema := range b.collections {
+		schemas[string(indexID)] = schema
+	}
+	return schemax.Lint(schemas, b.presets)
+}
+
+// validatePreset checks that the preset has search parameters and only queries existing fields
+func validatePreset(name string, preset any, fields map[string]bool) []error {
+	raw, err := json.Marshal(preset)
+	if err != nil {
+		return []error{fmt.Errorf("preset %s: %w", name, err)}
+	}
+	var value struct {
+		Value map[string]any `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &value); err != nil || len(value.Value) == 0 {
+		return []error{fmt.Errorf("preset %s: value must be an object of search parameters", name)}
+	}
+	queryBy, _ := value.Value["query_by"].(string)
+	var errs []error
+	for _, field := range strings.Split(queryBy, ",") {
+		if field = strings.TrimSpace(field); field != "" && !fields[field] {
+			errs = append(errs, fmt.Errorf("preset %s: query_by field %q does not exist in any schema", name, field))
+		}
+	}
+	return errs
+}
+
+// permissionError describes a failed read request, pointing out missing api key permissions
+func permissionError(action string, err error) error {
+	var httpErr *typesense.HTTPError
+	if errors.As(err, &httpErr) && (httpErr.Status == http.StatusUnauthorized || httpErr.Status == http.StatusForbidden) {
+		return fmt.Errorf("api key is not allowed to %s: %w", action, err)
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
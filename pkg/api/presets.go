@@ -0,0 +1,69 @@
+package typesenseapi
+
+import (
+	"encoding/json"
+	"errors"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// IndexPresetName returns the name under which the preset of the index is upserted, see WithIndexPresets
+func IndexPresetName(indexID pkgx.IndexID) string {
+	return defaultSearchPresetName + "-" + string(indexID)
+}
+
+// SetFacetSampling adds facet sampling to the given search preset: when a query matches more than threshold documents,
+// facet counts are estimated from percent of the hits instead of being counted exactly.
+// The typesense client has no search parameters for facet sampling, so it can only be configured through a preset.
+func SetFacetSampling(preset *api.PresetUpsertSchema, percent, threshold int) error {
+	if preset == nil {
+		return errors.New("preset cannot be nil")
+	}
+	if percent < 1 || percent > 100 {
+		return errors.New("facet sample percent must be between 1 and 100")
+	}
+
+	value := map[string]any{}
+	if raw, err := preset.Value.MarshalJSON(); err == nil && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+	}
+	value["facet_sample_percent"] = percent
+	value["facet_sample_threshold"] = threshold
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return preset.Value.UnmarshalJSON(raw)
+}
+
+// searchPreset returns the preset used by searches on the index without a preset name
+func (b *BaseAPI[indexDocument, returnType]) searchPreset(indexID pkgx.IndexID) string {
+	if preset, ok := b.opts.indexPresets[indexID]; ok && preset != nil {
+		return IndexPresetName(indexID)
+	}
+	return defaultSearchPresetName
+}
+
+// mergeIndexPresets adds the presets of the indices to the named presets without modifying them
+func mergeIndexPresets(
+	presets map[string]*api.PresetUpsertSchema,
+	indexPresets map[pkgx.IndexID]*api.PresetUpsertSchema,
+) map[string]*api.PresetUpsertSchema {
+	if len(indexPresets) == 0 {
+		return presets
+	}
+	merged := make(map[string]*api.PresetUpsertSchema, len(presets)+len(indexPresets))
+	for name, preset := range presets {
+		merged[name] = preset
+	}
+	for indexID, preset := range indexPresets {
+		if preset != nil {
+			merged[IndexPresetName(indexID)] = preset
+		}
+	}
+	return merged
+}
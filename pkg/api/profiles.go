@@ -0,0 +1,63 @@
+package typesenseapi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// ErrUnknownProfile is returned for searches selecting a projection profile that is not configured for the index
+var ErrUnknownProfile = errors.New("unknown projection profile")
+
+// simpleSearchParams builds the search parameters of a simple search on the index, searches without a preset name
+// use the preset of the index
+func (b *BaseAPI[indexDocument, returnType]) simpleSearchParams(
+	indexID pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*api.SearchCollectionParams, error) {
+	searchParams := buildSearchParams(parameters)
+	if parameters.PresetName == "" {
+		searchParams.Preset = pointer.String(b.searchPreset(indexID))
+	}
+	if err := b.applyOverrides(parameters.Overrides, searchParams); err != nil {
+		return nil, err
+	}
+	if err := b.normalizeSortBy(indexID, searchParams); err != nil {
+		return nil, err
+	}
+	b.addWeightTiebreaker(indexID, searchParams)
+	if err := b.applyProfile(indexID, parameters.Profile, searchParams); err != nil {
+		return nil, err
+	}
+	return searchParams, nil
+}
+
+// applyProfile restricts the returned fields to the fields of the projection profile,
+// the ID field is always included so hits can be identified, included reference fields are kept
+func (b *BaseAPI[indexDocument, returnType]) applyProfile(
+	indexID pkgx.IndexID,
+	profile string,
+	parameters *api.SearchCollectionParams,
+) error {
+	if profile == "" {
+		return nil
+	}
+	fields, ok := b.opts.projectionProfiles[indexID][profile]
+	if !ok {
+		return fmt.Errorf("%w %q for index %s", ErrUnknownProfile, profile, indexID)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	idField := b.idField(indexID)
+	if !slices.Contains(fields, idField) {
+		fields = append(slices.Clone(fields), idField)
+	}
+	addIncludeFields(parameters, fields...)
+	return nil
+}
@@ -0,0 +1,99 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// ErrDeletionCapExceeded is returned if more documents vanished than the deletion cap allows, see WithDeletionCap
+var ErrDeletionCapExceeded = errors.New("deletion cap exceeded")
+
+// RefreshInPlace updates the collection currently served by the alias of the index without a new revision:
+// the documents are imported with the emplace action and all other documents of the collection are deleted.
+// Searches may see a mix of old and new documents during the refresh, so it is meant for small indices only.
+// Pinned indices are not modified and nothing is deleted if no documents are given or the deletion cap is exceeded.
+func (b *BaseAPI[indexDocument, returnType]) RefreshInPlace(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	if err := b.checkPinned(ctx, indexID); err != nil {
+		return err
+	}
+	if len(documents) == 0 {
+		l.Warn("no documents provided for in-place refresh, keeping collection", zap.String("index", string(indexID)))
+		return nil
+	}
+
+	alias, err := b.client.Alias(string(indexID)).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve alias", zap.String("alias", string(indexID)), zap.Error(err))
+		return err
+	}
+	revisionID := extractRevisionID(alias.CollectionName, string(indexID))
+	if revisionID == "" {
+		return fmt.Errorf("alias %s points to unknown collection %s", indexID, alias.CollectionName)
+	}
+
+	provided, err := b.providedDocumentIDs(indexID, documents)
+	if err != nil {
+		return err
+	}
+	if err := b.importRevisionDocuments(ctx, revisionID, indexID, documents, "emplace"); err != nil {
+		return err
+	}
+
+	// Delete the documents that are no longer provided
+	existing, err := b.exportDocumentIDs(ctx, alias.CollectionName, "")
+	if err != nil {
+		return err
+	}
+	stale, err := b.vanishedDocumentIDs(ctx, alias.CollectionName, existing, provided)
+	if err != nil {
+		return err
+	}
+	if err := b.deleteDocumentIDs(ctx, alias.CollectionName, stale); err != nil {
+		return err
+	}
+
+	l.Info("refreshed collection in place",
+		zap.String("collection", alias.CollectionName),
+		zap.Int("emplaced_documents", len(documents)),
+		zap.Int("deleted_documents", len(stale)),
+	)
+	return nil
+}
+
+// vanishedDocumentIDs returns the existing IDs that are not provided anymore, it fails with ErrDeletionCapExceeded
+// if their share of the existing documents exceeds the deletion cap, e.g. because the provider returned only a part
+// of the documents during an outage
+func (b *BaseAPI[indexDocument, returnType]) vanishedDocumentIDs(
+	ctx context.Context,
+	collectionName string,
+	existing []string,
+	provided map[string]bool,
+) ([]string, error) {
+	var vanished []string
+	for _, id := range existing {
+		if !provided[id] {
+			vanished = append(vanished, id)
+		}
+	}
+	if maxRatio := b.opts.deletionCap; maxRatio > 0 && len(vanished) > 0 {
+		if ratio := float64(len(vanished)) / float64(len(existing)); ratio > maxRatio {
+			pkgx.Logger(ctx, b.l).Error("refusing to delete vanished documents",
+				zap.String("collection", collectionName),
+				zap.Int("vanished_documents", len(vanished)),
+				zap.Int("existing_documents", len(existing)),
+				zap.Float64("max_ratio", maxRatio),
+			)
+			return nil, fmt.Errorf("%w: %d of %d documents of %s vanished", ErrDeletionCapExceeded, len(vanished), len(existing), collectionName)
+		}
+	}
+	return vanished, nil
+}
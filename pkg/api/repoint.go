@@ -0,0 +1,117 @@
+package typesenseapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// RepointAllAliases forcibly points the aliases of all configured indices at the collections of the given revision,
+// e.g. to recover from manual interventions on the cluster. Nothing is changed unless the collections of all indices
+// exist. Pins are ignored, canary aliases are realigned.
+func (b *BaseAPI[indexDocument, returnType]) RepointAllAliases(ctx context.Context, revisionID pkgx.RevisionID) error {
+	return b.pointAliases(ctx, revisionID, true)
+}
+
+// RollbackRevision points the aliases of all configured indices back at the collections of a previous revision,
+// e.g. one kept by WithRetentionCount. Nothing is changed unless the collections of all indices exist and no index
+// is pinned. If moving an alias fails, the aliases moved before are restored. Canary aliases are realigned.
+func (b *BaseAPI[indexDocument, returnType]) RollbackRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	return b.pointAliases(ctx, revisionID, false)
+}
+
+// pointAliases points the aliases of all indices at the collections of the revision
+func (b *BaseAPI[indexDocument, returnType]) pointAliases(ctx context.Context, revisionID pkgx.RevisionID, ignorePins bool) error {
+	l := pkgx.Logger(ctx, b.l)
+	existingCollections, err := b.fetchExistingCollections(ctx)
+	if err != nil {
+		return err
+	}
+
+	indexIDs := make([]string, 0, len(b.collections))
+	var missing []string
+	for indexID := range b.collections {
+		indexIDs = append(indexIDs, string(indexID))
+		if collectionName := formatCollectionName(indexID, revisionID); !existingCollections[collectionName] {
+			missing = append(missing, collectionName)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		l.Error("cannot repoint aliases, collections are missing",
+			zap.String("revision", string(revisionID)),
+			zap.Strings("missing", missing),
+		)
+		return fmt.Errorf("missing collections of revision %s: %s", revisionID, strings.Join(missing, ", "))
+	}
+	sort.Strings(indexIDs)
+
+	// Remember the served collections to restore them if an alias can't be moved
+	previous := make(map[pkgx.IndexID]string, len(indexIDs))
+	for _, id := range indexIDs {
+		indexID := pkgx.IndexID(id)
+		if !ignorePins {
+			if err := b.checkPinned(ctx, indexID); err != nil {
+				return err
+			}
+		} else if pin, err := b.PinnedRevision(ctx, indexID); err == nil && pin != nil {
+			l.Warn("repointing pinned index", zap.String("index", id), zap.String("pinned", pin.Collection))
+		}
+		if alias, err := b.client.Alias(id).Retrieve(ctx); err == nil {
+			previous[indexID] = alias.CollectionName
+		}
+	}
+
+	var moved []pkgx.IndexID
+	for _, id := range indexIDs {
+		indexID := pkgx.IndexID(id)
+		collectionName := formatCollectionName(indexID, revisionID)
+		if err := b.ensureAliasMapping(ctx, indexID, collectionName); err != nil {
+			b.restoreAliases(ctx, revisionID, moved, previous)
+			return err
+		}
+		moved = append(moved, indexID)
+		if b.opts.canary {
+			if err := b.ensureAliasMapping(ctx, CanaryIndexID(indexID), collectionName); err != nil {
+				b.restoreAliases(ctx, revisionID, moved, previous)
+				return err
+			}
+		}
+		l.Warn("repointed alias", zap.String("alias", id), zap.String("collection", collectionName))
+		b.aliasSwitched(ctx, indexID, previous[indexID], collectionName)
+	}
+
+	b.revisionID = revisionID
+	return nil
+}
+
+// restoreAliases points the moved aliases back at their previous collections, failures are logged only
+func (b *BaseAPI[indexDocument, returnType]) restoreAliases(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	moved []pkgx.IndexID,
+	previous map[pkgx.IndexID]string,
+) {
+	l := pkgx.Logger(ctx, b.l)
+	for _, indexID := range moved {
+		collectionName, ok := previous[indexID]
+		if !ok {
+			continue
+		}
+		if err := b.ensureAliasMapping(ctx, indexID, collectionName); err != nil {
+			l.Error("failed to restore alias", zap.String("alias", string(indexID)), zap.Error(err))
+			continue
+		}
+		if b.opts.canary {
+			if err := b.ensureAliasMapping(ctx, CanaryIndexID(indexID), collectionName); err != nil {
+				l.Error("failed to restore canary alias", zap.String("alias", string(indexID)), zap.Error(err))
+			}
+		}
+		l.Warn("restored alias", zap.String("alias", string(indexID)), zap.String("collection", collectionName))
+		b.aliasSwitched(ctx, indexID, formatCollectionName(indexID, revisionID), collectionName)
+	}
+}
@@ -0,0 +1,86 @@
+package typesenseapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// SortRandom orders the results randomly, use it as SearchParameters.SortBy
+const SortRandom = "_rand()"
+
+// maxSampleSize is the maximum page size supported by typesense
+const maxSampleSize = 250
+
+// SortRandomSeed orders the results randomly but reproducible for the same seed
+func SortRandomSeed(seed int) string {
+	return "_rand(" + strconv.Itoa(seed) + ")"
+}
+
+// SampleDocuments returns up to n random documents of the given index, e.g. to spot-check the index quality after a run.
+// The sample is not recorded as a search.
+func (b *BaseAPI[indexDocument, returnType]) SampleDocuments(ctx context.Context, indexID pkgx.IndexID, n int) ([]returnType, error) {
+	results, _, err := b.sampleCollection(ctx, indexID, string(indexID), n)
+	return results, err
+}
+
+// SampleRevision returns up to n random documents of the collection of the given revision, e.g. to verify the
+// field mappings before it is committed. It fails if any of the sampled documents can't be converted.
+func (b *BaseAPI[indexDocument, returnType]) SampleRevision(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	n int,
+) ([]returnType, error) {
+	results, failed, err := b.sampleCollection(ctx, indexID, formatCollectionName(indexID, revisionID), n)
+	if err != nil {
+		return nil, err
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("failed to convert %d of %d sampled documents of index %s", failed, failed+len(results), indexID)
+	}
+	return results, nil
+}
+
+// sampleCollection returns up to n random converted documents of the collection of the index and the number of
+// documents that failed to convert
+func (b *BaseAPI[indexDocument, returnType]) sampleCollection(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	collectionName string,
+	n int,
+) ([]returnType, int, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if n < 1 {
+		return nil, 0, nil
+	}
+
+	searchResponse, err := b.client.Collection(collectionName).Documents().Search(ctx, &api.SearchCollectionParams{
+		Q:       pointer.String("*"),
+		SortBy:  pointer.String(SortRandom),
+		PerPage: pointer.Int(min(n, maxSampleSize)),
+	})
+	if err != nil {
+		l.Error("failed to sample documents", zap.String("index", collectionName), zap.Error(err))
+		return nil, 0, err
+	}
+	if searchResponse.Hits == nil {
+		return nil, 0, nil
+	}
+
+	failed := 0
+	results := make([]returnType, 0, len(*searchResponse.Hits))
+	for _, hit := range *searchResponse.Hits {
+		if convertedDoc, _, err := b.convertHit(indexID, collectionName, hit); err == nil {
+			results = append(results, convertedDoc)
+		} else {
+			failed++
+		}
+	}
+	return results, failed, nil
+}
@@ -0,0 +1,162 @@
+package typesenseapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// deleteBatchSize limits the number of IDs per delete request
+const deleteBatchSize = 250
+
+// ReplaceScope upserts the documents into the collection currently served by the alias of the index and deletes
+// the documents matching the filter of the scope that are not part of the given documents. Pinned indices are
+// not modified, nothing is deleted if the deletion cap is exceeded.
+func (b *BaseAPI[indexDocument, returnType]) ReplaceScope(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	scope pkgx.Scope,
+	documents []*indexDocument,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	if err := b.checkPinned(ctx, indexID); err != nil {
+		return err
+	}
+
+	alias, err := b.client.Alias(string(indexID)).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve alias", zap.String("alias", string(indexID)), zap.Error(err))
+		return err
+	}
+	revisionID := extractRevisionID(alias.CollectionName, string(indexID))
+	if revisionID == "" {
+		return fmt.Errorf("alias %s points to unknown collection %s", indexID, alias.CollectionName)
+	}
+
+	if err := b.UpsertDocuments(ctx, revisionID, indexID, documents); err != nil {
+		return err
+	}
+	if scope.FilterBy == "" {
+		return nil
+	}
+
+	// Delete the documents of the scope that are no longer provided
+	provided, err := b.providedDocumentIDs(indexID, documents)
+	if err != nil {
+		return err
+	}
+	existing, err := b.exportDocumentIDs(ctx, alias.CollectionName, scope.FilterBy)
+	if err != nil {
+		return err
+	}
+	stale, err := b.vanishedDocumentIDs(ctx, alias.CollectionName, existing, provided)
+	if err != nil {
+		return err
+	}
+	if err := b.deleteDocumentIDs(ctx, alias.CollectionName, stale); err != nil {
+		return err
+	}
+
+	l.Info("replaced scope",
+		zap.String("collection", alias.CollectionName),
+		zap.String("scope", scope.Selector),
+		zap.Int("upserted_documents", len(documents)),
+		zap.Int("deleted_documents", len(stale)),
+	)
+	return nil
+}
+
+// exportDocumentIDs returns the IDs of the documents of the collection matching the filter, all if it is empty
+func (b *BaseAPI[indexDocument, returnType]) exportDocumentIDs(ctx context.Context, collectionName, filterBy string) ([]string, error) {
+	l := pkgx.Logger(ctx, b.l)
+	params := &api.ExportDocumentsParams{
+		IncludeFields: pointer.String("id"),
+	}
+	if filterBy != "" {
+		params.FilterBy = pointer.String(filterBy)
+	}
+	body, err := b.client.Collection(collectionName).Documents().Export(ctx, params)
+	if err != nil {
+		l.Error("failed to export document ids", zap.String("collection", collectionName), zap.Error(err))
+		return nil, err
+	}
+	defer body.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		var document struct {
+			ID string `json:"id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &document); err != nil {
+			return nil, err
+		}
+		ids = append(ids, document.ID)
+	}
+	return ids, scanner.Err()
+}
+
+// deleteDocumentIDs deletes the documents with the given IDs in batches
+func (b *BaseAPI[indexDocument, returnType]) deleteDocumentIDs(ctx context.Context, collectionName string, ids []string) error {
+	l := pkgx.Logger(ctx, b.l)
+	for start := 0; start < len(ids); start += deleteBatchSize {
+		batch := ids[start:min(start+deleteBatchSize, len(ids))]
+		values := make([]any, len(batch))
+		for i, id := range batch {
+			values[i] = id
+		}
+		if _, err := b.client.Collection(collectionName).Documents().Delete(ctx, &api.DeleteDocumentsParams{
+			FilterBy: pointer.String(pkgx.NewFilterBuilder().In("id", values...).Build()),
+		}); err != nil {
+			l.Error("failed to delete documents", zap.String("collection", collectionName), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// providedDocumentIDs returns the typesense IDs of the documents as imported by importRevisionDocuments
+func (b *BaseAPI[indexDocument, returnType]) providedDocumentIDs(
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) (map[string]bool, error) {
+	ids := make(map[string]bool, len(documents))
+	for _, document := range documents {
+		if document == nil {
+			continue
+		}
+		var id string
+		if b.opts.documentSerializer != nil {
+			fields, err := b.opts.documentSerializer(indexID, document)
+			if err != nil {
+				// the document is skipped on import, so its ID is not provided
+				continue
+			}
+			id, _ = fields["id"].(string)
+		} else {
+			data, err := json.Marshal(document)
+			if err != nil {
+				return nil, err
+			}
+			var fields struct {
+				ID string `json:"id"`
+			}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, err
+			}
+			id = fields.ID
+		}
+		if id == "" {
+			return nil, errors.New("document without id in scope")
+		}
+		ids[id] = true
+	}
+	return ids, nil
+}
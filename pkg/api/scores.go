@@ -0,0 +1,51 @@
+package typesenseapi
+
+import (
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// ScoreNormalization defines how text match scores are scaled to 0–1
+type ScoreNormalization int
+
+const (
+	// NormalizeNone keeps Score.Normalized empty
+	NormalizeNone ScoreNormalization = iota
+	// NormalizeMaxScore divides every score by the best score of the response,
+	// responses without text match scores, e.g. wildcard queries, are normalized by rank
+	NormalizeMaxScore
+	// NormalizeRank ignores the scores and uses the position of the hit in the response
+	NormalizeRank
+)
+
+// normalizeScores sets Score.Normalized of the given scores which are expected in response order
+func normalizeScores(scores []pkgx.Score, normalization ScoreNormalization) {
+	if len(scores) == 0 {
+		return
+	}
+
+	maxScore := 0
+	for _, score := range scores {
+		maxScore = max(maxScore, score.Index)
+	}
+
+	switch normalization {
+	case NormalizeNone:
+		return
+	case NormalizeMaxScore:
+		if maxScore > 0 {
+			for i := range scores {
+				scores[i].Normalized = float64(scores[i].Index) / float64(maxScore)
+			}
+			return
+		}
+		normalizeRanks(scores)
+	case NormalizeRank:
+		normalizeRanks(scores)
+	}
+}
+
+func normalizeRanks(scores []pkgx.Score) {
+	for i := range scores {
+		scores[i].Normalized = 1 - float64(i)/float64(len(scores))
+	}
+}
@@ -0,0 +1,36 @@
+package typesenseapi
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// SimpleSearchAs performs the search like SimpleSearchResult but converts the hits with the given converter,
+// e.g. to teasers while the DocumentConverter of the API returns detail documents. Decorators of the API,
+// e.g. a cache, are not involved.
+func SimpleSearchAs[indexDocument any, returnType any, T any](
+	ctx context.Context,
+	b *BaseAPI[indexDocument, returnType],
+	indexID pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+	converter DocumentConverter[indexDocument, T],
+) (*pkgx.SearchResult[T], error) {
+	searchParams, err := b.simpleSearchParams(indexID, parameters)
+	if err != nil {
+		return nil, err
+	}
+	return expertSearchResult(ctx, b, indexID, searchParams, converter)
+}
+
+// ExpertSearchAs performs the search like ExpertSearchResult but converts the hits with the given converter
+func ExpertSearchAs[indexDocument any, returnType any, T any](
+	ctx context.Context,
+	b *BaseAPI[indexDocument, returnType],
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+	converter DocumentConverter[indexDocument, T],
+) (*pkgx.SearchResult[T], error) {
+	return expertSearchResult(ctx, b, indexID, parameters, converter)
+}
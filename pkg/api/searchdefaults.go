@@ -0,0 +1,185 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// maxSortCriteria is the maximum number of sort_by fields supported by typesense
+const maxSortCriteria = 3
+
+// ErrUnknownIndex is returned for searches of indices that are not configured, e.g. of the collections, canary or
+// slot aliases and registry collections behind the configured indices
+var ErrUnknownIndex = errors.New("unknown index")
+
+// SearchDefaults are applied to every search of an index, each default is only used if the search does not set it.
+// Filters that must not be bypassed by handlers or relaxed by fallbacks, e.g. "published:=true", belong into a
+// pkg.AccessFilterProvider instead of FilterBy.
+type SearchDefaults struct {
+	QueryBy  string
+	SortBy   string
+	FilterBy string
+	FacetBy  string
+}
+
+// applySearchDefaults returns a copy of the parameters with the defaults of the index merged in
+func (b *BaseAPI[indexDocument, returnType]) applySearchDefaults(
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) *api.SearchCollectionParams {
+	defaults, ok := b.opts.searchDefaults[indexID]
+	if !ok {
+		return parameters
+	}
+
+	params := *parameters
+	if params.QueryBy == nil && defaults.QueryBy != "" {
+		params.QueryBy = pointer.String(defaults.QueryBy)
+	}
+	if params.SortBy == nil && defaults.SortBy != "" {
+		params.SortBy = pointer.String(defaults.SortBy)
+	}
+	if params.FacetBy == nil && defaults.FacetBy != "" {
+		params.FacetBy = pointer.String(defaults.FacetBy)
+	}
+	if params.FilterBy == nil && defaults.FilterBy != "" {
+		params.FilterBy = pointer.String(defaults.FilterBy)
+	}
+	return &params
+}
+
+// curate applies the configured search curator to the parameters
+func (b *BaseAPI[indexDocument, returnType]) curate(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*api.SearchCollectionParams, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if b.opts.searchCurator == nil {
+		return parameters, nil
+	}
+	params, err := b.opts.searchCurator.Curate(ctx, indexID, parameters)
+	if err != nil {
+		l.Error("failed to curate search", zap.String("index", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+	return params, nil
+}
+
+// redirect resolves the redirect of the query if the configured search curator supports redirects
+func (b *BaseAPI[indexDocument, returnType]) redirect(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (string, bool) {
+	redirector, ok := b.opts.searchCurator.(pkgx.SearchRedirector)
+	if !ok || parameters.Q == nil {
+		return "", false
+	}
+	return redirector.Redirect(ctx, indexID, *parameters.Q)
+}
+
+// reloadCurator loads the current rules of the configured search curator if it supports reloading
+func (b *BaseAPI[indexDocument, returnType]) reloadCurator(ctx context.Context) error {
+	l := pkgx.Logger(ctx, b.l)
+	reloader, ok := b.opts.searchCurator.(interface {
+		Reload(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	if err := reloader.Reload(ctx); err != nil {
+		l.Error("failed to reload search curation rules", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// checkIndex returns ErrUnknownIndex if the index is not configured, searches must check it before the enforced
+// filter is resolved, so collections can't be searched past the access filter of their index
+func (b *BaseAPI[indexDocument, returnType]) checkIndex(indexID pkgx.IndexID) error {
+	if _, ok := b.collections[indexID]; !ok {
+		return fmt.Errorf("%w %s", ErrUnknownIndex, indexID)
+	}
+	return nil
+}
+
+// enforcedFilter returns the filter that has to be part of every search of the index,
+// the filter of the access filter provider
+func (b *BaseAPI[indexDocument, returnType]) enforcedFilter(ctx context.Context, indexID pkgx.IndexID) (string, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if b.opts.accessFilterProvider == nil {
+		return "", nil
+	}
+
+	accessFilter, err := b.opts.accessFilterProvider.AccessFilter(ctx, indexID)
+	if err != nil {
+		l.Error("failed to retrieve access filter", zap.String("index", string(indexID)), zap.Error(err))
+		return "", err
+	}
+	return accessFilter, nil
+}
+
+// withFilter returns a copy of the parameters with the enforced filter combined into the filter of the search
+func withFilter(parameters *api.SearchCollectionParams, filter string) *api.SearchCollectionParams {
+	if filter == "" {
+		return parameters
+	}
+	params := *parameters
+	params.FilterBy = pointer.String(combineFilters(filter, params.FilterBy))
+	return &params
+}
+
+// combineFilters joins the given filter with an optional additional filter
+func combineFilters(filter string, additional *string) string {
+	if additional == nil || *additional == "" {
+		return filter
+	}
+	return "(" + filter + ") && (" + *additional + ")"
+}
+
+// addWeightTiebreaker appends the weight field to the sort criteria if the index has one.
+// Without an explicit or default sort, results are sorted by text match first.
+func (b *BaseAPI[indexDocument, returnType]) addWeightTiebreaker(indexID pkgx.IndexID, parameters *api.SearchCollectionParams) {
+	if b.opts.weightField == "" || !b.hasField(indexID, b.opts.weightField) {
+		return
+	}
+
+	sortBy := "_text_match:desc"
+	if parameters.SortBy != nil && *parameters.SortBy != "" {
+		sortBy = *parameters.SortBy
+	} else if defaultSortBy := b.opts.searchDefaults[indexID].SortBy; defaultSortBy != "" {
+		sortBy = defaultSortBy
+	}
+	criteria := strings.Split(sortBy, ",")
+	if len(criteria) >= maxSortCriteria {
+		return
+	}
+	for _, criterion := range criteria {
+		if strings.HasPrefix(strings.TrimSpace(criterion), b.opts.weightField+":") {
+			return
+		}
+	}
+	parameters.SortBy = pointer.String(sortBy + "," + b.opts.weightField + ":desc")
+}
+
+// hasField checks if the schema of the index contains the given field
+func (b *BaseAPI[indexDocument, returnType]) hasField(indexID pkgx.IndexID, name string) bool {
+	schema, ok := b.collections[indexID]
+	if !ok {
+		return false
+	}
+	for _, field := range schema.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
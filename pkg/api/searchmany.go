@@ -0,0 +1,154 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// SearchMany runs the same search concurrently on all given indices and merges the hits by their normalized score.
+// Text match scores are not comparable across collections, so they are normalized per index, see WithScoreNormalization.
+// The searches are recorded and share the latency budget like single index searches.
+// Failing indices are logged and left out, an error is only returned if all searches fail.
+func (b *BaseAPI[indexDocument, returnType]) SearchMany(
+	ctx context.Context,
+	indices []pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*pkgx.MultiSearchResult[returnType], error) {
+	l := pkgx.Logger(ctx, b.l)
+	if parameters == nil {
+		l.Error("search parameters are nil")
+		return nil, errors.New("search parameters cannot be nil")
+	}
+
+	type indexResult struct {
+		hits    []pkgx.MultiSearchHit[returnType]
+		found   int
+		partial bool
+		err     error
+	}
+
+	searchParams := buildSearchParams(parameters)
+	results := make([]indexResult, len(indices))
+	var wg sync.WaitGroup
+	for i, indexID := range indices {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			params := *searchParams
+			if parameters.PresetName == "" {
+				params.Preset = pointer.String(b.searchPreset(indexID))
+			}
+			if err := b.applyProfile(indexID, parameters.Profile, &params); err != nil {
+				results[i] = indexResult{err: err}
+				return
+			}
+			hits, found, partial, err := b.searchIndex(ctx, indexID, &params)
+			results[i] = indexResult{hits: hits, found: found, partial: partial, err: err}
+		}()
+	}
+	wg.Wait()
+
+	result := &pkgx.MultiSearchResult[returnType]{
+		Totals: make(map[pkgx.IndexID]int, len(indices)),
+	}
+	var errs []error
+	for i, indexID := range indices {
+		if results[i].err != nil {
+			errs = append(errs, results[i].err)
+			continue
+		}
+		result.Hits = append(result.Hits, results[i].hits...)
+		result.Totals[indexID] = results[i].found
+		result.Total += results[i].found
+		if results[i].partial {
+			result.Partial = append(result.Partial, indexID)
+		}
+	}
+	if len(indices) > 0 && len(errs) == len(indices) {
+		return nil, errors.Join(errs...)
+	}
+
+	sort.SliceStable(result.Hits, func(i, j int) bool {
+		return result.Hits[i].NormalizedScore > result.Hits[j].NormalizedScore
+	})
+	return result, nil
+}
+
+// searchIndex searches a single index and returns its hits with normalized scores and whether they are partial
+func (b *BaseAPI[indexDocument, returnType]) searchIndex(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) ([]pkgx.MultiSearchHit[returnType], int, bool, error) {
+	l := pkgx.Logger(ctx, b.l)
+	if err := b.checkIndex(indexID); err != nil {
+		return nil, 0, false, err
+	}
+	collectionName := b.searchCollection(ctx, indexID)
+	filter, err := b.enforcedFilter(ctx, indexID)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	params, err := b.curate(ctx, indexID, b.applySearchDefaults(indexID, parameters))
+	if err != nil {
+		return nil, 0, false, err
+	}
+	b.logDeprecatedUsage(ctx, indexID, params)
+
+	searchParams := b.withLatencyBudget(ctx, withFilter(params, filter))
+	searchResponse, err := b.client.Collection(collectionName).Documents().Search(ctx, searchParams)
+	if err != nil {
+		l.Error("failed to perform search", zap.String("index", collectionName), zap.Error(err))
+		return nil, 0, false, err
+	}
+
+	found := 0
+	if searchResponse.Found != nil {
+		found = *searchResponse.Found
+	}
+	if b.opts.searchRecorder != nil && params.Q != nil {
+		b.opts.searchRecorder.RecordSearch(ctx, indexID, *params.Q, found)
+	}
+	partial := searchResponse.SearchCutoff != nil && *searchResponse.SearchCutoff
+	if partial {
+		l.Warn("search exceeded the latency budget, returning partial results", zap.String("index", collectionName))
+	}
+	if searchResponse.Hits == nil {
+		return nil, found, partial, nil
+	}
+
+	b.hydrateHits(ctx, indexID, *searchResponse.Hits)
+	hits := make([]pkgx.MultiSearchHit[returnType], 0, len(*searchResponse.Hits))
+	scores := make([]pkgx.Score, 0, len(*searchResponse.Hits))
+	for _, hit := range *searchResponse.Hits {
+		convertedDoc, score, err := b.convertHit(indexID, collectionName, hit)
+		if err != nil {
+			continue
+		}
+		hits = append(hits, pkgx.MultiSearchHit[returnType]{
+			IndexID:  indexID,
+			Document: convertedDoc,
+		})
+		scores = append(scores, score)
+	}
+
+	// merging requires comparable scores, so normalize even if it is not configured
+	normalization := b.opts.scoreNormalization
+	if normalization == NormalizeNone {
+		normalization = NormalizeMaxScore
+	}
+	normalizeScores(scores, normalization)
+	for i := range hits {
+		hits[i].Score = scores[i]
+		hits[i].NormalizedScore = scores[i].Normalized
+	}
+	return hits, found, partial, nil
+}
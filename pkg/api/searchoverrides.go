@@ -0,0 +1,33 @@
+package typesenseapi
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// syncSearchOverrides upserts the configured curation overrides of the index into the collection. Overrides are
+// stored per collection, so the collections of new revisions only hold the configured ones and overrides removed
+// from the configuration disappear with the next revision.
+func (b *BaseAPI[indexDocument, returnType]) syncSearchOverrides(ctx context.Context, indexID pkgx.IndexID, collectionName string) error {
+	l := pkgx.Logger(ctx, b.l)
+	overrides := b.opts.searchOverrides[indexID]
+	for id, override := range overrides {
+		if override == nil {
+			continue
+		}
+		if _, err := b.client.Collection(collectionName).Overrides().Upsert(ctx, id, override); err != nil {
+			l.Error("failed to upsert search override",
+				zap.String("collection", collectionName),
+				zap.String("override", id),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	if len(overrides) > 0 {
+		l.Info("synced search overrides", zap.String("collection", collectionName), zap.Int("count", len(overrides)))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package typesenseapi
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// slotSeparator separates the index and the slot in the name of a slot alias, e.g. "www-de@a"
+const slotSeparator = "@"
+
+type slotContextKey struct{}
+
+// SlotIndexID returns the name of the alias of the slot of the index, e.g. "www-de@a"
+func SlotIndexID(indexID pkgx.IndexID, slot string) pkgx.IndexID {
+	return indexID + slotSeparator + pkgx.IndexID(slot)
+}
+
+// WithSlot routes the searches of the returned context to the given slot of the indices, e.g. the experiment
+// bucket of the request. Indices without the slot configured with WithIndexSlots are searched as usual.
+func WithSlot(ctx context.Context, slot string) context.Context {
+	return context.WithValue(ctx, slotContextKey{}, slot)
+}
+
+// Slot returns the slot the searches of the context are routed to, "" if none is selected
+func Slot(ctx context.Context) string {
+	slot, _ := ctx.Value(slotContextKey{}).(string)
+	return slot
+}
+
+// AssignSlot points the slot alias of the index at the collection of the index built for the given revision, e.g.
+// by an offline job with a different embedding model that did not commit its revision. The main alias is not moved.
+func (b *BaseAPI[indexDocument, returnType]) AssignSlot(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	slot string,
+	revisionID pkgx.RevisionID,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	if !b.hasSlot(indexID, slot) {
+		return fmt.Errorf("slot %q is not configured for index %s", slot, indexID)
+	}
+	existingCollections, err := b.fetchExistingCollections(ctx)
+	if err != nil {
+		return err
+	}
+	collectionName := formatCollectionName(indexID, revisionID)
+	if !existingCollections[collectionName] {
+		return fmt.Errorf("missing collection %s of revision %s", collectionName, revisionID)
+	}
+	if err := b.ensureAliasMapping(ctx, SlotIndexID(indexID, slot), collectionName); err != nil {
+		return err
+	}
+	l.Info("assigned slot",
+		zap.String("alias", string(SlotIndexID(indexID, slot))),
+		zap.String("collection", collectionName),
+	)
+	return nil
+}
+
+// PromoteSlot points the main alias of the index, and its canary alias, at the collection served by the slot,
+// e.g. to roll out the winner of an experiment. Pinned indices are not promoted.
+func (b *BaseAPI[indexDocument, returnType]) PromoteSlot(ctx context.Context, indexID pkgx.IndexID, slot string) error {
+	l := pkgx.Logger(ctx, b.l)
+	if !b.hasSlot(indexID, slot) {
+		return fmt.Errorf("slot %q is not configured for index %s", slot, indexID)
+	}
+	if err := b.checkPinned(ctx, indexID); err != nil {
+		return err
+	}
+	alias, err := b.client.Alias(string(SlotIndexID(indexID, slot))).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve slot alias", zap.String("alias", string(SlotIndexID(indexID, slot))), zap.Error(err))
+		return err
+	}
+	oldCollectionName := b.previousCollection(ctx, indexID)
+	if err := b.ensureAliasMapping(ctx, indexID, alias.CollectionName); err != nil {
+		return err
+	}
+	b.aliasSwitched(ctx, indexID, oldCollectionName, alias.CollectionName)
+	if err := b.alignCanary(ctx, indexID); err != nil {
+		return err
+	}
+	l.Warn("promoted slot",
+		zap.String("alias", string(indexID)),
+		zap.String("slot", slot),
+		zap.String("collection", alias.CollectionName),
+	)
+	return nil
+}
+
+// hasSlot checks if the slot is configured for the index
+func (b *BaseAPI[indexDocument, returnType]) hasSlot(indexID pkgx.IndexID, slot string) bool {
+	return slot != "" && slices.Contains(b.opts.indexSlots[indexID], slot)
+}
+
+// slotCollections returns the collections served by the slot aliases of the index, they are not pruned
+func (b *BaseAPI[indexDocument, returnType]) slotCollections(ctx context.Context, indexID pkgx.IndexID) map[string]bool {
+	collections := map[string]bool{}
+	for _, slot := range b.opts.indexSlots[indexID] {
+		if alias, err := b.client.Alias(string(SlotIndexID(indexID, slot))).Retrieve(ctx); err == nil {
+			collections[alias.CollectionName] = true
+		}
+	}
+	return collections
+}
@@ -0,0 +1,113 @@
+package typesenseapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	schemax "github.com/foomo/typesense/pkg/schema"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// textMatchSort sorts hits by relevance, it is appended as tiebreaker to sorted searches with a query
+const textMatchSort = "_text_match:desc"
+
+// ErrInvalidSortBy is returned for simple searches sorting by unknown or unsortable fields
+var ErrInvalidSortBy = errors.New("invalid sort by")
+
+// normalizeSortBy validates the comma separated sort criteria of a simple search against the schema of the index,
+// adds the default ascending order to criteria without one and appends _text_match:desc as tiebreaker if the
+// search has a query. Special criteria like _text_match, _eval(…) or geo sorting are passed through.
+func (b *BaseAPI[indexDocument, returnType]) normalizeSortBy(indexID pkgx.IndexID, parameters *api.SearchCollectionParams) error {
+	if parameters.SortBy == nil || strings.TrimSpace(*parameters.SortBy) == "" {
+		return nil
+	}
+	var fields map[string]api.Field
+	if schema := b.collections[indexID]; schema != nil && len(schema.Fields) > 0 {
+		fields = make(map[string]api.Field, len(schema.Fields))
+		for _, field := range schema.Fields {
+			fields[field.Name] = field
+		}
+	}
+
+	var criteria []string
+	textMatch := false
+	for _, criterion := range splitSortBy(*parameters.SortBy) {
+		if criterion = strings.TrimSpace(criterion); criterion == "" {
+			continue
+		}
+		if base, _, ok := strings.Cut(criterion, "("); ok {
+			// geo sorting and _eval carry their own parameters
+			if _, known := fields[base]; fields != nil && !strings.HasPrefix(base, "_") && !known {
+				return fmt.Errorf("%w: unknown field %q for index %s", ErrInvalidSortBy, base, indexID)
+			}
+			criteria = append(criteria, criterion)
+			continue
+		}
+		name, order, ok := strings.Cut(criterion, ":")
+		if !ok {
+			order = "asc"
+		}
+		name, order = strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(order))
+		switch {
+		case order != "asc" && order != "desc":
+			return fmt.Errorf("%w: order of %q must be asc or desc", ErrInvalidSortBy, name)
+		case strings.HasPrefix(name, "_"):
+			textMatch = textMatch || name == "_text_match"
+		case fields != nil:
+			field, ok := fields[name]
+			if !ok {
+				return fmt.Errorf("%w: unknown field %q for index %s", ErrInvalidSortBy, name, indexID)
+			}
+			if !sortable(field) {
+				return fmt.Errorf("%w: field %q of index %s is not sortable", ErrInvalidSortBy, name, indexID)
+			}
+		}
+		criteria = append(criteria, name+":"+order)
+	}
+	if len(criteria) > maxSortCriteria {
+		return fmt.Errorf("%w: at most %d sort criteria are supported", ErrInvalidSortBy, maxSortCriteria)
+	}
+
+	query := parameters.Q != nil && *parameters.Q != "" && *parameters.Q != "*"
+	if query && !textMatch && len(criteria) > 0 && len(criteria) < maxSortCriteria {
+		criteria = append(criteria, textMatchSort)
+	}
+	parameters.SortBy = pointer.String(strings.Join(criteria, ","))
+	return nil
+}
+
+// splitSortBy splits the sort criteria at commas outside of parentheses
+func splitSortBy(sortBy string) []string {
+	var criteria []string
+	depth, start := 0, 0
+	for i, r := range sortBy {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				criteria = append(criteria, sortBy[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(criteria, sortBy[start:])
+}
+
+// sortable checks if typesense can sort by the field, numeric fields are sortable unless disabled
+func sortable(field api.Field) bool {
+	if field.Sort != nil {
+		return *field.Sort
+	}
+	switch field.Type {
+	case schemax.TypeInt32, schemax.TypeInt64, schemax.TypeFloat, schemax.TypeBool:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,42 @@
+package typesenseapi
+
+import (
+	"context"
+
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// SwapAlias points the alias at the collection and deletes the collection it pointed at before, e.g. for auxiliary
+// collections like vocabularies or query suggestions that are rebuilt as a whole. The collection is deleted instead
+// if the alias can't be moved.
+func SwapAlias(ctx context.Context, l *zap.Logger, client *typesense.Client, alias, collectionName string) error {
+	previous := ""
+	if current, err := client.Alias(alias).Retrieve(ctx); err == nil {
+		previous = current.CollectionName
+	}
+	if _, err := client.Aliases().Upsert(ctx, alias, &api.CollectionAliasSchema{
+		CollectionName: collectionName,
+	}); err != nil {
+		l.Error("failed to upsert alias", zap.String("alias", alias), zap.String("collection", collectionName), zap.Error(err))
+		if err := DeleteCollection(ctx, client, collectionName); err != nil {
+			l.Warn("failed to delete collection", zap.String("collection", collectionName), zap.Error(err))
+		}
+		return err
+	}
+	if previous != "" && previous != collectionName {
+		if err := DeleteCollection(ctx, client, previous); err != nil {
+			l.Warn("failed to delete collection", zap.String("collection", previous), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// DeleteCollection deletes the collection, collections that don't exist are ignored
+func DeleteCollection(ctx context.Context, client *typesense.Client, collectionName string) error {
+	if _, err := client.Collection(collectionName).Delete(ctx); err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package typesenseapi
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -13,7 +14,20 @@ import (
 	"go.uber.org/zap"
 )
 
-const defaultSearchPresetName = "default"
+const (
+	defaultSearchPresetName = "default"
+	defaultPerPage          = 10
+)
+
+// ErrPageOutOfRange is returned for pages beyond the configured max hits
+var ErrPageOutOfRange = errors.New("page out of range")
+
+var (
+	// ErrMissingDocument is reported for search hits without a document
+	ErrMissingDocument = errors.New("hit without document")
+	// ErrMissingDocumentID is reported for search hits whose document ID can't be resolved
+	ErrMissingDocumentID = errors.New("missing or invalid document id")
+)
 
 // buildSearchParams will return the search collection parameters
 func buildSearchParams(
@@ -37,6 +51,37 @@ func buildSearchParams(
 		searchParams.Q = pointer.String(params.Query)
 	}
 
+	if params.FilterBy != "" {
+		searchParams.FilterBy = pointer.String(params.FilterBy)
+	}
+
+	if params.SortBy != "" {
+		searchParams.SortBy = pointer.String(params.SortBy)
+	}
+
+	if params.GroupBy != "" {
+		searchParams.GroupBy = pointer.String(params.GroupBy)
+		if params.GroupLimit > 0 {
+			searchParams.GroupLimit = pointer.Int(params.GroupLimit)
+		}
+		searchParams.GroupMissingValues = params.GroupMissingValues
+	}
+
+	if params.FacetBy != "" {
+		searchParams.FacetBy = pointer.String(params.FacetBy)
+		if params.MaxFacetValues > 0 {
+			searchParams.MaxFacetValues = pointer.Int(params.MaxFacetValues)
+		}
+	}
+
+	if params.Stopwords != "" {
+		searchParams.Stopwords = pointer.String(params.Stopwords)
+	}
+
+	if len(params.Joins) > 0 {
+		applyJoins(params.Joins, searchParams)
+	}
+
 	if params.Modify != nil {
 		params.Modify(searchParams)
 	}
@@ -44,8 +89,20 @@ func buildSearchParams(
 	return searchParams
 }
 
+// searchPage returns the requested page and page size, falling back to the typesense defaults
+func searchPage(parameters *api.SearchCollectionParams) (int, int) {
+	page, perPage := 1, defaultPerPage
+	if parameters.Page != nil && *parameters.Page > 0 {
+		page = *parameters.Page
+	}
+	if parameters.PerPage != nil && *parameters.PerPage > 0 {
+		perPage = *parameters.PerPage
+	}
+	return page, perPage
+}
+
 func (b *BaseAPI[indexDocument, returnType]) generateRevisionID() pkgx.RevisionID {
-	return pkgx.RevisionID(time.Now().Format("2006-01-02-15-04")) // "YYYY-MM-DD-HH-MM"
+	return pkgx.RevisionID(b.opts.clock.Now().Format("2006-01-02-15-04")) // "YYYY-MM-DD-HH-MM"
 }
 
 func formatCollectionName(indexID pkgx.IndexID, revisionID pkgx.RevisionID) string {
@@ -69,11 +126,12 @@ func extractRevisionID(collectionName, name string) pkgx.RevisionID {
 
 // ensureAliasMapping ensures an alias correctly points to the specified collection.
 func (b *BaseAPI[indexDocument, returnType]) ensureAliasMapping(ctx context.Context, indexID pkgx.IndexID, collectionName string) error {
+	l := pkgx.Logger(ctx, b.l)
 	_, err := b.client.Aliases().Upsert(ctx, string(indexID), &api.CollectionAliasSchema{
 		CollectionName: collectionName,
 	})
 	if err != nil {
-		b.l.Error("failed to upsert alias",
+		l.Error("failed to upsert alias",
 			zap.String("alias", string(indexID)),
 			zap.String("collection", collectionName),
 			zap.Error(err),
@@ -83,16 +141,37 @@ func (b *BaseAPI[indexDocument, returnType]) ensureAliasMapping(ctx context.Cont
 }
 
 func (b *BaseAPI[indexDocument, returnType]) pruneOldCollections(ctx context.Context, alias, currentCollection string) error {
+	l := pkgx.Logger(ctx, b.l)
 	// Step 1: Retrieve all collections
 	collections, err := b.client.Collections().Retrieve(ctx)
 	if err != nil {
-		b.l.Error("failed to retrieve collections", zap.Error(err))
+		l.Error("failed to retrieve collections", zap.Error(err))
 		return err
 	}
 
+	pin, err := b.PinnedRevision(ctx, pkgx.IndexID(alias))
+	if err != nil {
+		return err
+	}
+	pinnedCollection := ""
+	if pin != nil {
+		pinnedCollection = pin.Collection
+	}
+
+	// Collections created within the protection window or served by slots are never deleted
+	protected := b.slotCollections(ctx, pkgx.IndexID(alias))
+	if window := b.opts.pruneProtection; window > 0 {
+		threshold := b.opts.clock.Now().Add(-window).Unix()
+		for _, col := range collections {
+			if col.CreatedAt != nil && *col.CreatedAt > threshold {
+				protected[col.Name] = true
+			}
+		}
+	}
+
 	var oldCollections []string
 	for _, col := range collections {
-		if strings.HasPrefix(col.Name, alias+"-") && col.Name != currentCollection {
+		if extractRevisionID(col.Name, alias) != "" && col.Name != currentCollection && col.Name != pinnedCollection {
 			oldCollections = append(oldCollections, col.Name)
 		}
 	}
@@ -102,15 +181,20 @@ func (b *BaseAPI[indexDocument, returnType]) pruneOldCollections(ctx context.Con
 		return oldCollections[i] > oldCollections[j] // Reverse order
 	})
 
-	// Step 3: Delete all but the latest two collections
-	if len(oldCollections) > 1 {
-		toDelete := oldCollections[1:] // Keep only the latest two
+	// Step 3: Delete all but the latest collections within the retention count, including the current one
+	if keep := b.retentionCount(pkgx.IndexID(alias)) - 1; len(oldCollections) > keep {
+		toDelete := oldCollections[keep:]
 		for _, col := range toDelete {
+			if protected[col] {
+				l.Info("keeping protected collection", zap.String("collection", col))
+				continue
+			}
 			_, err := b.client.Collection(col).Delete(ctx)
 			if err != nil {
-				b.l.Error("failed to delete collection", zap.String("collection", col), zap.Error(err))
+				l.Error("failed to delete collection", zap.String("collection", col), zap.Error(err))
 			} else {
-				b.l.Info("deleted old collection", zap.String("collection", col))
+				l.Info("deleted old collection", zap.String("collection", col))
+				b.deleteCollectionMetadata(ctx, col)
 			}
 		}
 	}
@@ -118,11 +202,20 @@ func (b *BaseAPI[indexDocument, returnType]) pruneOldCollections(ctx context.Con
 	return nil
 }
 
+// retentionCount returns the number of collections kept for the index
+func (b *BaseAPI[indexDocument, returnType]) retentionCount(indexID pkgx.IndexID) int {
+	if count, ok := b.opts.indexRetentionCounts[indexID]; ok {
+		return max(count, 1)
+	}
+	return max(b.opts.retentionCount, 1)
+}
+
 // fetchExistingCollections retrieves all existing collections and stores them in a map for quick lookup.
 func (b *BaseAPI[indexDocument, returnType]) fetchExistingCollections(ctx context.Context) (map[string]bool, error) {
+	l := pkgx.Logger(ctx, b.l)
 	collections, err := b.client.Collections().Retrieve(ctx)
 	if err != nil {
-		b.l.Error("failed to retrieve collections", zap.Error(err))
+		l.Error("failed to retrieve collections", zap.Error(err))
 		return nil, err
 	}
 
@@ -135,7 +228,13 @@ func (b *BaseAPI[indexDocument, returnType]) fetchExistingCollections(ctx contex
 }
 
 // createCollectionIfNotExists ensures that a collection exists before trying to use it.
-func (b *BaseAPI[indexDocument, returnType]) createCollectionIfNotExists(ctx context.Context, schema *api.CollectionSchema, collectionName string) error {
+func (b *BaseAPI[indexDocument, returnType]) createCollectionIfNotExists(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	schema *api.CollectionSchema,
+	collectionName string,
+) error {
+	l := pkgx.Logger(ctx, b.l)
 	// Check if collection already exists
 	existingCollections, err := b.fetchExistingCollections(ctx)
 	if err != nil {
@@ -143,18 +242,110 @@ func (b *BaseAPI[indexDocument, returnType]) createCollectionIfNotExists(ctx con
 	}
 
 	if existingCollections[collectionName] {
-		b.l.Info("collection already exists, skipping creation", zap.String("collection", collectionName))
+		l.Info("collection already exists, skipping creation", zap.String("collection", collectionName))
 		return nil
 	}
 
 	// Set the collection name and create it
 	schema.Name = collectionName
-	_, err = b.client.Collections().Create(ctx, schema)
+	if overrides, ok := b.opts.fieldOverrides[indexID]; ok && len(overrides) > 0 {
+		err = b.createOverriddenCollection(ctx, schema, overrides)
+	} else {
+		_, err = b.client.Collections().Create(ctx, schema)
+	}
+	if isConflict(err) {
+		l.Info("collection was created concurrently", zap.String("collection", collectionName))
+		return nil
+	}
 	if err != nil {
-		b.l.Error("failed to create collection", zap.String("collection", collectionName), zap.Error(err))
+		l.Error("failed to create collection", zap.String("collection", collectionName), zap.Error(err))
 		return err
 	}
 
-	b.l.Info("created new collection", zap.String("collection", collectionName))
+	l.Info("created new collection", zap.String("collection", collectionName))
 	return nil
 }
+
+// searchFallbacks executes the configured fallback chain of the prepared search, applying the fallbacks on top of
+// each other, and returns the first response with hits and the name of its fallback or the given response if none of
+// the fallbacks yields results. The fallback searches share the rest of the latency budget and are described for
+// debugging.
+func (b *BaseAPI[indexDocument, returnType]) searchFallbacks(
+	ctx context.Context,
+	search *preparedSearch,
+	searchResponse *api.SearchResult,
+) (*api.SearchResult, string, []pkgx.FallbackDebug) {
+	l := pkgx.Logger(ctx, b.l)
+	collectionName, parameters := search.collectionName, search.parameters
+	budget := b.latencyBudget(ctx)
+	debug := IsSearchDebug(ctx)
+	var fallbacks []pkgx.FallbackDebug
+	for _, fallback := range b.opts.fallbacks {
+		fallbackParams := applyFallback(fallback, parameters)
+		if fallbackParams == nil {
+			continue
+		}
+		parameters = fallbackParams
+
+		// the enforced filter is applied after the fallback, so it can't be relaxed
+		fallbackParams = withFilter(fallbackParams, search.filter)
+		if budget > 0 {
+			remaining := budget - time.Since(search.start)
+			if remaining <= 0 {
+				l.Warn("latency budget spent, skipping the remaining fallbacks",
+					zap.String("index", collectionName),
+					zap.String("fallback", fallback.Name),
+				)
+				break
+			}
+			fallbackParams = withSearchCutoff(fallbackParams, remaining)
+		}
+
+		fallbackStart := time.Now()
+		fallbackResponse, err := b.client.Collection(collectionName).Documents().Search(ctx, fallbackParams)
+		if err != nil {
+			l.Warn("failed to perform fallback search",
+				zap.String("index", collectionName),
+				zap.String("fallback", fallback.Name),
+				zap.Error(err),
+			)
+			continue
+		}
+		if debug {
+			fallbacks = append(fallbacks, pkgx.FallbackDebug{
+				Name:       fallback.Name,
+				Parameters: fallbackParams,
+				Duration:   time.Since(fallbackStart),
+				Found:      pointerValue(fallbackResponse.Found),
+			})
+		}
+
+		if fallbackResponse.Found != nil && *fallbackResponse.Found > 0 {
+			l.Info("zero result search recovered by fallback",
+				zap.String("index", collectionName),
+				zap.String("fallback", fallback.Name),
+				zap.Int("total_results", *fallbackResponse.Found),
+			)
+			return fallbackResponse, fallback.Name, fallbacks
+		}
+	}
+	return searchResponse, "", fallbacks
+}
+
+// suggestSpellings asks the configured spelling suggester for alternatives of the search query
+func (b *BaseAPI[indexDocument, returnType]) suggestSpellings(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) []string {
+	l := pkgx.Logger(ctx, b.l)
+	if parameters.Q == nil || *parameters.Q == "" || *parameters.Q == "*" {
+		return nil
+	}
+	suggestions, err := b.opts.spellingSuggester.Suggest(ctx, indexID, *parameters.Q)
+	if err != nil {
+		l.Warn("failed to retrieve spelling suggestions", zap.String("index", string(indexID)), zap.Error(err))
+		return nil
+	}
+	return suggestions
+}
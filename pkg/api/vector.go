@@ -0,0 +1,179 @@
+package typesenseapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	schemax "github.com/foomo/typesense/pkg/schema"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// defaultVectorK is the number of nearest neighbors requested by vector searches
+const defaultVectorK = 10
+
+// VectorOption configures a vector search
+type VectorOption func(o *vectorOptions)
+
+type vectorOptions struct {
+	field             string
+	k                 int
+	alpha             *float64
+	distanceThreshold *float64
+	ef                int
+	filterBy          string
+	presetName        string
+	page              int
+	perPage           int
+}
+
+// VectorField sets the vector field to search, it defaults to the first float[] field with dimensions of the schema
+func VectorField(field string) VectorOption {
+	return func(o *vectorOptions) {
+		o.field = field
+	}
+}
+
+// VectorK sets the number of nearest neighbors
+func VectorK(k int) VectorOption {
+	return func(o *vectorOptions) {
+		o.k = k
+	}
+}
+
+// VectorAlpha weights the vector ranking against the keyword ranking of hybrid searches,
+// 1 ranks by vector distance only and 0 by text match only
+func VectorAlpha(alpha float64) VectorOption {
+	return func(o *vectorOptions) {
+		o.alpha = &alpha
+	}
+}
+
+// VectorDistanceThreshold drops hits whose vector distance exceeds the threshold
+func VectorDistanceThreshold(threshold float64) VectorOption {
+	return func(o *vectorOptions) {
+		o.distanceThreshold = &threshold
+	}
+}
+
+// VectorEf sets the size of the candidate list of the HNSW search, larger values improve the recall at the cost
+// of latency, it has to be at least VectorK
+func VectorEf(ef int) VectorOption {
+	return func(o *vectorOptions) {
+		o.ef = ef
+	}
+}
+
+// VectorFilter restricts the search to the documents matching the filter
+func VectorFilter(filter string) VectorOption {
+	return func(o *vectorOptions) {
+		o.filterBy = filter
+	}
+}
+
+// VectorPreset sets the preset providing the query_by fields of the keyword part of hybrid searches, it defaults to
+// the preset of the index
+func VectorPreset(name string) VectorOption {
+	return func(o *vectorOptions) {
+		o.presetName = name
+	}
+}
+
+// VectorPage selects the page and page size of the hits
+func VectorPage(page, perPage int) VectorOption {
+	return func(o *vectorOptions) {
+		o.page = page
+		o.perPage = perPage
+	}
+}
+
+// VectorSearch searches the nearest neighbors of the embedding in the vector field of the index. With a query the
+// search is hybrid and the keyword and vector rankings are fused, weighted by VectorAlpha. Without an embedding
+// the query is embedded by typesense, which requires an auto-embedding field. Enforced filters, curation and
+// conversion apply as for ExpertSearchResult.
+func (b *BaseAPI[indexDocument, returnType]) VectorSearch(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	query string,
+	embedding []float32,
+	opts ...VectorOption,
+) (*pkgx.SearchResult[returnType], error) {
+	o := vectorOptions{k: defaultVectorK, presetName: b.searchPreset(indexID)}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.field == "" {
+		o.field = b.vectorField(indexID)
+	}
+	if o.field == "" {
+		return nil, fmt.Errorf("index %s has no vector field", indexID)
+	}
+	if query == "" && len(embedding) == 0 {
+		return nil, errors.New("vector search requires a query or an embedding")
+	}
+	if o.ef > 0 && o.ef < o.k {
+		return nil, fmt.Errorf("vector ef %d must not be less than k %d", o.ef, o.k)
+	}
+
+	parameters := &api.SearchCollectionParams{
+		Q:           pointer.String("*"),
+		Preset:      pointer.String(o.presetName),
+		VectorQuery: pointer.String(vectorQuery(o, embedding)),
+	}
+	if query != "" {
+		parameters.Q = pointer.String(query)
+	}
+	if len(embedding) == 0 {
+		// typesense embeds the query with the model of the auto-embedding field
+		parameters.QueryBy = pointer.String(o.field)
+	}
+	if o.filterBy != "" {
+		parameters.FilterBy = pointer.String(o.filterBy)
+	}
+	if o.page > 0 {
+		parameters.Page = pointer.Int(o.page)
+	}
+	if o.perPage > 0 {
+		parameters.PerPage = pointer.Int(o.perPage)
+	}
+	return b.ExpertSearchResult(ctx, indexID, parameters)
+}
+
+// vectorQuery formats the vector_query parameter, e.g. "embedding:([0.1,0.2], k:10, alpha:0.3)"
+func vectorQuery(o vectorOptions, embedding []float32) string {
+	values := make([]string, len(embedding))
+	for i, value := range embedding {
+		values[i] = strconv.FormatFloat(float64(value), 'g', -1, 32)
+	}
+	arguments := []string{"[" + strings.Join(values, ",") + "]", "k:" + strconv.Itoa(o.k)}
+	if o.alpha != nil {
+		arguments = append(arguments, "alpha:"+strconv.FormatFloat(*o.alpha, 'g', -1, 64))
+	}
+	if o.distanceThreshold != nil {
+		arguments = append(arguments, "distance_threshold:"+strconv.FormatFloat(*o.distanceThreshold, 'g', -1, 64))
+	}
+	if o.ef > 0 {
+		arguments = append(arguments, "ef:"+strconv.Itoa(o.ef))
+	}
+	return o.field + ":(" + strings.Join(arguments, ", ") + ")"
+}
+
+// vectorField returns the first float[] field with dimensions or an embedding configuration of the index schema
+func (b *BaseAPI[indexDocument, returnType]) vectorField(indexID pkgx.IndexID) string {
+	schema, ok := b.collections[indexID]
+	if !ok || schema == nil {
+		return ""
+	}
+	for _, field := range schema.Fields {
+		if field.Type == schemax.TypeFloatArray && (field.NumDim != nil || field.Embed != nil) {
+			return field.Name
+		}
+	}
+	return ""
+}
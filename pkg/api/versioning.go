@@ -0,0 +1,52 @@
+package typesenseapi
+
+import (
+	"encoding/json"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+const (
+	// indexedAtField stores the unix time the document was imported at, it is not part of the schema and not indexed
+	indexedAtField = "_indexed_at"
+	// revisionField stores the revision the document was imported with, it is not part of the schema and not indexed
+	revisionField = "_revision"
+)
+
+// stampDocuments adds the import time and revision to the documents, converting them to maps if required
+func stampDocuments(documents []interface{}, revisionID pkgx.RevisionID, indexedAt time.Time) ([]interface{}, error) {
+	stamped := make([]interface{}, 0, len(documents))
+	for _, document := range documents {
+		fields, ok := document.(map[string]any)
+		if !ok {
+			data, err := json.Marshal(document)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, err
+			}
+		}
+		if fields == nil {
+			// nil documents are not imported
+			continue
+		}
+		fields[indexedAtField] = indexedAt.Unix()
+		fields[revisionField] = string(revisionID)
+		stamped = append(stamped, fields)
+	}
+	return stamped, nil
+}
+
+// documentVersion reads the version stamped on a hit document, it is empty for unstamped documents
+func documentVersion(document map[string]any) pkgx.DocumentVersion {
+	var version pkgx.DocumentVersion
+	if revisionID, ok := document[revisionField].(string); ok {
+		version.RevisionID = pkgx.RevisionID(revisionID)
+	}
+	if indexedAt, ok := document[indexedAtField].(float64); ok {
+		version.IndexedAt = time.Unix(int64(indexedAt), 0)
+	}
+	return version
+}
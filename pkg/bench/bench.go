@@ -0,0 +1,228 @@
+package typesensebench
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+const collectionSuffix = "-bench"
+
+// Config describes a benchmark run
+type Config struct {
+	// Schema of the benchmarked collection, the collection is created as "<name>-bench" and deleted afterwards
+	Schema *api.CollectionSchema
+	// Documents is the number of synthetic documents to import
+	Documents int
+	// BatchSize is the number of documents per import request
+	BatchSize int
+	// Searches is the number of search requests to execute after the import
+	Searches int
+	// Concurrency is the number of parallel import and search requests
+	Concurrency int
+	// QueryBy defaults to all string fields of the schema
+	QueryBy string
+	Seed    uint64
+	// KeepCollection skips deleting the benchmark collection, e.g. to inspect it afterwards
+	KeepCollection bool
+}
+
+// Stats summarizes the requests of one benchmark phase
+type Stats struct {
+	Requests   int
+	Errors     int
+	Duration   time.Duration
+	Throughput float64 // requests per second
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Max        time.Duration
+}
+
+// Report is the result of a benchmark run
+type Report struct {
+	Collection string
+	Documents  int
+	// DocumentsPerSecond is the import throughput in documents
+	DocumentsPerSecond float64
+	Import             Stats
+	Search             Stats
+}
+
+// Bench drives imports and searches against a typesense cluster
+type Bench struct {
+	l      *zap.Logger
+	client *typesense.Client
+	config Config
+}
+
+func NewBench(
+	l *zap.Logger,
+	client *typesense.Client,
+	config Config,
+) *Bench {
+	if config.BatchSize < 1 {
+		config.BatchSize = 100
+	}
+	if config.Concurrency < 1 {
+		config.Concurrency = 1
+	}
+	return &Bench{
+		l:      l,
+		client: client,
+		config: config,
+	}
+}
+
+// Run creates the benchmark collection, imports the synthetic documents, runs the searches and reports the results
+func (b *Bench) Run(ctx context.Context) (*Report, error) {
+	if b.config.Schema == nil {
+		return nil, errors.New("schema cannot be nil")
+	}
+
+	schema := *b.config.Schema
+	schema.Name += collectionSuffix
+	collectionName := schema.Name
+	generator := NewGenerator(&schema, b.config.Seed)
+
+	if _, err := b.client.Collection(collectionName).Delete(ctx); err != nil {
+		b.l.Debug("no benchmark collection to delete", zap.String("collection", collectionName), zap.Error(err))
+	}
+	if _, err := b.client.Collections().Create(ctx, &schema); err != nil {
+		b.l.Error("failed to create benchmark collection", zap.String("collection", collectionName), zap.Error(err))
+		return nil, err
+	}
+	if !b.config.KeepCollection {
+		defer func() {
+			if _, err := b.client.Collection(collectionName).Delete(context.WithoutCancel(ctx)); err != nil {
+				b.l.Warn("failed to delete benchmark collection", zap.String("collection", collectionName), zap.Error(err))
+			}
+		}()
+	}
+
+	report := &Report{
+		Collection: collectionName,
+		Documents:  b.config.Documents,
+	}
+
+	// generate all batches upfront so the generator does not distort the import latencies
+	var batches [][]interface{}
+	for start := 0; start < b.config.Documents; start += b.config.BatchSize {
+		end := min(start+b.config.BatchSize, b.config.Documents)
+		batch := make([]interface{}, 0, end-start)
+		for id := start; id < end; id++ {
+			batch = append(batch, generator.Document(id))
+		}
+		batches = append(batches, batch)
+	}
+
+	b.l.Info("importing documents", zap.String("collection", collectionName), zap.Int("documents", b.config.Documents))
+	report.Import = b.measure(ctx, len(batches), func(ctx context.Context, i int) error {
+		_, err := b.client.Collection(collectionName).Documents().Import(ctx, batches[i], &api.ImportDocumentsParams{
+			Action: (*api.IndexAction)(pointer.String("create")),
+		})
+		return err
+	})
+	if report.Import.Duration > 0 {
+		report.DocumentsPerSecond = float64(b.config.Documents) / report.Import.Duration.Seconds()
+	}
+
+	queryBy := b.config.QueryBy
+	if queryBy == "" {
+		queryBy = stringFields(&schema)
+	}
+	if queryBy == "" {
+		b.l.Warn("no string fields to query, skipping searches", zap.String("collection", collectionName))
+		return report, nil
+	}
+
+	queries := make([]string, b.config.Searches)
+	for i := range queries {
+		queries[i] = generator.Query()
+	}
+
+	b.l.Info("running searches", zap.String("collection", collectionName), zap.Int("searches", b.config.Searches))
+	report.Search = b.measure(ctx, len(queries), func(ctx context.Context, i int) error {
+		_, err := b.client.Collection(collectionName).Documents().Search(ctx, &api.SearchCollectionParams{
+			Q:       pointer.String(queries[i]),
+			QueryBy: pointer.String(queryBy),
+		})
+		return err
+	})
+
+	return report, nil
+}
+
+// measure executes n requests with the configured concurrency and collects their latencies
+func (b *Bench) measure(ctx context.Context, n int, request func(ctx context.Context, i int) error) Stats {
+	latencies := make([]time.Duration, n)
+	errs := make([]error, n)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for range b.config.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				requestStart := time.Now()
+				errs[i] = request(ctx, i)
+				latencies[i] = time.Since(requestStart)
+			}
+		}()
+	}
+	sent := 0
+	for ; sent < n && ctx.Err() == nil; sent++ {
+		jobs <- sent
+	}
+	close(jobs)
+	wg.Wait()
+	latencies = latencies[:sent]
+
+	stats := Stats{
+		Requests: sent,
+		Duration: time.Since(start),
+	}
+	for _, err := range errs[:sent] {
+		if err != nil {
+			stats.Errors++
+			b.l.Debug("benchmark request failed", zap.Error(err))
+		}
+	}
+	if stats.Duration > 0 {
+		stats.Throughput = float64(sent) / stats.Duration.Seconds()
+	}
+	if sent > 0 {
+		slices.Sort(latencies)
+		stats.P50 = percentile(latencies, 0.5)
+		stats.P90 = percentile(latencies, 0.9)
+		stats.P99 = percentile(latencies, 0.99)
+		stats.Max = latencies[sent-1]
+	}
+	return stats
+}
+
+// percentile returns the given percentile of the sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[max(0, min(index, len(sorted)-1))]
+}
+
+func stringFields(schema *api.CollectionSchema) string {
+	var fields []string
+	for _, field := range schema.Fields {
+		if (field.Type == "string" || field.Type == "string[]") && field.Embed == nil && !strings.ContainsAny(field.Name, ".*") {
+			fields = append(fields, field.Name)
+		}
+	}
+	return strings.Join(fields, ",")
+}
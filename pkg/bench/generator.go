@@ -0,0 +1,120 @@
+package typesensebench
+
+import (
+	"math/rand/v2"
+	"strconv"
+	"strings"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+const vocabularySize = 2000
+
+// Generator creates synthetic documents matching a collection schema.
+// String fields are filled with words from a fixed vocabulary so generated queries find hits.
+type Generator struct {
+	fields     []api.Field
+	rng        *rand.Rand
+	vocabulary []string
+}
+
+func NewGenerator(schema *api.CollectionSchema, seed uint64) *Generator {
+	rng := rand.New(rand.NewPCG(seed, seed)) //nolint:gosec // synthetic benchmark data
+	vocabulary := make([]string, vocabularySize)
+	for i := range vocabulary {
+		vocabulary[i] = randomWord(rng)
+	}
+	return &Generator{
+		fields:     schema.Fields,
+		rng:        rng,
+		vocabulary: vocabulary,
+	}
+}
+
+// Document returns a new document with the given ID and a random value for every field of the schema
+func (g *Generator) Document(id int) map[string]any {
+	document := map[string]any{
+		"id": strconv.Itoa(id),
+	}
+	for _, field := range g.fields {
+		if field.Name == "id" || strings.ContainsAny(field.Name, ".*") {
+			continue
+		}
+		if value, ok := g.value(field); ok {
+			document[field.Name] = value
+		}
+	}
+	return document
+}
+
+// Query returns a random query of one or two vocabulary words
+func (g *Generator) Query() string {
+	query := g.word()
+	if g.rng.IntN(2) == 0 {
+		query += " " + g.word()
+	}
+	return query
+}
+
+func (g *Generator) value(field api.Field) (any, bool) {
+	if field.Embed != nil {
+		// embeddings are generated by typesense
+		return nil, false
+	}
+	if field.Type == "float[]" && field.NumDim != nil {
+		// vector fields are a single float array with the configured dimensions
+		vector := make([]float32, *field.NumDim)
+		for i := range vector {
+			vector[i] = g.rng.Float32()
+		}
+		return vector, true
+	}
+	if elementType, ok := strings.CutSuffix(field.Type, "[]"); ok {
+		values := make([]any, 1+g.rng.IntN(4))
+		for i := range values {
+			value, ok := g.value(api.Field{Name: field.Name, Type: elementType})
+			if !ok {
+				return nil, false
+			}
+			values[i] = value
+		}
+		return values, true
+	}
+
+	switch field.Type {
+	case "string":
+		words := make([]string, 3+g.rng.IntN(12))
+		for i := range words {
+			words[i] = g.word()
+		}
+		return strings.Join(words, " "), true
+	case "int32":
+		return g.rng.Int32N(100000), true
+	case "int64":
+		return g.rng.Int64N(1 << 40), true
+	case "float":
+		return g.rng.Float64() * 1000, true
+	case "bool":
+		return g.rng.IntN(2) == 0, true
+	case "geopoint":
+		return []float64{g.rng.Float64()*180 - 90, g.rng.Float64()*360 - 180}, true
+	default:
+		// auto, object and embedding source fields are left out
+		return nil, false
+	}
+}
+
+func (g *Generator) word() string {
+	// skew the distribution so some words are far more frequent than others
+	index := int(float64(len(g.vocabulary)) * g.rng.Float64() * g.rng.Float64())
+	return g.vocabulary[index]
+}
+
+func randomWord(rng *rand.Rand) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	word := make([]byte, 3+rng.IntN(7))
+	for i := range word {
+		word[i] = letters[rng.IntN(len(letters))]
+	}
+	return string(word)
+}
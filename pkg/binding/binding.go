@@ -0,0 +1,229 @@
+package typesensebinding
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+const (
+	defaultMaxPage        = 100
+	defaultMaxQueryLength = 256
+)
+
+type contextKey struct{}
+
+// ValidationError is returned for query string parameters that can't be bound
+type ValidationError struct {
+	Parameter string
+	Message   string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid parameter %s: %s", e.Parameter, e.Message)
+}
+
+// Option configures the Binder
+type Option func(b *Binder)
+
+// WithFilters allows filtering by the given fields through "filters[field]=value" parameters
+func WithFilters(fields ...string) Option {
+	return func(b *Binder) {
+		b.filters = fields
+	}
+}
+
+// WithSorts allows the given sort expressions, e.g. "price:asc", through the "sort" parameter.
+// The first one is used if the parameter is missing.
+func WithSorts(sorts ...string) Option {
+	return func(b *Binder) {
+		b.sorts = sorts
+	}
+}
+
+// WithProfiles restricts the "profile" parameter to the given projection profiles,
+// by default any profile is passed on and validated by the API
+func WithProfiles(profiles ...string) Option {
+	return func(b *Binder) {
+		b.profiles = profiles
+	}
+}
+
+// WithMaxPage limits the "page" parameter
+func WithMaxPage(maxPage int) Option {
+	return func(b *Binder) {
+		b.maxPage = maxPage
+	}
+}
+
+// WithMaxQueryLength limits the length of the "q" parameter
+func WithMaxQueryLength(maxQueryLength int) Option {
+	return func(b *Binder) {
+		b.maxQueryLength = maxQueryLength
+	}
+}
+
+// WithPresetName sets the search preset of all bound parameters
+func WithPresetName(presetName string) Option {
+	return func(b *Binder) {
+		b.presetName = presetName
+	}
+}
+
+// Binder parses query strings like "?q=shoes&page=2&filters[brand]=acme&sort=price:asc" into validated SearchParameters.
+// It works on the plain *http.Request, so it can be used with any framework, e.g. binder.Bind(c.Request()) in Echo
+// or binder.Bind(c.Request) in Gin.
+type Binder struct {
+	filters        []string
+	sorts          []string
+	profiles       []string
+	maxPage        int
+	maxQueryLength int
+	presetName     string
+}
+
+func NewBinder(opts ...Option) *Binder {
+	b := &Binder{
+		maxPage:        defaultMaxPage,
+		maxQueryLength: defaultMaxQueryLength,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(b)
+		}
+	}
+	return b
+}
+
+// Filters returns the fields that can be filtered by
+func (b *Binder) Filters() []string {
+	return b.filters
+}
+
+// Sorts returns the allowed sort expressions
+func (b *Binder) Sorts() []string {
+	return b.sorts
+}
+
+// Profiles returns the allowed projection profiles
+func (b *Binder) Profiles() []string {
+	return b.profiles
+}
+
+// Bind parses the query string of the request into SearchParameters
+func (b *Binder) Bind(r *http.Request) (*pkgx.SearchParameters, error) {
+	return b.BindValues(r.URL.Query())
+}
+
+// BindValues parses the given query values into SearchParameters
+func (b *Binder) BindValues(values url.Values) (*pkgx.SearchParameters, error) {
+	params := &pkgx.SearchParameters{
+		Page:       1,
+		PresetName: b.presetName,
+	}
+
+	params.Query = strings.TrimSpace(values.Get("q"))
+	if len([]rune(params.Query)) > b.maxQueryLength {
+		return nil, &ValidationError{Parameter: "q", Message: fmt.Sprintf("must not be longer than %d characters", b.maxQueryLength)}
+	}
+
+	if page := values.Get("page"); page != "" {
+		p, err := strconv.Atoi(page)
+		if err != nil || p < 1 || p > b.maxPage {
+			return nil, &ValidationError{Parameter: "page", Message: fmt.Sprintf("must be a number between 1 and %d", b.maxPage)}
+		}
+		params.Page = p
+	}
+
+	if sortBy := values.Get("sort"); sortBy != "" {
+		if !slices.Contains(b.sorts, sortBy) {
+			return nil, &ValidationError{Parameter: "sort", Message: "unsupported sort " + sortBy}
+		}
+		params.SortBy = sortBy
+	} else if len(b.sorts) > 0 {
+		params.SortBy = b.sorts[0]
+	}
+
+	filterBy, err := b.bindFilters(values)
+	if err != nil {
+		return nil, err
+	}
+	params.FilterBy = filterBy
+
+	if profile := values.Get("profile"); profile != "" {
+		if len(b.profiles) > 0 && !slices.Contains(b.profiles, profile) {
+			return nil, &ValidationError{Parameter: "profile", Message: "unsupported profile " + profile}
+		}
+		params.Profile = profile
+	}
+
+	return params, nil
+}
+
+// Middleware binds the SearchParameters of every request and stores them in the request context,
+// requests with invalid parameters are answered with 400 Bad Request
+func (b *Binder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params, err := b.Bind(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithSearchParameters(r.Context(), params)))
+	})
+}
+
+// WithSearchParameters returns a copy of the context holding the given SearchParameters
+func WithSearchParameters(ctx context.Context, params *pkgx.SearchParameters) context.Context {
+	return context.WithValue(ctx, contextKey{}, params)
+}
+
+// SearchParametersFromContext returns the SearchParameters bound by the Middleware
+func SearchParametersFromContext(ctx context.Context) (*pkgx.SearchParameters, bool) {
+	params, ok := ctx.Value(contextKey{}).(*pkgx.SearchParameters)
+	return params, ok
+}
+
+// bindFilters translates "filters[field]=value" parameters into a filter_by expression,
+// multiple values of the same field are combined with OR
+func (b *Binder) bindFilters(values url.Values) (string, error) {
+	var fields []string
+	for key := range values {
+		field, ok := strings.CutPrefix(key, "filters[")
+		if !ok {
+			continue
+		}
+		field, ok = strings.CutSuffix(field, "]")
+		if !ok || !slices.Contains(b.filters, field) {
+			return "", &ValidationError{Parameter: key, Message: "unsupported filter"}
+		}
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	filter := pkgx.NewFilterBuilder()
+	for _, field := range fields {
+		var filterValues []any
+		for _, value := range values["filters["+field+"]"] {
+			if value = strings.TrimSpace(value); value != "" {
+				filterValues = append(filterValues, value)
+			}
+		}
+		switch len(filterValues) {
+		case 0:
+			continue
+		case 1:
+			filter.Eq(field, filterValues[0])
+		default:
+			filter.In(field, filterValues...)
+		}
+	}
+	return filter.Build(), nil
+}
@@ -0,0 +1,347 @@
+package typesensecache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	typesenseapi "github.com/foomo/typesense/pkg/api"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+var _ pkgx.API[any, any] = (*API[any, any])(nil)
+
+// IndexConfig configures the caching of an index
+type IndexConfig struct {
+	// TTL is the duration a result is served without refreshing it
+	TTL time.Duration
+	// StaleTTL is the additional duration an expired result is still served while it is refreshed in the background
+	StaleTTL time.Duration
+}
+
+type entry[returnType any] struct {
+	index      pkgx.IndexID
+	result     *pkgx.SearchResult[returnType]
+	storedAt   time.Time
+	freshUntil time.Time
+	staleUntil time.Time
+	refreshing bool
+}
+
+// API decorates an API with a stale-while-revalidate cache for the search results of the configured indices.
+// Results are shared between callers with the same roles, see pkg.WithRoles, and must not be modified. Searches of
+// other indices, debug searches and simple searches with a Modify function are passed through, partial results
+// are not cached. The cache is purged after a revision has been committed and, if
+// AliasSwitched is registered with typesenseapi.WithOnAliasSwitched, after every other alias switch.
+type API[indexDocument any, returnType any] struct {
+	pkgx.API[indexDocument, returnType]
+	l       *zap.Logger
+	indices map[pkgx.IndexID]IndexConfig
+	opts    options
+	metrics *metrics
+	mu      sync.Mutex
+	entries map[string]*entry[returnType]
+	// generation is incremented by every purge, results fetched before are not stored
+	generation uint64
+}
+
+func NewAPI[indexDocument any, returnType any](
+	l *zap.Logger,
+	api pkgx.API[indexDocument, returnType],
+	indices map[pkgx.IndexID]IndexConfig,
+	opts ...Option,
+) *API[indexDocument, returnType] {
+	o := newOptions(opts...)
+	return &API[indexDocument, returnType]{
+		API:     api,
+		l:       l,
+		indices: indices,
+		opts:    o,
+		metrics: newMetrics(o.registerer),
+		entries: map[string]*entry[returnType]{},
+	}
+}
+
+// DeployCanary forwards to the decorated API, see pkgx.CanaryDeployer
+func (a *API[indexDocument, returnType]) DeployCanary(ctx context.Context, revisionID pkgx.RevisionID) error {
+	deployer, ok := a.API.(pkgx.CanaryDeployer)
+	if !ok {
+		return fmt.Errorf("%w: canary aliases", pkgx.ErrUnsupported)
+	}
+	return deployer.DeployCanary(ctx, revisionID)
+}
+
+// SampleRevision forwards to the decorated API, see pkgx.RevisionSampler
+func (a *API[indexDocument, returnType]) SampleRevision(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	n int,
+) ([]returnType, error) {
+	sampler, ok := a.API.(pkgx.RevisionSampler[returnType])
+	if !ok {
+		return nil, fmt.Errorf("%w: revision sampling", pkgx.ErrUnsupported)
+	}
+	return sampler.SampleRevision(ctx, revisionID, indexID, n)
+}
+
+func (a *API[indexDocument, returnType]) CommitRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	// failed commits may have moved some aliases, e.g. if pinned indices were skipped
+	err := a.API.CommitRevision(ctx, revisionID)
+	a.Purge()
+	return err
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) ([]returnType, pkgx.Scores, int, error) {
+	result, err := a.SimpleSearchResult(ctx, index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) ([]returnType, pkgx.Scores, int, error) {
+	result, err := a.ExpertSearchResult(ctx, index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*pkgx.SearchResult[returnType], error) {
+	fetch := func(ctx context.Context) (*pkgx.SearchResult[returnType], error) {
+		return a.API.SimpleSearchResult(ctx, index, parameters)
+	}
+	if parameters == nil || parameters.Modify != nil {
+		return a.bypass(ctx, index, fetch)
+	}
+	return a.search(ctx, index, "simple", parameters, fetch)
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*pkgx.SearchResult[returnType], error) {
+	fetch := func(ctx context.Context) (*pkgx.SearchResult[returnType], error) {
+		return a.API.ExpertSearchResult(ctx, index, parameters)
+	}
+	if parameters == nil {
+		return a.bypass(ctx, index, fetch)
+	}
+	return a.search(ctx, index, "expert", parameters, fetch)
+}
+
+// Purge removes all cached results
+func (a *API[indexDocument, returnType]) Purge() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	clear(a.entries)
+	a.generation++
+	a.metrics.entries.Set(0)
+}
+
+// PurgeIndex removes the cached results of the index
+func (a *API[indexDocument, returnType]) PurgeIndex(index pkgx.IndexID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key, e := range a.entries {
+		if e.index == index {
+			delete(a.entries, key)
+		}
+	}
+	a.generation++
+	a.metrics.entries.Set(float64(len(a.entries)))
+}
+
+// AliasSwitched purges the results of the index whose alias has been moved, so rollbacks, repoints and slot
+// promotions don't serve results of the previous collection. The base API is created before the cache, register it
+// with a closure, e.g. typesenseapi.WithOnAliasSwitched(func(ctx context.Context, event typesenseapi.AliasSwitch) error
+// { return cache.AliasSwitched(ctx, event) })
+func (a *API[indexDocument, returnType]) AliasSwitched(_ context.Context, event typesenseapi.AliasSwitch) error {
+	a.PurgeIndex(event.IndexID)
+	return nil
+}
+
+// search serves the result from the cache, refreshes stale results in the background and fetches missing results
+func (a *API[indexDocument, returnType]) search(
+	ctx context.Context,
+	index pkgx.IndexID,
+	kind string,
+	parameters any,
+	fetch func(ctx context.Context) (*pkgx.SearchResult[returnType], error),
+) (*pkgx.SearchResult[returnType], error) {
+	config, ok := a.indices[index]
+	if !ok {
+		return fetch(ctx)
+	}
+	// debug results contain the enforced filters of the caller
+	if typesenseapi.IsSearchDebug(ctx) {
+		return a.bypass(ctx, index, fetch)
+	}
+	key, err := a.key(ctx, index, kind, parameters)
+	if err != nil {
+		a.l.Warn("failed to build cache key", zap.String("index", string(index)), zap.Error(err))
+		return a.bypass(ctx, index, fetch)
+	}
+
+	now := a.opts.clock.Now()
+	a.mu.Lock()
+	generation := a.generation
+	if e, ok := a.entries[key]; ok {
+		if now.Before(e.freshUntil) {
+			a.mu.Unlock()
+			a.metrics.requests.WithLabelValues(string(index), resultHit).Inc()
+			return e.result, nil
+		}
+		if now.Before(e.staleUntil) {
+			refresh := !e.refreshing
+			e.refreshing = true
+			a.mu.Unlock()
+			a.metrics.requests.WithLabelValues(string(index), resultStale).Inc()
+			if refresh {
+				go a.refresh(context.WithoutCancel(ctx), index, key, config, generation, fetch)
+			}
+			return e.result, nil
+		}
+	}
+	a.mu.Unlock()
+
+	a.metrics.requests.WithLabelValues(string(index), resultMiss).Inc()
+	result, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a.store(index, key, config, generation, result)
+	return result, nil
+}
+
+// bypass fetches the result without caching it
+func (a *API[indexDocument, returnType]) bypass(
+	ctx context.Context,
+	index pkgx.IndexID,
+	fetch func(ctx context.Context) (*pkgx.SearchResult[returnType], error),
+) (*pkgx.SearchResult[returnType], error) {
+	if _, ok := a.indices[index]; ok {
+		a.metrics.requests.WithLabelValues(string(index), resultBypass).Inc()
+	}
+	return fetch(ctx)
+}
+
+// refresh fetches a stale result again, failed refreshes keep serving the stale result until it expires
+func (a *API[indexDocument, returnType]) refresh(
+	ctx context.Context,
+	index pkgx.IndexID,
+	key string,
+	config IndexConfig,
+	generation uint64,
+	fetch func(ctx context.Context) (*pkgx.SearchResult[returnType], error),
+) {
+	result, err := fetch(ctx)
+	if err != nil {
+		a.metrics.refreshes.WithLabelValues(string(index), "error").Inc()
+		a.l.Warn("failed to refresh cached search result", zap.String("index", string(index)), zap.Error(err))
+		a.mu.Lock()
+		if e, ok := a.entries[key]; ok {
+			e.refreshing = false
+		}
+		a.mu.Unlock()
+		return
+	}
+	a.metrics.refreshes.WithLabelValues(string(index), "success").Inc()
+	a.store(index, key, config, generation, result)
+}
+
+// store caches the result and evicts expired or, if the cache is full, the oldest entries. Results fetched before
+// the cache was purged, i.e. in an older generation, are dropped.
+func (a *API[indexDocument, returnType]) store(
+	index pkgx.IndexID,
+	key string,
+	config IndexConfig,
+	generation uint64,
+	result *pkgx.SearchResult[returnType],
+) {
+	// partial results were cut off by the latency budget, the next search may complete
+	if result.Partial || result.Debug != nil {
+		return
+	}
+	now := a.opts.clock.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if generation != a.generation {
+		return
+	}
+
+	if _, ok := a.entries[key]; !ok && len(a.entries) >= a.opts.maxEntries {
+		a.evict(now)
+	}
+	a.entries[key] = &entry[returnType]{
+		index:      index,
+		result:     result,
+		storedAt:   now,
+		freshUntil: now.Add(config.TTL),
+		staleUntil: now.Add(config.TTL + config.StaleTTL),
+	}
+	a.metrics.entries.Set(float64(len(a.entries)))
+}
+
+// evict removes all expired entries, or the oldest entry if none has expired
+func (a *API[indexDocument, returnType]) evict(now time.Time) {
+	oldestKey := ""
+	var oldest time.Time
+	for key, e := range a.entries {
+		if !now.Before(e.staleUntil) && !e.refreshing {
+			delete(a.entries, key)
+			continue
+		}
+		if oldestKey == "" || e.storedAt.Before(oldest) {
+			oldestKey, oldest = key, e.storedAt
+		}
+	}
+	if len(a.entries) >= a.opts.maxEntries && oldestKey != "" {
+		delete(a.entries, oldestKey)
+	}
+}
+
+// key identifies a search by index, searched alias, roles of the caller, context segment, kind and parameters
+func (a *API[indexDocument, returnType]) key(ctx context.Context, index pkgx.IndexID, kind string, parameters any) (string, error) {
+	data, err := json.Marshal(parameters)
+	if err != nil {
+		return "", err
+	}
+	roles := slices.Clone(pkgx.Roles(ctx))
+	slices.Sort(roles)
+	segment := strings.Join(roles, ",")
+	if a.opts.keyFunc != nil {
+		segment += "\x00" + a.opts.keyFunc(ctx)
+	}
+	return string(index) + "\x00" + searchTarget(ctx) + "\x00" + segment + "\x00" + kind + "\x00" + string(data), nil
+}
+
+// searchTarget returns the slot and canary routing of the context, searches routed to other aliases of the index
+// never share results
+func searchTarget(ctx context.Context) string {
+	target := typesenseapi.Slot(ctx)
+	if typesenseapi.IsCanary(ctx) {
+		target += "\x00canary"
+	}
+	return target
+}
@@ -0,0 +1,62 @@
+package typesensecache
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	resultHit    = "hit"
+	resultStale  = "stale"
+	resultMiss   = "miss"
+	resultBypass = "bypass"
+)
+
+type metrics struct {
+	requests  *prometheus.CounterVec
+	refreshes *prometheus.CounterVec
+	entries   prometheus.Gauge
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "typesense",
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Number of cached searches by index and result (hit, stale, miss, bypass)",
+		}, []string{"index", "result"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "typesense",
+			Subsystem: "cache",
+			Name:      "refreshes_total",
+			Help:      "Number of background refreshes of stale results by index and result",
+		}, []string{"index", "result"}),
+		entries: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "typesense",
+			Subsystem: "cache",
+			Name:      "entries",
+			Help:      "Number of cached search results",
+		}),
+	}
+	if registerer != nil {
+		m.requests = register(registerer, m.requests)
+		m.refreshes = register(registerer, m.refreshes)
+		m.entries = register(registerer, m.entries)
+	}
+	return m
+}
+
+// register registers the collector or returns the already registered one, e.g. when a cache is recreated
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+	}
+	return collector
+}
@@ -0,0 +1,70 @@
+package typesensecache
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultMaxEntries = 1000
+
+// KeyFunc derives a cache key segment from the request context. Searches with different segments never share
+// results, e.g. when an AccessFilterProvider restricts the results by something else than the roles of the caller,
+// which are always part of the key.
+type KeyFunc func(ctx context.Context) string
+
+// Option configures the cache
+type Option func(o *options)
+
+type options struct {
+	maxEntries int
+	keyFunc    KeyFunc
+	registerer prometheus.Registerer
+	clock      pkgx.Clock
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		maxEntries: defaultMaxEntries,
+		registerer: prometheus.DefaultRegisterer,
+		clock:      pkgx.SystemClock,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.maxEntries < 1 {
+		o.maxEntries = defaultMaxEntries
+	}
+	return o
+}
+
+// WithMaxEntries limits the number of cached results, the oldest entries are evicted first
+func WithMaxEntries(maxEntries int) Option {
+	return func(o *options) {
+		o.maxEntries = maxEntries
+	}
+}
+
+// WithKeyFunc partitions the cache by a segment derived from the request context
+func WithKeyFunc(keyFunc KeyFunc) Option {
+	return func(o *options) {
+		o.keyFunc = keyFunc
+	}
+}
+
+// WithRegisterer registers the cache metrics with the given registerer, nil disables the registration
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
+
+// WithClock replaces the system clock used for expiry, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
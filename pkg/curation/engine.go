@@ -0,0 +1,208 @@
+package typesensecuration
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// maxSortCriteria is the maximum number of sort_by fields supported by typesense
+const maxSortCriteria = 3
+
+// Option configures the engine
+type Option func(o *options)
+
+type options struct {
+	clock pkgx.Clock
+}
+
+// WithClock replaces the system clock used to evaluate campaign periods, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// Engine evaluates curation rules before searches are sent to typesense.
+// Rules are applied in order, the rule set is replaced atomically on Reload.
+type Engine struct {
+	l      *zap.Logger
+	source Source
+	opts   options
+	rules  atomic.Pointer[[]compiledRule]
+}
+
+var (
+	_ pkgx.SearchCurator    = (*Engine)(nil)
+	_ pkgx.SearchRedirector = (*Engine)(nil)
+)
+
+func NewEngine(
+	l *zap.Logger,
+	source Source,
+	opts ...Option,
+) *Engine {
+	o := options{
+		clock: pkgx.SystemClock,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	e := &Engine{
+		l:      l,
+		source: source,
+		opts:   o,
+	}
+	e.rules.Store(&[]compiledRule{})
+	return e
+}
+
+// Reload loads and validates the rules of the source, the current rules are kept if any rule is invalid
+func (e *Engine) Reload(ctx context.Context) error {
+	rules, err := e.source.Load(ctx)
+	if err != nil {
+		e.l.Error("failed to load curation rules", zap.Error(err))
+		return err
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c, err := compile(rule)
+		if err != nil {
+			e.l.Error("invalid curation rule", zap.String("rule", rule.ID), zap.Error(err))
+			return err
+		}
+		compiled = append(compiled, c)
+	}
+
+	e.rules.Store(&compiled)
+	e.l.Info("loaded curation rules", zap.Int("rules", len(compiled)))
+	return nil
+}
+
+// Watch reloads the rules in the given interval until the context is done, failed reloads keep the current rules
+func (e *Engine) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = e.Reload(ctx)
+		}
+	}
+}
+
+// Rules returns the currently active rules
+func (e *Engine) Rules() []Rule {
+	compiled := *e.rules.Load()
+	rules := make([]Rule, len(compiled))
+	for i, c := range compiled {
+		rules[i] = c.Rule
+	}
+	return rules
+}
+
+// Redirect returns the URL of the first active redirect rule matching the query
+func (e *Engine) Redirect(_ context.Context, indexID pkgx.IndexID, query string) (string, bool) {
+	query = normalizeQuery(query)
+	now := e.opts.clock.Now()
+	for _, rule := range *e.rules.Load() {
+		if rule.Redirect != "" && rule.matches(indexID, query, now) {
+			return rule.Redirect, true
+		}
+	}
+	return "", false
+}
+
+// Curate returns a copy of the parameters with the actions of all matching rules applied
+func (e *Engine) Curate(
+	_ context.Context,
+	indexID pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*api.SearchCollectionParams, error) {
+	if parameters.Q == nil {
+		return parameters, nil
+	}
+	query := normalizeQuery(*parameters.Q)
+	now := e.opts.clock.Now()
+
+	var params *api.SearchCollectionParams
+	for _, rule := range *e.rules.Load() {
+		if rule.Redirect != "" || !rule.matches(indexID, query, now) {
+			continue
+		}
+		if params == nil {
+			p := *parameters
+			params = &p
+		}
+		e.apply(params, &rule.Rule)
+		e.l.Debug("applied curation rule",
+			zap.String("index", string(indexID)),
+			zap.String("rule", rule.ID),
+			zap.String("query", query),
+		)
+		if rule.Stop {
+			break
+		}
+	}
+	if params == nil {
+		return parameters, nil
+	}
+	return params, nil
+}
+
+// apply merges the actions of the rule into the parameters
+func (e *Engine) apply(params *api.SearchCollectionParams, rule *Rule) {
+	if rule.ReplaceQuery != "" {
+		params.Q = pointer.String(rule.ReplaceQuery)
+	}
+	if rule.FilterBy != "" {
+		filter := rule.FilterBy
+		if params.FilterBy != nil && *params.FilterBy != "" {
+			filter = "(" + *params.FilterBy + ") && (" + filter + ")"
+		}
+		params.FilterBy = pointer.String(filter)
+	}
+	if rule.Boost != "" {
+		sortBy := "_text_match:desc"
+		if params.SortBy != nil && *params.SortBy != "" {
+			sortBy = *params.SortBy
+		}
+		if len(strings.Split(sortBy, ",")) < maxSortCriteria {
+			params.SortBy = pointer.String("_eval(" + rule.Boost + "):desc," + sortBy)
+		} else {
+			e.l.Warn("skipping boost of curation rule, too many sort criteria", zap.String("rule", rule.ID))
+		}
+	}
+	if len(rule.Pin) > 0 {
+		pins := make([]string, len(rule.Pin))
+		for i, pin := range rule.Pin {
+			pins[i] = string(pin.ID) + ":" + strconv.Itoa(pin.Position)
+		}
+		params.PinnedHits = pointer.String(combine(params.PinnedHits, strings.Join(pins, ","), ","))
+	}
+	if len(rule.Hide) > 0 {
+		hidden := make([]string, len(rule.Hide))
+		for i, id := range rule.Hide {
+			hidden[i] = string(id)
+		}
+		params.HiddenHits = pointer.String(combine(params.HiddenHits, strings.Join(hidden, ","), ","))
+	}
+}
+
+// combine appends value to an optional existing value
+func combine(existing *string, value, separator string) string {
+	if existing == nil || *existing == "" {
+		return value
+	}
+	return *existing + separator + value
+}
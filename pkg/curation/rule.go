@@ -0,0 +1,110 @@
+package typesensecuration
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// Rule applies merchandising actions to searches whose query matches.
+// Either Query (exact match after normalization) or Pattern (case-insensitive regular expression) is required.
+type Rule struct {
+	ID string `json:"id" yaml:"id"`
+	// Indices limits the rule to the given indices, empty applies it to all
+	Indices []pkgx.IndexID `json:"indices,omitempty" yaml:"indices,omitempty"`
+	Query   string         `json:"query,omitempty" yaml:"query,omitempty"`
+	Pattern string         `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	// From and Until limit the rule to a campaign period
+	From  *time.Time `json:"from,omitempty" yaml:"from,omitempty"`
+	Until *time.Time `json:"until,omitempty" yaml:"until,omitempty"`
+
+	// FilterBy is combined with the filter of the search
+	FilterBy string `json:"filter_by,omitempty" yaml:"filter_by,omitempty"`
+	// Boost is a filter expression, matching documents are ranked before all others
+	Boost string `json:"boost,omitempty" yaml:"boost,omitempty"`
+	// Pin places documents at fixed 1-based positions
+	Pin []Pin `json:"pin,omitempty" yaml:"pin,omitempty"`
+	// Hide removes documents from the results
+	Hide []pkgx.DocumentID `json:"hide,omitempty" yaml:"hide,omitempty"`
+	// ReplaceQuery searches for another query instead, e.g. to map campaign names to products
+	ReplaceQuery string `json:"replace_query,omitempty" yaml:"replace_query,omitempty"`
+	// Redirect is a URL the frontend should navigate to instead of showing results, e.g. "agb" → "/terms".
+	// Searches matching a redirect rule are not sent to typesense.
+	Redirect string `json:"redirect,omitempty" yaml:"redirect,omitempty"`
+	// Stop skips all following rules if this rule matched
+	Stop bool `json:"stop,omitempty" yaml:"stop,omitempty"`
+}
+
+// Pin places a document at a fixed position of the results
+type Pin struct {
+	ID       pkgx.DocumentID `json:"id" yaml:"id"`
+	Position int             `json:"position" yaml:"position"`
+}
+
+// compiledRule is a validated rule with its compiled pattern
+type compiledRule struct {
+	Rule
+	pattern *regexp.Regexp
+}
+
+// compile validates the rule and compiles its pattern
+func compile(rule Rule) (compiledRule, error) {
+	c := compiledRule{Rule: rule}
+	if rule.ID == "" {
+		return c, errors.New("rule id is required")
+	}
+	switch {
+	case rule.Query != "" && rule.Pattern != "":
+		return c, errors.New("rule " + rule.ID + ": query and pattern are mutually exclusive")
+	case rule.Query != "":
+		c.Query = normalizeQuery(rule.Query)
+	case rule.Pattern != "":
+		pattern, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return c, errors.New("rule " + rule.ID + ": " + err.Error())
+		}
+		c.pattern = pattern
+	default:
+		return c, errors.New("rule " + rule.ID + ": query or pattern is required")
+	}
+	for _, pin := range rule.Pin {
+		if pin.ID == "" || pin.Position < 1 {
+			return c, errors.New("rule " + rule.ID + ": pins require an id and a position starting at 1")
+		}
+	}
+	return c, nil
+}
+
+// matches checks if the rule applies to a search with the normalized query on the index at the given time
+func (r *compiledRule) matches(indexID pkgx.IndexID, query string, now time.Time) bool {
+	if len(r.Indices) > 0 && !containsIndex(r.Indices, indexID) {
+		return false
+	}
+	if r.From != nil && now.Before(*r.From) {
+		return false
+	}
+	if r.Until != nil && !now.Before(*r.Until) {
+		return false
+	}
+	if r.pattern != nil {
+		return r.pattern.MatchString(query)
+	}
+	return r.Query == query
+}
+
+func containsIndex(indices []pkgx.IndexID, indexID pkgx.IndexID) bool {
+	for _, i := range indices {
+		if i == indexID {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeQuery lowercases the query and collapses whitespace
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
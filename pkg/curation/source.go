@@ -0,0 +1,63 @@
+package typesensecuration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Source loads the curation rules, e.g. from a file, a CMS or a database
+type Source interface {
+	Load(ctx context.Context) ([]Rule, error)
+}
+
+// SourceFunc adapts a function to a Source
+type SourceFunc func(ctx context.Context) ([]Rule, error)
+
+func (f SourceFunc) Load(ctx context.Context) ([]Rule, error) {
+	return f(ctx)
+}
+
+// StaticSource provides a fixed set of rules
+type StaticSource []Rule
+
+func (s StaticSource) Load(_ context.Context) ([]Rule, error) {
+	return s, nil
+}
+
+// FileSource reads a list of rules from a JSON or, for ".yaml" and ".yml" files, YAML file
+type FileSource string
+
+func (s FileSource) Load(_ context.Context) ([]Rule, error) {
+	data, err := os.ReadFile(string(s))
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data, filepath.Ext(string(s)))
+}
+
+// Decode parses a list of rules, ext selects the format like a file extension
+func Decode(data []byte, ext string) ([]Rule, error) {
+	var rules []Rule
+	switch ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&rules); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rules); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
@@ -0,0 +1,203 @@
+package typesensededup
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	typesenseindexing "github.com/foomo/typesense/pkg/indexing"
+	"go.uber.org/zap"
+)
+
+// bands splits a hash into 16 bit parts, near duplicates within a distance of less than four bits
+// share at least one identical band
+const bands = 4
+
+type Mode int
+
+const (
+	// ModeFlag keeps near duplicates and reports them through the flag func
+	ModeFlag Mode = iota
+	// ModeDrop removes near duplicates, keeping the first document of every cluster
+	ModeDrop
+)
+
+// Cluster groups the documents that are near duplicates of the canonical document
+type Cluster struct {
+	Canonical  pkgx.DocumentID
+	Duplicates []pkgx.DocumentID
+}
+
+// Report lists the clusters found in the current or last indexer run of an index
+type Report struct {
+	IndexID   pkgx.IndexID
+	Documents int
+	Clusters  []Cluster
+}
+
+// TextFunc returns the text that is compared, e.g. the body without navigation boilerplate
+type TextFunc[indexDocument any] func(document *indexDocument) string
+
+// IDFunc returns the id of the document
+type IDFunc[indexDocument any] func(document *indexDocument) pkgx.DocumentID
+
+// FlagFunc marks a document as near duplicate of the canonical document
+type FlagFunc[indexDocument any] func(document *indexDocument, canonical pkgx.DocumentID)
+
+// Stage detects near duplicate documents by their simhash. Documents are compared with all documents provided for
+// the index in the same indexer run, see typesenseindexing.RunID, and only within the batch without one.
+// Documents without text are passed on as is. Use Process as typesenseindexing.DocumentStageFunc to add it to the
+// provider pipeline.
+type Stage[indexDocument any] struct {
+	l           *zap.Logger
+	textFunc    TextFunc[indexDocument]
+	idFunc      IDFunc[indexDocument]
+	flagFunc    FlagFunc[indexDocument]
+	mode        Mode
+	maxDistance int
+	mu          sync.RWMutex
+	reports     map[pkgx.IndexID]Report
+	runs        map[pkgx.IndexID]*run
+}
+
+type canonical struct {
+	hash    uint64
+	cluster int
+}
+
+// run holds the canonical documents seen in an indexer run of an index
+type run struct {
+	id       string
+	buckets  [bands]map[uint16][]*canonical
+	clusters []Cluster
+	count    int
+}
+
+func newRun(id string) *run {
+	r := &run{id: id}
+	for i := range r.buckets {
+		r.buckets[i] = map[uint16][]*canonical{}
+	}
+	return r
+}
+
+// NewStage creates a dedup stage, maxDistance is capped at 3 bits
+func NewStage[indexDocument any](
+	l *zap.Logger,
+	textFunc TextFunc[indexDocument],
+	idFunc IDFunc[indexDocument],
+	flagFunc FlagFunc[indexDocument],
+	mode Mode,
+	maxDistance int,
+) *Stage[indexDocument] {
+	return &Stage[indexDocument]{
+		l:           l,
+		textFunc:    textFunc,
+		idFunc:      idFunc,
+		flagFunc:    flagFunc,
+		mode:        mode,
+		maxDistance: min(max(maxDistance, 0), bands-1),
+		reports:     map[pkgx.IndexID]Report{},
+		runs:        map[pkgx.IndexID]*run{},
+	}
+}
+
+// Report returns the report of the current or last indexer run of the given index
+func (s *Stage[indexDocument]) Report(indexID pkgx.IndexID) (Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	report, ok := s.reports[indexID]
+	return report, ok
+}
+
+// Process clusters near duplicates and flags or drops them according to the mode
+func (s *Stage[indexDocument]) Process(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) ([]*indexDocument, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runID := typesenseindexing.RunID(ctx)
+	r, ok := s.runs[indexID]
+	if !ok || runID == "" || r.id != runID {
+		r = newRun(runID)
+		s.runs[indexID] = r
+	}
+
+	result := make([]*indexDocument, 0, len(documents))
+	for _, document := range documents {
+		if document == nil {
+			result = append(result, document)
+			continue
+		}
+		text := s.textFunc(document)
+		if strings.TrimSpace(text) == "" {
+			result = append(result, document)
+			continue
+		}
+		r.count++
+		hash := Simhash(text)
+
+		var match *canonical
+		for band := 0; band < bands && match == nil; band++ {
+			for _, candidate := range r.buckets[band][bandValue(hash, band)] {
+				if Distance(hash, candidate.hash) <= s.maxDistance {
+					match = candidate
+					break
+				}
+			}
+		}
+
+		if match == nil {
+			c := &canonical{hash: hash, cluster: len(r.clusters)}
+			r.clusters = append(r.clusters, Cluster{Canonical: s.idFunc(document)})
+			for band := range bands {
+				r.buckets[band][bandValue(hash, band)] = append(r.buckets[band][bandValue(hash, band)], c)
+			}
+			result = append(result, document)
+			continue
+		}
+
+		cluster := &r.clusters[match.cluster]
+		cluster.Duplicates = append(cluster.Duplicates, s.idFunc(document))
+		if s.mode == ModeDrop {
+			continue
+		}
+		if s.flagFunc != nil {
+			s.flagFunc(document, cluster.Canonical)
+		}
+		result = append(result, document)
+	}
+
+	report := Report{
+		IndexID:   indexID,
+		Documents: r.count,
+	}
+	duplicates := 0
+	for _, cluster := range r.clusters {
+		if len(cluster.Duplicates) > 0 {
+			report.Clusters = append(report.Clusters, Cluster{
+				Canonical:  cluster.Canonical,
+				Duplicates: slices.Clone(cluster.Duplicates),
+			})
+			duplicates += len(cluster.Duplicates)
+		}
+	}
+	s.reports[indexID] = report
+
+	pkgx.Logger(ctx, s.l).Info("near duplicate detection completed",
+		zap.String("index", string(indexID)),
+		zap.Int("documents", r.count),
+		zap.Int("clusters", len(report.Clusters)),
+		zap.Int("duplicates", duplicates),
+		zap.Bool("dropped", s.mode == ModeDrop),
+	)
+	return result, nil
+}
+
+func bandValue(hash uint64, band int) uint16 {
+	return uint16(hash >> (uint(band) * 16)) //nolint:gosec // intended truncation
+}
@@ -0,0 +1,124 @@
+package typesensedevseed
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	apix "github.com/foomo/typesense/pkg/api"
+	indexingx "github.com/foomo/typesense/pkg/indexing"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+const (
+	fixtureSuffix = ".jsonl"
+	schemaSuffix  = ".schema.json"
+	// maxLineSize is the maximum size of a single fixture document
+	maxLineSize = 10 * 1024 * 1024
+)
+
+// Seed loads the fixtures of the given directory into typesense using the regular revision pipeline:
+// a new revision is created from the "<index>.schema.json" files, the "<index>.jsonl" files are imported
+// and the revision is committed
+func Seed(ctx context.Context, l *zap.Logger, client *typesense.Client, dir string) error {
+	schemas, err := LoadSchemas(dir)
+	if err != nil {
+		l.Error("failed to load schemas", zap.String("dir", dir), zap.Error(err))
+		return err
+	}
+
+	typesenseAPI := apix.NewBaseAPI[map[string]any, map[string]any](
+		l,
+		client,
+		schemas,
+		nil,
+		func(document map[string]any) map[string]any { return document },
+	)
+	indexer := indexingx.NewBaseIndexer[map[string]any, map[string]any](
+		l,
+		typesenseAPI,
+		NewFileProvider[map[string]any](dir),
+	)
+	return indexer.Run(ctx)
+}
+
+// LoadSchemas reads all "<index>.schema.json" collection schemas of the given directory
+func LoadSchemas(dir string) (map[pkgx.IndexID]*api.CollectionSchema, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+schemaSuffix))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s files found in %s", schemaSuffix, dir)
+	}
+
+	schemas := make(map[pkgx.IndexID]*api.CollectionSchema, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var schema api.CollectionSchema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("invalid schema %s: %w", file, err)
+		}
+		indexID := pkgx.IndexID(strings.TrimSuffix(filepath.Base(file), schemaSuffix))
+		schemas[indexID] = &schema
+	}
+	return schemas, nil
+}
+
+// FileProvider provides the documents of an index from the "<index>.jsonl" fixture file in its directory,
+// one JSON document per line
+type FileProvider[indexDocument any] struct {
+	dir string
+}
+
+func NewFileProvider[indexDocument any](dir string) *FileProvider[indexDocument] {
+	return &FileProvider[indexDocument]{
+		dir: dir,
+	}
+}
+
+func (p *FileProvider[indexDocument]) Provide(_ context.Context, indexID pkgx.IndexID) ([]*indexDocument, error) {
+	file, err := os.Open(filepath.Join(p.dir, string(indexID)+fixtureSuffix))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var documents []*indexDocument
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		data := strings.TrimSpace(scanner.Text())
+		if data == "" {
+			continue
+		}
+		document := new(indexDocument)
+		if err := json.Unmarshal([]byte(data), document); err != nil {
+			return nil, fmt.Errorf("invalid document in %s%s line %d: %w", indexID, fixtureSuffix, line, err)
+		}
+		documents = append(documents, document)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+// ProvidePaged returns all documents with the first page, fixture files are small enough to be read at once
+func (p *FileProvider[indexDocument]) ProvidePaged(ctx context.Context, indexID pkgx.IndexID, offset int) ([]*indexDocument, int, error) {
+	if offset > 0 {
+		return nil, 0, nil
+	}
+	documents, err := p.Provide(ctx, indexID)
+	return documents, 0, err
+}
@@ -0,0 +1,50 @@
+package typesenseembedding
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Embedder turns a batch of texts into vectors, the result has the same order as the given texts
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Cache stores embeddings by the content hash of the embedded text
+type Cache interface {
+	Get(hash string) ([]float32, bool)
+	Set(hash string, embedding []float32)
+}
+
+// MemoryCache is a simple unbounded in-memory Cache
+type MemoryCache struct {
+	mu         sync.RWMutex
+	embeddings map[string][]float32
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		embeddings: map[string][]float32{},
+	}
+}
+
+func (c *MemoryCache) Get(hash string) ([]float32, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	embedding, ok := c.embeddings[hash]
+	return embedding, ok
+}
+
+func (c *MemoryCache) Set(hash string, embedding []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.embeddings[hash] = embedding
+}
+
+// ContentHash returns the cache key for the given text
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
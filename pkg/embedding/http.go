@@ -0,0 +1,68 @@
+package typesenseembedding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// HTTPEmbedder calls a local embedding service that accepts {"texts": [...]}
+// and responds with {"embeddings": [[...], ...]} in the same order
+type HTTPEmbedder struct {
+	client *http.Client
+	url    string
+}
+
+type httpRequest struct {
+	Texts []string `json:"texts"`
+}
+
+type httpResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+func NewHTTPEmbedder(client *http.Client, url string) *HTTPEmbedder {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPEmbedder{
+		client: client,
+		url:    url,
+	}
+}
+
+func (e *HTTPEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(httpRequest{Texts: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embedding request failed with status %d: %s", resp.StatusCode, msg)
+	}
+
+	var response httpResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	if len(response.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(response.Embeddings))
+	}
+	return response.Embeddings, nil
+}
@@ -0,0 +1,175 @@
+package typesenseembedding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+const defaultBatchSize = 64
+
+// TextFunc returns the text to embed for a document, empty texts are skipped
+type TextFunc[indexDocument any] func(document *indexDocument) string
+
+// SetFunc stores the embedding on the document
+type SetFunc[indexDocument any] func(document *indexDocument, embedding []float32)
+
+// StageOption configures the embedding stage
+type StageOption func(o *stageOptions)
+
+type stageOptions struct {
+	batchSize         int
+	requestsPerSecond float64
+	cache             Cache
+}
+
+// WithBatchSize sets the maximum number of texts sent with one embedding request
+func WithBatchSize(batchSize int) StageOption {
+	return func(o *stageOptions) {
+		o.batchSize = batchSize
+	}
+}
+
+// WithRateLimit limits the number of embedding requests per second
+func WithRateLimit(requestsPerSecond float64) StageOption {
+	return func(o *stageOptions) {
+		o.requestsPerSecond = requestsPerSecond
+	}
+}
+
+// WithCache reuses embeddings for texts with an identical content hash
+func WithCache(cache Cache) StageOption {
+	return func(o *stageOptions) {
+		o.cache = cache
+	}
+}
+
+// Stage populates the vector field of documents before they are imported.
+// Use Process as typesenseindexing.DocumentStageFunc to add it to the provider pipeline.
+type Stage[indexDocument any] struct {
+	l        *zap.Logger
+	embedder Embedder
+	textFunc TextFunc[indexDocument]
+	setFunc  SetFunc[indexDocument]
+	opts     stageOptions
+	mu       sync.Mutex
+	lastCall time.Time
+}
+
+func NewStage[indexDocument any](
+	l *zap.Logger,
+	embedder Embedder,
+	textFunc TextFunc[indexDocument],
+	setFunc SetFunc[indexDocument],
+	opts ...StageOption,
+) *Stage[indexDocument] {
+	o := stageOptions{
+		batchSize: defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.batchSize < 1 {
+		o.batchSize = defaultBatchSize
+	}
+	return &Stage[indexDocument]{
+		l:        l,
+		embedder: embedder,
+		textFunc: textFunc,
+		setFunc:  setFunc,
+		opts:     o,
+	}
+}
+
+// Process embeds the texts of all documents in batches and stores the vectors on the documents
+func (s *Stage[indexDocument]) Process(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) ([]*indexDocument, error) {
+	var (
+		pending []*indexDocument
+		texts   []string
+		cached  int
+	)
+
+	for _, document := range documents {
+		if document == nil {
+			continue
+		}
+		text := s.textFunc(document)
+		if text == "" {
+			continue
+		}
+		if s.opts.cache != nil {
+			if embedding, ok := s.opts.cache.Get(ContentHash(text)); ok {
+				s.setFunc(document, embedding)
+				cached++
+				continue
+			}
+		}
+		pending = append(pending, document)
+		texts = append(texts, text)
+	}
+
+	for start := 0; start < len(texts); start += s.opts.batchSize {
+		end := min(start+s.opts.batchSize, len(texts))
+
+		if err := s.wait(ctx); err != nil {
+			return nil, err
+		}
+
+		embeddings, err := s.embedder.Embed(ctx, texts[start:end])
+		if err != nil {
+			s.l.Error("failed to embed documents", zap.String("index", string(indexID)), zap.Error(err))
+			return nil, err
+		}
+		if len(embeddings) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(embeddings))
+		}
+
+		for i, embedding := range embeddings {
+			s.setFunc(pending[start+i], embedding)
+			if s.opts.cache != nil {
+				s.opts.cache.Set(ContentHash(texts[start+i]), embedding)
+			}
+		}
+	}
+
+	s.l.Info("embedded documents",
+		zap.String("index", string(indexID)),
+		zap.Int("embedded", len(texts)),
+		zap.Int("cached", cached),
+	)
+	return documents, nil
+}
+
+// wait blocks until the next request is allowed by the configured rate limit
+func (s *Stage[indexDocument]) wait(ctx context.Context) error {
+	if s.opts.requestsPerSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / s.opts.requestsPerSecond)
+
+	s.mu.Lock()
+	next := s.lastCall.Add(interval)
+	now := time.Now()
+	if next.Before(now) {
+		next = now
+	}
+	s.lastCall = next
+	s.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
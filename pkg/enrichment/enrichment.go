@@ -0,0 +1,343 @@
+package typesenseenrichment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// FailurePolicy defines what happens to documents that could not be enriched
+type FailurePolicy int
+
+const (
+	// FailureKeep keeps the document without the enriched fields
+	FailureKeep FailurePolicy = iota
+	// FailureDrop removes the document from the batch
+	FailureDrop
+	// FailureAbort fails the whole index run
+	FailureAbort
+)
+
+// Request is sent to the enrichment service
+type Request[indexDocument any] struct {
+	IndexID   pkgx.IndexID     `json:"index"`
+	Documents []*indexDocument `json:"documents"`
+}
+
+// Response is expected from the enrichment service with one result per document in request order
+type Response struct {
+	Results []Result `json:"results"`
+}
+
+// Result contains the fields to merge into the document or an error message
+type Result struct {
+	Fields json.RawMessage `json:"fields,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Option configures the enrichment stage
+type Option func(o *options)
+
+type options struct {
+	batchSize     int
+	concurrency   int
+	maxRetries    int
+	retryDelay    time.Duration
+	failurePolicy FailurePolicy
+}
+
+// WithBatchSize sets the number of documents sent with one request
+func WithBatchSize(batchSize int) Option {
+	return func(o *options) {
+		o.batchSize = batchSize
+	}
+}
+
+// WithConcurrency sets the number of parallel requests
+func WithConcurrency(concurrency int) Option {
+	return func(o *options) {
+		o.concurrency = concurrency
+	}
+}
+
+// WithRetries retries requests failing with network errors or 5xx responses with a linearly increasing delay
+func WithRetries(maxRetries int, delay time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.retryDelay = delay
+	}
+}
+
+// WithFailurePolicy sets the handling of documents that could not be enriched
+func WithFailurePolicy(policy FailurePolicy) Option {
+	return func(o *options) {
+		o.failurePolicy = policy
+	}
+}
+
+// Stage posts document batches to an enrichment service and merges the returned fields into the documents.
+// Use Process as typesenseindexing.DocumentStageFunc to add it between Provide and Upsert.
+type Stage[indexDocument any] struct {
+	l      *zap.Logger
+	client *http.Client
+	url    string
+	opts   options
+}
+
+func NewStage[indexDocument any](
+	l *zap.Logger,
+	client *http.Client,
+	url string,
+	opts ...Option,
+) *Stage[indexDocument] {
+	o := options{
+		batchSize:   100,
+		concurrency: 1,
+		retryDelay:  time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.batchSize = max(o.batchSize, 1)
+	o.concurrency = max(o.concurrency, 1)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Stage[indexDocument]{
+		l:      l,
+		client: client,
+		url:    url,
+		opts:   o,
+	}
+}
+
+// Process enriches all documents in concurrent batches
+func (s *Stage[indexDocument]) Process(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) ([]*indexDocument, error) {
+	pending := make([]*indexDocument, 0, len(documents))
+	for _, document := range documents {
+		if document != nil {
+			pending = append(pending, document)
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		errs    []error
+		dropped = map[*indexDocument]bool{}
+		sem     = make(chan struct{}, s.opts.concurrency)
+	)
+
+	for start := 0; start < len(pending); start += s.opts.batchSize {
+		batch := pending[start:min(start+s.opts.batchSize, len(pending))]
+
+		select {
+		case <-ctx.Done():
+			// running batches still write into the documents
+			wg.Wait()
+			return nil, ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			failed, err := s.enrichBatch(ctx, indexID, batch)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for _, document := range failed {
+				dropped[document] = true
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if len(dropped) == 0 {
+		return documents, nil
+	}
+	result := make([]*indexDocument, 0, len(documents)-len(dropped))
+	for _, document := range documents {
+		if !dropped[document] {
+			result = append(result, document)
+		}
+	}
+	s.l.Warn("dropped documents that could not be enriched",
+		zap.String("index", string(indexID)),
+		zap.Int("dropped", len(dropped)),
+	)
+	return result, nil
+}
+
+// enrichBatch returns the documents to drop according to the failure policy
+func (s *Stage[indexDocument]) enrichBatch(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	batch []*indexDocument,
+) ([]*indexDocument, error) {
+	response, err := s.requestWithRetries(ctx, indexID, batch)
+	if err == nil && len(response.Results) != len(batch) {
+		err = fmt.Errorf("expected %d enrichment results, got %d", len(batch), len(response.Results))
+	}
+	if err != nil {
+		s.l.Error("failed to enrich batch", zap.String("index", string(indexID)), zap.Int("documents", len(batch)), zap.Error(err))
+		return s.fail(batch, err)
+	}
+
+	var failed []*indexDocument
+	for i, result := range response.Results {
+		var resultErr error
+		if result.Error != "" {
+			resultErr = errors.New(result.Error)
+		} else if len(result.Fields) > 0 {
+			resultErr = merge(batch[i], result.Fields)
+		}
+		if resultErr != nil {
+			s.l.Warn("failed to enrich document", zap.String("index", string(indexID)), zap.Error(resultErr))
+			dropped, err := s.fail(batch[i:i+1], resultErr)
+			if err != nil {
+				return nil, err
+			}
+			failed = append(failed, dropped...)
+		}
+	}
+	return failed, nil
+}
+
+// merge merges the fields into a copy of the document first, so it is left untouched if they don't match
+func merge[indexDocument any](document *indexDocument, fields json.RawMessage) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	merged := new(indexDocument)
+	if err := json.Unmarshal(data, merged); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(fields, merged); err != nil {
+		return err
+	}
+	*document = *merged
+	return nil
+}
+
+func (s *Stage[indexDocument]) fail(documents []*indexDocument, err error) ([]*indexDocument, error) {
+	switch s.opts.failurePolicy {
+	case FailureDrop:
+		return documents, nil
+	case FailureAbort:
+		return nil, err
+	case FailureKeep:
+		return nil, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (s *Stage[indexDocument]) requestWithRetries(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	batch []*indexDocument,
+) (*Response, error) {
+	var err error
+	for attempt := 0; attempt <= s.opts.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * s.opts.retryDelay):
+			}
+		}
+		var response *Response
+		if response, err = s.request(ctx, indexID, batch); err == nil {
+			return response, nil
+		}
+		s.l.Debug("enrichment request failed", zap.Int("attempt", attempt+1), zap.Error(err))
+		if !retryable(ctx, err) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
+
+// statusError is returned for responses with an unexpected status code
+type statusError struct {
+	code int
+	msg  []byte
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("enrichment request failed with status %d: %s", e.code, e.msg)
+}
+
+// retryable checks if the request failed with a network error or a 5xx response, other failures would fail again
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func (s *Stage[indexDocument]) request(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	batch []*indexDocument,
+) (*Response, error) {
+	body, err := json.Marshal(Request[indexDocument]{
+		IndexID:   indexID,
+		Documents: batch,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, &statusError{code: resp.StatusCode, msg: msg}
+	}
+
+	var response Response
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
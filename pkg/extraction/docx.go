@@ -0,0 +1,71 @@
+package typesenseextraction
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// DOCXExtractor extracts the paragraph text of word documents without external dependencies
+func DOCXExtractor() Extractor {
+	return ExtractorFunc(func(_ context.Context, _ string, r io.Reader) (string, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		archive, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+		if err != nil {
+			return "", err
+		}
+		for _, file := range archive.File {
+			if file.Name == "word/document.xml" {
+				return extractDocumentXML(file)
+			}
+		}
+		return "", errors.New("word/document.xml not found")
+	})
+}
+
+func extractDocumentXML(file *zip.File) (string, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var sb strings.Builder
+	inText := false
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return strings.TrimSpace(sb.String()), nil
+		} else if err != nil {
+			return "", err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab", "br":
+				sb.WriteByte(' ')
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteByte('\n')
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package typesenseextraction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Mime types of common office documents
+const (
+	MimeTypePDF  = "application/pdf"
+	MimeTypeDOCX = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MimeTypeText = "text/plain"
+)
+
+// ErrUnsupportedMimeType is returned by extractors that can't handle the given document
+var ErrUnsupportedMimeType = errors.New("unsupported mime type")
+
+// Extractor turns a binary document into searchable plain text
+type Extractor interface {
+	Extract(ctx context.Context, mimeType string, r io.Reader) (string, error)
+}
+
+// ExtractorFunc allows plugging local extraction libraries as Extractor
+type ExtractorFunc func(ctx context.Context, mimeType string, r io.Reader) (string, error)
+
+func (f ExtractorFunc) Extract(ctx context.Context, mimeType string, r io.Reader) (string, error) {
+	return f(ctx, mimeType, r)
+}
+
+// MimeTypeExtractor routes documents to extractors by their mime type
+type MimeTypeExtractor map[string]Extractor
+
+func (m MimeTypeExtractor) Extract(ctx context.Context, mimeType string, r io.Reader) (string, error) {
+	extractor, ok := m[baseMimeType(mimeType)]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedMimeType, mimeType)
+	}
+	return extractor.Extract(ctx, mimeType, r)
+}
+
+// PlainTextExtractor returns text documents as they are
+func PlainTextExtractor() Extractor {
+	return ExtractorFunc(func(_ context.Context, _ string, r io.Reader) (string, error) {
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	})
+}
+
+// baseMimeType strips parameters like the charset from the given mime type
+func baseMimeType(mimeType string) string {
+	if mediaType, _, err := mime.ParseMediaType(mimeType); err == nil {
+		return mediaType
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
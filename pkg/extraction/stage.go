@@ -0,0 +1,184 @@
+package typesenseextraction
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+const defaultMaxBytes = 50 << 20
+
+// ErrAssetTooLarge is returned for assets exceeding the maximum size of the stage, they are skipped like failures
+var ErrAssetTooLarge = errors.New("asset too large")
+
+// Fetcher loads the binary asset referenced by a document
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (io.ReadCloser, string, error)
+}
+
+// HTTPFetcher downloads assets, relative urls are resolved against the base url
+type HTTPFetcher struct {
+	client  *http.Client
+	baseURL string
+}
+
+func NewHTTPFetcher(client *http.Client, baseURL string) *HTTPFetcher {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &HTTPFetcher{
+		client:  client,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Fetch returns the asset body and its mime type, guessed from the file extension if the server doesn't send one
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (io.ReadCloser, string, error) {
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = f.baseURL + "/" + strings.TrimPrefix(url, "/")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := f.client.Do(req) //nolint:bodyclose // closed by the caller
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, "", fmt.Errorf("failed to fetch %s: status %d", url, resp.StatusCode)
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if mimeType == "" || baseMimeType(mimeType) == "application/octet-stream" {
+		if byExtension := mime.TypeByExtension(path.Ext(req.URL.Path)); byExtension != "" {
+			mimeType = byExtension
+		}
+	}
+	return resp.Body, mimeType, nil
+}
+
+// AssetFunc returns the url of the asset to extract for a document, documents without asset return an empty string
+type AssetFunc[indexDocument any] func(document *indexDocument) string
+
+// SetFunc stores the extracted text on the document
+type SetFunc[indexDocument any] func(document *indexDocument, text string)
+
+// Stage fetches the assets referenced by documents and stores their text content.
+// Use Process as typesenseindexing.DocumentStageFunc to add it to the provider pipeline.
+type Stage[indexDocument any] struct {
+	l         *zap.Logger
+	fetcher   Fetcher
+	extractor Extractor
+	assetFunc AssetFunc[indexDocument]
+	setFunc   SetFunc[indexDocument]
+	maxBytes  int64
+}
+
+func NewStage[indexDocument any](
+	l *zap.Logger,
+	fetcher Fetcher,
+	extractor Extractor,
+	assetFunc AssetFunc[indexDocument],
+	setFunc SetFunc[indexDocument],
+	maxBytes int64,
+) *Stage[indexDocument] {
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxBytes
+	}
+	return &Stage[indexDocument]{
+		l:         l,
+		fetcher:   fetcher,
+		extractor: extractor,
+		assetFunc: assetFunc,
+		setFunc:   setFunc,
+		maxBytes:  maxBytes,
+	}
+}
+
+// Process extracts the text of every referenced asset, failing assets and assets exceeding the maximum size are
+// logged and skipped
+func (s *Stage[indexDocument]) Process(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) ([]*indexDocument, error) {
+	extracted, failed := 0, 0
+	for _, document := range documents {
+		if document == nil {
+			continue
+		}
+		url := s.assetFunc(document)
+		if url == "" {
+			continue
+		}
+		text, err := s.extract(ctx, url)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			failed++
+			s.l.Warn("failed to extract asset text",
+				zap.String("index", string(indexID)),
+				zap.String("url", url),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.setFunc(document, text)
+		extracted++
+	}
+
+	s.l.Info("extracted asset texts",
+		zap.String("index", string(indexID)),
+		zap.Int("extracted", extracted),
+		zap.Int("failed", failed),
+	)
+	return documents, nil
+}
+
+func (s *Stage[indexDocument]) extract(ctx context.Context, url string) (string, error) {
+	body, mimeType, err := s.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	limited := &limitedReader{r: body, remaining: s.maxBytes}
+	text, err := s.extractor.Extract(ctx, mimeType, limited)
+	if limited.exceeded {
+		return "", fmt.Errorf("%w: more than %d bytes", ErrAssetTooLarge, s.maxBytes)
+	}
+	return text, err
+}
+
+// limitedReader reads up to one byte beyond the limit to tell truncated assets from assets of the exact size
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	exceeded  bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrAssetTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.remaining {
+		l.exceeded = true
+		return int(l.remaining), ErrAssetTooLarge
+	}
+	l.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,57 @@
+package typesenseextraction
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// TikaExtractor extracts text through the /tika endpoint of an Apache Tika server
+type TikaExtractor struct {
+	client *http.Client
+	url    string
+}
+
+// NewTikaExtractor creates an extractor for the given tika server url, e.g. "http://tika:9998"
+func NewTikaExtractor(client *http.Client, url string) *TikaExtractor {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &TikaExtractor{
+		client: client,
+		url:    strings.TrimSuffix(url, "/"),
+	}
+}
+
+func (e *TikaExtractor) Extract(ctx context.Context, mimeType string, r io.Reader) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, e.url+"/tika", r)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/plain")
+	if mimeType != "" {
+		req.Header.Set("Content-Type", mimeType)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnsupportedMediaType:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedMimeType, mimeType)
+	default:
+		return "", fmt.Errorf("tika extraction failed with status %d", resp.StatusCode)
+	}
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	return string(text), nil
+}
@@ -0,0 +1,188 @@
+package typesensefailover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/sony/gobreaker"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+var _ pkgx.API[any, any] = (*API[any, any])(nil)
+
+// searchFunc runs a search on the API of a cluster
+type searchFunc[indexDocument any, returnType any] func(
+	ctx context.Context,
+	cluster pkgx.API[indexDocument, returnType],
+) (*pkgx.SearchResult[returnType], error)
+
+// API decorates the API of the primary cluster with a read-path failover: searches that fail or exceed the latency
+// budget on the primary are retried on the secondary cluster, e.g. a replica in another region. Indexing and all
+// other operations are passed to the primary. Only timeouts, network errors and 5xx responses are retried, other
+// errors, e.g. invalid parameters or unknown indices, would fail on the secondary as well.
+type API[indexDocument any, returnType any] struct {
+	pkgx.API[indexDocument, returnType]
+	l         *zap.Logger
+	secondary pkgx.API[indexDocument, returnType]
+	opts      options
+	metrics   *metrics
+}
+
+func NewAPI[indexDocument any, returnType any](
+	l *zap.Logger,
+	primary pkgx.API[indexDocument, returnType],
+	secondary pkgx.API[indexDocument, returnType],
+	opts ...Option,
+) *API[indexDocument, returnType] {
+	o := newOptions(opts...)
+	return &API[indexDocument, returnType]{
+		API:       primary,
+		l:         l,
+		secondary: secondary,
+		opts:      o,
+		metrics:   newMetrics(o.registerer),
+	}
+}
+
+// DeployCanary forwards to the decorated API, see pkgx.CanaryDeployer
+func (a *API[indexDocument, returnType]) DeployCanary(ctx context.Context, revisionID pkgx.RevisionID) error {
+	deployer, ok := a.API.(pkgx.CanaryDeployer)
+	if !ok {
+		return fmt.Errorf("%w: canary aliases", pkgx.ErrUnsupported)
+	}
+	return deployer.DeployCanary(ctx, revisionID)
+}
+
+// SampleRevision forwards to the decorated API, see pkgx.RevisionSampler
+func (a *API[indexDocument, returnType]) SampleRevision(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	n int,
+) ([]returnType, error) {
+	sampler, ok := a.API.(pkgx.RevisionSampler[returnType])
+	if !ok {
+		return nil, fmt.Errorf("%w: revision sampling", pkgx.ErrUnsupported)
+	}
+	return sampler.SampleRevision(ctx, revisionID, indexID, n)
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) ([]returnType, pkgx.Scores, int, error) {
+	result, err := a.SimpleSearchResult(ctx, index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) ([]returnType, pkgx.Scores, int, error) {
+	result, err := a.ExpertSearchResult(ctx, index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*pkgx.SearchResult[returnType], error) {
+	return a.search(ctx, index, func(ctx context.Context, cluster pkgx.API[indexDocument, returnType]) (*pkgx.SearchResult[returnType], error) {
+		return cluster.SimpleSearchResult(ctx, index, parameters)
+	})
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*pkgx.SearchResult[returnType], error) {
+	return a.search(ctx, index, func(ctx context.Context, cluster pkgx.API[indexDocument, returnType]) (*pkgx.SearchResult[returnType], error) {
+		return cluster.ExpertSearchResult(ctx, index, parameters)
+	})
+}
+
+// search runs the search on the primary cluster and retries it on the secondary if it fails or times out
+func (a *API[indexDocument, returnType]) search(
+	ctx context.Context,
+	index pkgx.IndexID,
+	fetch searchFunc[indexDocument, returnType],
+) (*pkgx.SearchResult[returnType], error) {
+	primaryCtx := ctx
+	if a.opts.latencyBudget > 0 {
+		var cancel context.CancelFunc
+		primaryCtx, cancel = context.WithTimeout(ctx, a.opts.latencyBudget)
+		defer cancel()
+	}
+
+	result, err := a.observe(primaryCtx, index, clusterPrimary, a.API, fetch)
+	if err == nil || ctx.Err() != nil {
+		return result, err
+	}
+	timedOut := primaryCtx.Err() != nil
+	if !timedOut && !isTransient(err) {
+		return result, err
+	}
+
+	reason := reasonError
+	if timedOut {
+		reason = reasonTimeout
+	}
+	a.metrics.failovers.WithLabelValues(string(index), reason).Inc()
+	pkgx.Logger(ctx, a.l).Warn("search failed on primary cluster, failing over to secondary",
+		zap.String("index", string(index)),
+		zap.String("reason", reason),
+		zap.Error(err),
+	)
+	return a.observe(ctx, index, clusterSecondary, a.secondary, fetch)
+}
+
+// observe runs the search on the cluster and records its metrics
+func (a *API[indexDocument, returnType]) observe(
+	ctx context.Context,
+	index pkgx.IndexID,
+	cluster string,
+	clusterAPI pkgx.API[indexDocument, returnType],
+	fetch searchFunc[indexDocument, returnType],
+) (*pkgx.SearchResult[returnType], error) {
+	start := time.Now()
+	result, err := fetch(ctx, clusterAPI)
+	a.metrics.duration.WithLabelValues(string(index), cluster).Observe(time.Since(start).Seconds())
+	status := "success"
+	if err != nil {
+		status = "error"
+	}
+	a.metrics.searches.WithLabelValues(string(index), cluster, status).Inc()
+	return result, err
+}
+
+// isTransient checks if the search failed with a network error, an open circuit breaker or a 5xx, timeout or rate
+// limit response, other failures would fail on another cluster as well
+func isTransient(err error) bool {
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return true
+	}
+	var httpErr *typesense.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Status >= http.StatusInternalServerError ||
+			httpErr.Status == http.StatusRequestTimeout || httpErr.Status == http.StatusTooManyRequests
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
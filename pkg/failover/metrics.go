@@ -0,0 +1,64 @@
+package typesensefailover
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	clusterPrimary   = "primary"
+	clusterSecondary = "secondary"
+
+	reasonError   = "error"
+	reasonTimeout = "timeout"
+)
+
+type metrics struct {
+	searches  *prometheus.CounterVec
+	failovers *prometheus.CounterVec
+	duration  *prometheus.HistogramVec
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		searches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "typesense",
+			Subsystem: "failover",
+			Name:      "searches_total",
+			Help:      "Number of searches by index, serving cluster (primary, secondary) and result (success, error)",
+		}, []string{"index", "cluster", "result"}),
+		failovers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "typesense",
+			Subsystem: "failover",
+			Name:      "failovers_total",
+			Help:      "Number of searches retried on the secondary cluster by index and reason (error, timeout)",
+		}, []string{"index", "reason"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "typesense",
+			Subsystem: "failover",
+			Name:      "search_duration_seconds",
+			Help:      "Duration of searches by index and cluster",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"index", "cluster"}),
+	}
+	if registerer != nil {
+		m.searches = register(registerer, m.searches)
+		m.failovers = register(registerer, m.failovers)
+		m.duration = register(registerer, m.duration)
+	}
+	return m
+}
+
+// register registers the collector or returns the already registered one, e.g. when a failover is recreated
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+	}
+	return collector
+}
@@ -0,0 +1,42 @@
+package typesensefailover
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option configures the failover
+type Option func(o *options)
+
+type options struct {
+	latencyBudget time.Duration
+	registerer    prometheus.Registerer
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		registerer: prometheus.DefaultRegisterer,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithLatencyBudget cancels searches on the primary cluster exceeding the budget and retries them on the secondary,
+// 0 waits for the primary
+func WithLatencyBudget(budget time.Duration) Option {
+	return func(o *options) {
+		o.latencyBudget = budget
+	}
+}
+
+// WithRegisterer registers the failover metrics with the given registerer, nil disables the registration
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
@@ -0,0 +1,176 @@
+package typesensefaults
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+// ErrInjected is returned by all calls that fail on purpose
+var ErrInjected = errors.New("injected fault")
+
+var _ pkgx.API[any, any] = (*API[any, any])(nil)
+
+// Config defines which faults are injected, rates are between 0 and 1
+type Config struct {
+	// ImportFailureRate is the share of UpsertDocuments calls that fail
+	ImportFailureRate float64
+	// SearchFailureRate is the share of searches that fail
+	SearchFailureRate float64
+	// SearchDelay is added to every search
+	SearchDelay time.Duration
+	// DropCommits makes CommitRevision succeed without committing, so aliases keep pointing to the old revision
+	DropCommits bool
+	// CommitFailureRate is the share of CommitRevision calls that fail
+	CommitFailureRate float64
+	Seed              uint64
+}
+
+// API decorates an API with randomly injected failures and delays.
+// It is meant for tests and staging environments to verify that revert, retry and alerting paths work.
+type API[indexDocument any, returnType any] struct {
+	pkgx.API[indexDocument, returnType]
+	l      *zap.Logger
+	config Config
+	mu     sync.Mutex
+	rng    *rand.Rand
+}
+
+func NewAPI[indexDocument any, returnType any](
+	l *zap.Logger,
+	api pkgx.API[indexDocument, returnType],
+	config Config,
+) *API[indexDocument, returnType] {
+	return &API[indexDocument, returnType]{
+		API:    api,
+		l:      l,
+		config: config,
+		rng:    rand.New(rand.NewPCG(config.Seed, config.Seed)), //nolint:gosec // fault injection does not need a secure source
+	}
+}
+
+// DeployCanary forwards to the decorated API, see pkgx.CanaryDeployer
+func (a *API[indexDocument, returnType]) DeployCanary(ctx context.Context, revisionID pkgx.RevisionID) error {
+	deployer, ok := a.API.(pkgx.CanaryDeployer)
+	if !ok {
+		return fmt.Errorf("%w: canary aliases", pkgx.ErrUnsupported)
+	}
+	return deployer.DeployCanary(ctx, revisionID)
+}
+
+// SampleRevision forwards to the decorated API, see pkgx.RevisionSampler
+func (a *API[indexDocument, returnType]) SampleRevision(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	n int,
+) ([]returnType, error) {
+	sampler, ok := a.API.(pkgx.RevisionSampler[returnType])
+	if !ok {
+		return nil, fmt.Errorf("%w: revision sampling", pkgx.ErrUnsupported)
+	}
+	return sampler.SampleRevision(ctx, revisionID, indexID, n)
+}
+
+func (a *API[indexDocument, returnType]) UpsertDocuments(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) error {
+	if a.fail(a.config.ImportFailureRate) {
+		a.l.Warn("injecting import failure", zap.String("index", string(indexID)), zap.Int("documents", len(documents)))
+		return ErrInjected
+	}
+	return a.API.UpsertDocuments(ctx, revisionID, indexID, documents)
+}
+
+func (a *API[indexDocument, returnType]) CommitRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	if a.config.DropCommits {
+		a.l.Warn("dropping commit", zap.String("revisionID", string(revisionID)))
+		return nil
+	}
+	if a.fail(a.config.CommitFailureRate) {
+		a.l.Warn("injecting commit failure", zap.String("revisionID", string(revisionID)))
+		return ErrInjected
+	}
+	return a.API.CommitRevision(ctx, revisionID)
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) ([]returnType, pkgx.Scores, int, error) {
+	if err := a.searchFault(ctx, index); err != nil {
+		return nil, nil, 0, err
+	}
+	return a.API.SimpleSearch(ctx, index, parameters)
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) ([]returnType, pkgx.Scores, int, error) {
+	if err := a.searchFault(ctx, index); err != nil {
+		return nil, nil, 0, err
+	}
+	return a.API.ExpertSearch(ctx, index, parameters)
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*pkgx.SearchResult[returnType], error) {
+	if err := a.searchFault(ctx, index); err != nil {
+		return nil, err
+	}
+	return a.API.SimpleSearchResult(ctx, index, parameters)
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*pkgx.SearchResult[returnType], error) {
+	if err := a.searchFault(ctx, index); err != nil {
+		return nil, err
+	}
+	return a.API.ExpertSearchResult(ctx, index, parameters)
+}
+
+// searchFault delays the search and decides if it fails
+func (a *API[indexDocument, returnType]) searchFault(ctx context.Context, index pkgx.IndexID) error {
+	if a.config.SearchDelay > 0 {
+		timer := time.NewTimer(a.config.SearchDelay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	if a.fail(a.config.SearchFailureRate) {
+		a.l.Warn("injecting search failure", zap.String("index", string(index)))
+		return ErrInjected
+	}
+	return nil
+}
+
+func (a *API[indexDocument, returnType]) fail(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.rng.Float64() < rate
+}
@@ -0,0 +1,184 @@
+package typesense
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// matchNothing is a filter expression no document matches
+const matchNothing = "(id:=`-` && id:!=`-`)"
+
+// FilterBuilder composes a filter_by expression from typed conditions, values are quoted so commas, colons and
+// brackets in them are matched literally. Backticks can't be escaped in typesense and are removed from values.
+// The conditions of a builder are combined with &&.
+type FilterBuilder struct {
+	conditions []string
+}
+
+// NewFilterBuilder creates an empty filter builder
+func NewFilterBuilder() *FilterBuilder {
+	return &FilterBuilder{}
+}
+
+// Eq matches documents whose field equals the value
+func (b *FilterBuilder) Eq(field string, value any) *FilterBuilder {
+	return b.add(field + ":=" + filterValue(value))
+}
+
+// NotEq matches documents whose field does not equal the value
+func (b *FilterBuilder) NotEq(field string, value any) *FilterBuilder {
+	return b.add(field + ":!=" + filterValue(value))
+}
+
+// In matches documents whose field equals any of the values. Typesense rejects empty lists, without values
+// the condition is a contradiction on the id field that matches no documents.
+func (b *FilterBuilder) In(field string, values ...any) *FilterBuilder {
+	if len(values) == 0 {
+		return b.add(matchNothing)
+	}
+	return b.add(field + ":=" + filterValues(values))
+}
+
+// NotIn matches documents whose field equals none of the values
+func (b *FilterBuilder) NotIn(field string, values ...any) *FilterBuilder {
+	if len(values) == 0 {
+		return b
+	}
+	return b.add(field + ":!=" + filterValues(values))
+}
+
+// Range matches documents whose numeric field is between minValue and maxValue, both inclusive
+func (b *FilterBuilder) Range(field string, minValue, maxValue any) *FilterBuilder {
+	return b.add(field + ":[" + filterValue(minValue) + ".." + filterValue(maxValue) + "]")
+}
+
+// Gt matches documents whose numeric field is greater than the value
+func (b *FilterBuilder) Gt(field string, value any) *FilterBuilder {
+	return b.add(field + ":>" + filterValue(value))
+}
+
+// Gte matches documents whose numeric field is greater than or equal to the value
+func (b *FilterBuilder) Gte(field string, value any) *FilterBuilder {
+	return b.add(field + ":>=" + filterValue(value))
+}
+
+// Lt matches documents whose numeric field is less than the value
+func (b *FilterBuilder) Lt(field string, value any) *FilterBuilder {
+	return b.add(field + ":<" + filterValue(value))
+}
+
+// Lte matches documents whose numeric field is less than or equal to the value
+func (b *FilterBuilder) Lte(field string, value any) *FilterBuilder {
+	return b.add(field + ":<=" + filterValue(value))
+}
+
+// Geo matches documents whose geopoint field is within the radius in kilometers around the coordinates
+func (b *FilterBuilder) Geo(field string, lat, lng, radiusKm float64) *FilterBuilder {
+	return b.add(fmt.Sprintf("%s:(%s, %s, %s km)", field, formatFloat(lat), formatFloat(lng), formatFloat(radiusKm)))
+}
+
+// And adds the conditions of the filters as a group, e.g. to nest them in Or
+func (b *FilterBuilder) And(filters ...*FilterBuilder) *FilterBuilder {
+	return b.group(" && ", filters)
+}
+
+// Or matches documents matching any of the filters
+func (b *FilterBuilder) Or(filters ...*FilterBuilder) *FilterBuilder {
+	return b.group(" || ", filters)
+}
+
+// Not matches documents not matching the filter, it requires typesense 28 or later
+func (b *FilterBuilder) Not(filter *FilterBuilder) *FilterBuilder {
+	if expression := filter.Build(); expression != "" {
+		b.add("!(" + expression + ")")
+	}
+	return b
+}
+
+// Raw adds a handwritten filter expression, e.g. for syntax not covered by the builder
+func (b *FilterBuilder) Raw(expression string) *FilterBuilder {
+	if expression != "" {
+		b.add("(" + expression + ")")
+	}
+	return b
+}
+
+// Build returns the filter_by expression, "" if the builder has no conditions
+func (b *FilterBuilder) Build() string {
+	if b == nil {
+		return ""
+	}
+	return strings.Join(b.conditions, " && ")
+}
+
+// String returns the filter_by expression
+func (b *FilterBuilder) String() string {
+	return b.Build()
+}
+
+func (b *FilterBuilder) add(condition string) *FilterBuilder {
+	b.conditions = append(b.conditions, condition)
+	return b
+}
+
+// group adds the non-empty filters joined by the operator in parentheses
+func (b *FilterBuilder) group(operator string, filters []*FilterBuilder) *FilterBuilder {
+	expressions := make([]string, 0, len(filters))
+	for _, filter := range filters {
+		if expression := filter.Build(); expression != "" {
+			expressions = append(expressions, "("+expression+")")
+		}
+	}
+	switch len(expressions) {
+	case 0:
+		return b
+	case 1:
+		return b.add(expressions[0])
+	default:
+		return b.add("(" + strings.Join(expressions, operator) + ")")
+	}
+}
+
+// filterValues formats the values as a list
+func filterValues(values []any) string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = filterValue(value)
+	}
+	return "[" + strings.Join(formatted, ",") + "]"
+}
+
+// filterValue formats numbers and booleans as is and quotes all other values with backticks,
+// types implementing fmt.Stringer are quoted even if they are numeric
+func filterValue(value any) string {
+	if v, ok := value.(fmt.Stringer); ok {
+		return quoteFilterValue(v.String())
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(rv.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, 32)
+	case reflect.Float64:
+		return formatFloat(rv.Float())
+	case reflect.String:
+		return quoteFilterValue(rv.String())
+	default:
+		return quoteFilterValue(fmt.Sprint(value))
+	}
+}
+
+func quoteFilterValue(value string) string {
+	return "`" + strings.ReplaceAll(value, "`", "") + "`"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,232 @@
+package typesensefreshness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// ErrStale is reported by Healthz while an index exceeds its freshness SLO
+var ErrStale = errors.New("index is stale")
+
+// IndexFreshness describes when an index was last updated
+type IndexFreshness struct {
+	IndexID pkgx.IndexID `json:"index"`
+	// LastCommit is the time of the last committed revision, zero if none was committed since the start
+	LastCommit time.Time `json:"last_commit"`
+	// LastUpdate is the time of the last committed revision or successful delta update, e.g. ReindexScope
+	LastUpdate time.Time     `json:"last_update"`
+	Age        time.Duration `json:"age"`
+	SLO        time.Duration `json:"slo"`
+	Stale      bool          `json:"stale"`
+}
+
+// Tracker tracks the freshness of indices against an SLO. Register it as indexer extension to record commits and
+// delta updates of the indexer process. Indices count as updated when the tracker is created, so the SLO applies
+// from the start on. Other processes only see the commits of the cluster with WithRevisionLister, delta updates
+// are not visible to them. It implements the keel healthz interface, e.g. to flip the readiness of the search
+// service configured with WithRevisionLister:
+//
+//	svr.AddReadinessHealthzers(tracker)
+type Tracker struct {
+	l       *zap.Logger
+	slo     time.Duration
+	opts    options
+	metrics *metrics
+	started time.Time
+	mu      sync.Mutex
+	indices map[pkgx.IndexID]*IndexFreshness
+}
+
+func NewTracker(
+	l *zap.Logger,
+	indices []pkgx.IndexID,
+	slo time.Duration,
+	opts ...Option,
+) *Tracker {
+	o := newOptions(opts...)
+	t := &Tracker{
+		l:       l,
+		slo:     slo,
+		opts:    o,
+		metrics: newMetrics(o.registerer),
+		started: o.clock.Now(),
+		indices: make(map[pkgx.IndexID]*IndexFreshness, len(indices)),
+	}
+	for _, indexID := range indices {
+		t.indices[indexID] = &IndexFreshness{IndexID: indexID, SLO: t.sloOf(indexID)}
+	}
+	return t
+}
+
+// AfterCommit records the commit of the indices
+func (t *Tracker) AfterCommit(_ context.Context, _ pkgx.RevisionID, indices []pkgx.IndexID) error {
+	now := t.opts.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, indexID := range indices {
+		freshness := t.index(indexID)
+		freshness.LastCommit = now
+		freshness.LastUpdate = now
+		t.metrics.lastCommit.WithLabelValues(string(indexID)).Set(float64(now.Unix()))
+		t.metrics.lastUpdate.WithLabelValues(string(indexID)).Set(float64(now.Unix()))
+	}
+	return nil
+}
+
+// AfterUpdate records a successful delta update of the index
+func (t *Tracker) AfterUpdate(_ context.Context, indexID pkgx.IndexID) error {
+	now := t.opts.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.index(indexID).LastUpdate = now
+	t.metrics.lastUpdate.WithLabelValues(string(indexID)).Set(float64(now.Unix()))
+	return nil
+}
+
+// Check evaluates the freshness of all indices and invokes the callback for indices that changed their state
+func (t *Tracker) Check(ctx context.Context) []IndexFreshness {
+	t.syncRevisions(ctx)
+	now := t.opts.clock.Now()
+	var changed []IndexFreshness
+	t.mu.Lock()
+	for indexID, freshness := range t.indices {
+		lastUpdate := freshness.LastUpdate
+		if lastUpdate.IsZero() {
+			lastUpdate = t.started
+		}
+		freshness.Age = now.Sub(lastUpdate)
+		stale := freshness.SLO > 0 && freshness.Age > freshness.SLO
+		if stale != freshness.Stale {
+			freshness.Stale = stale
+			changed = append(changed, *freshness)
+		}
+		value := 0.0
+		if stale {
+			value = 1
+		}
+		t.metrics.stale.WithLabelValues(string(indexID)).Set(value)
+	}
+	t.mu.Unlock()
+
+	l := pkgx.Logger(ctx, t.l)
+	for _, freshness := range changed {
+		if freshness.Stale {
+			l.Warn("index exceeds freshness slo",
+				zap.String("index", string(freshness.IndexID)),
+				zap.Duration("age", freshness.Age),
+				zap.Duration("slo", freshness.SLO),
+			)
+		} else {
+			l.Info("index is fresh again", zap.String("index", string(freshness.IndexID)))
+		}
+		if t.opts.callback != nil {
+			t.opts.callback(ctx, freshness)
+		}
+	}
+	return t.Status()
+}
+
+// syncRevisions records the creation of the collections served by the aliases as commits
+func (t *Tracker) syncRevisions(ctx context.Context) {
+	if t.opts.revisions == nil {
+		return
+	}
+	l := pkgx.Logger(ctx, t.l)
+	t.mu.Lock()
+	indices := make([]pkgx.IndexID, 0, len(t.indices))
+	for indexID := range t.indices {
+		indices = append(indices, indexID)
+	}
+	t.mu.Unlock()
+
+	for _, indexID := range indices {
+		revisions, err := t.opts.revisions.ListRevisions(ctx, indexID)
+		if err != nil {
+			l.Warn("failed to list revisions", zap.String("index", string(indexID)), zap.Error(err))
+			continue
+		}
+		for _, revision := range revisions {
+			if !revision.Active || revision.CreatedAt == 0 {
+				continue
+			}
+			created := time.Unix(revision.CreatedAt, 0)
+			t.mu.Lock()
+			freshness := t.index(indexID)
+			if created.After(freshness.LastCommit) {
+				freshness.LastCommit = created
+				t.metrics.lastCommit.WithLabelValues(string(indexID)).Set(float64(created.Unix()))
+			}
+			if created.After(freshness.LastUpdate) {
+				freshness.LastUpdate = created
+				t.metrics.lastUpdate.WithLabelValues(string(indexID)).Set(float64(created.Unix()))
+			}
+			t.mu.Unlock()
+		}
+	}
+}
+
+// Watch checks the freshness in the given interval until the context is done
+func (t *Tracker) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.Check(ctx)
+		}
+	}
+}
+
+// Healthz fails while any index exceeds its SLO
+func (t *Tracker) Healthz(ctx context.Context) error {
+	var stale []string
+	for _, freshness := range t.Check(ctx) {
+		if freshness.Stale {
+			stale = append(stale, string(freshness.IndexID))
+		}
+	}
+	if len(stale) > 0 {
+		return fmt.Errorf("%w: %v", ErrStale, stale)
+	}
+	return nil
+}
+
+// Status returns the freshness of all indices as of the last check, ordered by index
+func (t *Tracker) Status() []IndexFreshness {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := make([]IndexFreshness, 0, len(t.indices))
+	for _, freshness := range t.indices {
+		status = append(status, *freshness)
+	}
+	sort.Slice(status, func(i, j int) bool {
+		return status[i].IndexID < status[j].IndexID
+	})
+	return status
+}
+
+// index returns the freshness of the index, indices that were not configured are tracked from their first update
+func (t *Tracker) index(indexID pkgx.IndexID) *IndexFreshness {
+	freshness, ok := t.indices[indexID]
+	if !ok {
+		freshness = &IndexFreshness{IndexID: indexID, SLO: t.sloOf(indexID)}
+		t.indices[indexID] = freshness
+	}
+	return freshness
+}
+
+func (t *Tracker) sloOf(indexID pkgx.IndexID) time.Duration {
+	if slo, ok := t.opts.slos[indexID]; ok {
+		return slo
+	}
+	return t.slo
+}
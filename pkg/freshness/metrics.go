@@ -0,0 +1,55 @@
+package typesensefreshness
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	lastCommit *prometheus.GaugeVec
+	lastUpdate *prometheus.GaugeVec
+	stale      *prometheus.GaugeVec
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		lastCommit: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "typesense",
+			Subsystem: "freshness",
+			Name:      "last_commit_timestamp_seconds",
+			Help:      "Unix timestamp of the last committed revision by index",
+		}, []string{"index"}),
+		lastUpdate: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "typesense",
+			Subsystem: "freshness",
+			Name:      "last_update_timestamp_seconds",
+			Help:      "Unix timestamp of the last committed revision or successful delta update by index",
+		}, []string{"index"}),
+		stale: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "typesense",
+			Subsystem: "freshness",
+			Name:      "stale",
+			Help:      "1 if the index exceeds its freshness SLO, 0 otherwise",
+		}, []string{"index"}),
+	}
+	if registerer != nil {
+		m.lastCommit = register(registerer, m.lastCommit)
+		m.lastUpdate = register(registerer, m.lastUpdate)
+		m.stale = register(registerer, m.stale)
+	}
+	return m
+}
+
+// register registers the collector or returns the already registered one, e.g. when a tracker is recreated
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+	}
+	return collector
+}
@@ -0,0 +1,78 @@
+package typesensefreshness
+
+import (
+	"context"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	typesenseapi "github.com/foomo/typesense/pkg/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Callback is invoked when an index becomes stale or fresh again
+type Callback func(ctx context.Context, freshness IndexFreshness)
+
+// RevisionLister lists the revisions of an index, it is implemented by typesenseapi.BaseAPI
+type RevisionLister interface {
+	ListRevisions(ctx context.Context, indexID pkgx.IndexID) ([]typesenseapi.RevisionInfo, error)
+}
+
+// Option configures the Tracker
+type Option func(o *options)
+
+type options struct {
+	slos       map[pkgx.IndexID]time.Duration
+	callback   Callback
+	registerer prometheus.Registerer
+	clock      pkgx.Clock
+	revisions  RevisionLister
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		registerer: prometheus.DefaultRegisterer,
+		clock:      pkgx.SystemClock,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithIndexSLOs overrides the SLO of single indices, e.g. for news indices that have to be updated more often
+func WithIndexSLOs(slos map[pkgx.IndexID]time.Duration) Option {
+	return func(o *options) {
+		o.slos = slos
+	}
+}
+
+// WithCallback is invoked whenever an index exceeds its SLO or is updated again afterwards, e.g. to page someone
+func WithCallback(callback Callback) Option {
+	return func(o *options) {
+		o.callback = callback
+	}
+}
+
+// WithRegisterer registers the freshness metrics with the given registerer, nil disables the registration
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
+
+// WithClock replaces the system clock, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithRevisionLister derives the last commit of every index from the creation of the collection served by its alias,
+// so processes that don't run the indexer, e.g. the search service, track the freshness of the cluster
+func WithRevisionLister(revisions RevisionLister) Option {
+	return func(o *options) {
+		o.revisions = revisions
+	}
+}
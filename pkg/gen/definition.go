@@ -0,0 +1,288 @@
+package typesensegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	schemax "github.com/foomo/typesense/pkg/schema"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"gopkg.in/yaml.v3"
+)
+
+// Definition declares the indices of a package, it is read from YAML or JSON
+type Definition struct {
+	// Package is the name of the generated Go package
+	Package string  `json:"package" yaml:"package"`
+	Indices []Index `json:"indices" yaml:"indices"`
+}
+
+// Index declares a single index
+type Index struct {
+	// Name is the index ID, e.g. "products"
+	Name string `json:"name" yaml:"name"`
+	// Type is the name of the generated document struct, e.g. "Product"
+	Type                string   `json:"type" yaml:"type"`
+	QueryBy             []string `json:"query_by" yaml:"query_by"`
+	DefaultSortingField string   `json:"default_sorting_field,omitempty" yaml:"default_sorting_field,omitempty"`
+	// Locale is the locale of all string fields, e.g. "de"
+	Locale string  `json:"locale,omitempty" yaml:"locale,omitempty"`
+	Fields []Field `json:"fields" yaml:"fields"`
+	// Presets are the search presets by name, the values are the typesense search parameters
+	Presets map[string]map[string]any `json:"presets,omitempty" yaml:"presets,omitempty"`
+	// Synonyms are the synonyms by ID
+	Synonyms map[string]Synonym `json:"synonyms,omitempty" yaml:"synonyms,omitempty"`
+	// Overrides are the curation overrides by ID, the values are typesense override schemas,
+	// e.g. {"rule": {"query": "sale", "match": "exact"}, "includes": [{"id": "42", "position": 1}]}
+	Overrides map[string]map[string]any `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// Field declares a field of an index
+type Field struct {
+	Name     string `json:"name" yaml:"name"`
+	Type     string `json:"type" yaml:"type"`
+	Facet    bool   `json:"facet,omitempty" yaml:"facet,omitempty"`
+	Sort     bool   `json:"sort,omitempty" yaml:"sort,omitempty"`
+	Optional bool   `json:"optional,omitempty" yaml:"optional,omitempty"`
+	Infix    bool   `json:"infix,omitempty" yaml:"infix,omitempty"`
+	Stem     bool   `json:"stem,omitempty" yaml:"stem,omitempty"`
+	// NoIndex stores the field without indexing it
+	NoIndex bool `json:"no_index,omitempty" yaml:"no_index,omitempty"`
+	// NoStore indexes the field without storing it
+	NoStore bool `json:"no_store,omitempty" yaml:"no_store,omitempty"`
+	// Locale overrides the locale of the index for the string field
+	Locale string `json:"locale,omitempty" yaml:"locale,omitempty"`
+	// Reference links the field to a field of another index, e.g. "brands.id"
+	Reference string `json:"reference,omitempty" yaml:"reference,omitempty"`
+	// Deprecated marks the field as deprecated, it is kept for the given number of revisions and dropped afterwards,
+	// see typesenseapi.WithDeprecatedFields
+	Deprecated int `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// Synonym declares a multi-way synonym or, if Root is set, a one-way synonym
+type Synonym struct {
+	Root     string   `json:"root,omitempty" yaml:"root,omitempty"`
+	Synonyms []string `json:"synonyms" yaml:"synonyms"`
+}
+
+// Load reads the definition from a .yaml, .yml or .json file
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	definition, err := Decode(data, filepath.Ext(path))
+	if err != nil {
+		return nil, fmt.Errorf("invalid definition %s: %w", path, err)
+	}
+	return definition, nil
+}
+
+// Decode parses the definition in the format given by the file extension, unknown keys are rejected
+func Decode(data []byte, ext string) (*Definition, error) {
+	definition := &Definition{}
+	switch strings.ToLower(ext) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(definition); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(definition); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported definition format %q", ext)
+	}
+	return definition, definition.Validate()
+}
+
+// Validate checks the definition and the schemas built from it
+func (d *Definition) Validate() error {
+	var errs []error
+	if d.Package == "" {
+		errs = append(errs, errors.New("package must not be empty"))
+	}
+	types := map[string]bool{}
+	for _, index := range d.Indices {
+		if index.Name == "" || index.Type == "" {
+			errs = append(errs, errors.New("index name and type must not be empty"))
+			continue
+		}
+		if types[index.Type] {
+			errs = append(errs, fmt.Errorf("type %q is declared twice", index.Type))
+		}
+		types[index.Type] = true
+
+		fields := map[string]Field{}
+		for _, field := range index.Fields {
+			if _, ok := goTypes[field.Type]; !ok {
+				errs = append(errs, fmt.Errorf("index %s: field %q has unsupported type %q", index.Name, field.Name, field.Type))
+			}
+			fields[field.Name] = field
+		}
+		for _, name := range index.QueryBy {
+			if field, ok := fields[name]; !ok || !strings.HasPrefix(field.Type, schemax.TypeString) {
+				errs = append(errs, fmt.Errorf("index %s: query_by field %q must be a declared string field", index.Name, name))
+			} else if field.Deprecated > 0 {
+				errs = append(errs, fmt.Errorf("index %s: query_by field %q is deprecated", index.Name, name))
+			}
+		}
+		for _, field := range index.Fields {
+			if field.Deprecated < 0 {
+				errs = append(errs, fmt.Errorf("index %s: deprecated revisions of field %q must not be negative", index.Name, field.Name))
+			}
+		}
+		for _, id := range sortedKeys(index.Overrides) {
+			if _, err := decodeOverride(index.Overrides[id]); err != nil {
+				errs = append(errs, fmt.Errorf("index %s: override %s: %w", index.Name, id, err))
+			}
+		}
+		if _, err := index.builder().Build(); err != nil {
+			errs = append(errs, fmt.Errorf("index %s: %w", index.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Schemas builds the collection schemas of all indices by index name, e.g. to validate them against a cluster
+func (d *Definition) Schemas() (map[string]*api.CollectionSchema, error) {
+	schemas := make(map[string]*api.CollectionSchema, len(d.Indices))
+	for _, index := range d.Indices {
+		schema, err := index.builder().Build()
+		if err != nil {
+			return nil, fmt.Errorf("index %s: %w", index.Name, err)
+		}
+		schemas[index.Name] = schema
+	}
+	return schemas, nil
+}
+
+// Presets returns the search presets of all indices by name, as generated by Generate
+func (d *Definition) Presets() (map[string]*api.PresetUpsertSchema, error) {
+	presets := map[string]*api.PresetUpsertSchema{}
+	for _, index := range d.Indices {
+		for name, value := range index.Presets {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("index %s: preset %s: %w", index.Name, name, err)
+			}
+			preset := &api.PresetUpsertSchema{}
+			if err := preset.Value.UnmarshalJSON(data); err != nil {
+				return nil, fmt.Errorf("index %s: preset %s: %w", index.Name, name, err)
+			}
+			presets[name] = preset
+		}
+	}
+	return presets, nil
+}
+
+// SearchOverrides returns the curation overrides of all indices by index name and override ID, as generated by Generate
+func (d *Definition) SearchOverrides() (map[string]map[string]*api.SearchOverrideSchema, error) {
+	overrides := map[string]map[string]*api.SearchOverrideSchema{}
+	for _, index := range d.Indices {
+		for id, value := range index.Overrides {
+			override, err := decodeOverride(value)
+			if err != nil {
+				return nil, fmt.Errorf("index %s: override %s: %w", index.Name, id, err)
+			}
+			if overrides[index.Name] == nil {
+				overrides[index.Name] = map[string]*api.SearchOverrideSchema{}
+			}
+			overrides[index.Name][id] = override
+		}
+	}
+	return overrides, nil
+}
+
+// decodeOverride converts the declared override into a typesense override schema,
+// the rule has to match a query, a filter or tags
+func decodeOverride(value map[string]any) (*api.SearchOverrideSchema, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	override := &api.SearchOverrideSchema{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(override); err != nil {
+		return nil, err
+	}
+	if override.Rule.Query == nil && override.Rule.FilterBy == nil && override.Rule.Tags == nil {
+		return nil, errors.New("rule must match a query, a filter or tags")
+	}
+	return override, nil
+}
+
+// builder returns the schema builder of the index, used to validate the generated schema
+func (i Index) builder() *schemax.Builder {
+	builder := schemax.NewBuilder()
+	for _, field := range i.Fields {
+		builder.Field(field.Name, field.Type, field.options()...)
+	}
+	if i.DefaultSortingField != "" {
+		builder.DefaultSortingField(i.DefaultSortingField)
+	}
+	if i.Locale != "" {
+		builder.Locale(i.Locale)
+	}
+	return builder
+}
+
+func (f Field) options() []schemax.FieldOption {
+	var opts []schemax.FieldOption
+	indexID, field, _ := strings.Cut(f.Reference, ".")
+	for _, option := range []struct {
+		option  schemax.FieldOption
+		enabled bool
+	}{
+		{schemax.Facet(), f.Facet},
+		{schemax.Sort(), f.Sort},
+		{schemax.Optional(), f.Optional},
+		{schemax.Infix(), f.Infix},
+		{schemax.Stem(), f.Stem},
+		{schemax.NoIndex(), f.NoIndex},
+		{schemax.NoStore(), f.NoStore},
+		{schemax.Locale(f.Locale), f.Locale != ""},
+		{schemax.Reference(indexID, field), f.Reference != ""},
+	} {
+		if option.enabled {
+			opts = append(opts, option.option)
+		}
+	}
+	return opts
+}
+
+// optionCalls returns the calls of the typesenseschema field options of the field
+func (f Field) optionCalls() []string {
+	var calls []string
+	indexID, field, _ := strings.Cut(f.Reference, ".")
+	for _, option := range []struct {
+		call    string
+		enabled bool
+	}{
+		{"Facet()", f.Facet},
+		{"Sort()", f.Sort},
+		{"Optional()", f.Optional},
+		{"Infix()", f.Infix},
+		{"Stem()", f.Stem},
+		{"NoIndex()", f.NoIndex},
+		{"NoStore()", f.NoStore},
+		{"Locale(" + strconv.Quote(f.Locale) + ")", f.Locale != ""},
+		{"Reference(" + strconv.Quote(indexID) + ", " + strconv.Quote(field) + ")", f.Reference != ""},
+	} {
+		if option.enabled {
+			calls = append(calls, option.call)
+		}
+	}
+	return calls
+}
@@ -0,0 +1,377 @@
+package typesensegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	schemax "github.com/foomo/typesense/pkg/schema"
+)
+
+// goTypes maps the typesense field types to Go types
+var goTypes = map[string]string{
+	schemax.TypeString:      "string",
+	schemax.TypeStringArray: "[]string",
+	schemax.TypeInt32:       "int32",
+	schemax.TypeInt32Array:  "[]int32",
+	schemax.TypeInt64:       "int64",
+	schemax.TypeInt64Array:  "[]int64",
+	schemax.TypeFloat:       "float64",
+	schemax.TypeFloatArray:  "[]float64",
+	schemax.TypeBool:        "bool",
+	schemax.TypeBoolArray:   "[]bool",
+	schemax.TypeGeopoint:    "[]float64",
+	schemax.TypeObject:      "map[string]any",
+	schemax.TypeObjectArray: "[]map[string]any",
+	schemax.TypeAuto:        "any",
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]bool{"id": true, "url": true, "uri": true, "html": true, "api": true, "sku": true}
+
+// Generate renders the Go source of the definition
+func Generate(definition *Definition) ([]byte, error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := sourceTemplate.Execute(&buf, newTemplateData(definition)); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+type templateData struct {
+	Package    string
+	Indices    []indexData
+	HasPresets bool
+}
+
+type indexData struct {
+	Name                string
+	Type                string
+	QueryBy             string
+	DefaultSortingField string
+	Locale              string
+	HasID               bool
+	Fields              []fieldData
+	Presets             []presetData
+	Synonyms            []synonymData
+	Overrides           []overrideData
+}
+
+type fieldData struct {
+	Name    string
+	Type    string
+	GoName  string
+	GoType  string
+	Tag     string
+	Options []string
+	Facet   bool
+	Sort    bool
+	// Deprecated is the number of revisions the deprecated field is kept
+	Deprecated int
+}
+
+type presetData struct {
+	Name  string
+	Value string
+}
+
+type overrideData struct {
+	ID    string
+	Value string
+}
+
+type synonymData struct {
+	ID       string
+	Root     string
+	Synonyms []string
+}
+
+func newTemplateData(definition *Definition) templateData {
+	data := templateData{Package: definition.Package}
+	for _, index := range definition.Indices {
+		indexData := indexData{
+			Name:                index.Name,
+			Type:                index.Type,
+			QueryBy:             strings.Join(index.QueryBy, ","),
+			DefaultSortingField: index.DefaultSortingField,
+			Locale:              index.Locale,
+		}
+		for _, field := range index.Fields {
+			if field.Name == "id" {
+				indexData.HasID = true
+			}
+			goType := goTypes[field.Type]
+			tag := field.Name
+			if field.Optional {
+				tag += ",omitempty"
+				if !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map") && goType != "any" {
+					goType = "*" + goType
+				}
+			}
+			indexData.Fields = append(indexData.Fields, fieldData{
+				Name:       field.Name,
+				Type:       field.Type,
+				GoName:     goName(field.Name),
+				GoType:     goType,
+				Tag:        tag,
+				Options:    field.optionCalls(),
+				Facet:      field.Facet,
+				Sort:       field.Sort,
+				Deprecated: field.Deprecated,
+			})
+		}
+		for _, name := range sortedKeys(index.Presets) {
+			value, _ := json.Marshal(index.Presets[name])
+			indexData.Presets = append(indexData.Presets, presetData{Name: name, Value: string(value)})
+			data.HasPresets = true
+		}
+		for _, id := range sortedKeys(index.Synonyms) {
+			synonym := index.Synonyms[id]
+			indexData.Synonyms = append(indexData.Synonyms, synonymData{ID: id, Root: synonym.Root, Synonyms: synonym.Synonyms})
+		}
+		for _, id := range sortedKeys(index.Overrides) {
+			value, _ := json.Marshal(index.Overrides[id])
+			indexData.Overrides = append(indexData.Overrides, overrideData{ID: id, Value: string(value)})
+		}
+		data.Indices = append(data.Indices, indexData)
+	}
+	return data
+}
+
+// goName converts a field name like "published_at" or "price.net" to an exported Go name like "PublishedAt"
+func goName(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, part := range parts {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	if b.Len() == 0 || unicode.IsDigit([]rune(b.String())[0]) {
+		return "F" + b.String()
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var sourceTemplate = template.Must(template.New("source").Funcs(template.FuncMap{
+	"quote": strconv.Quote,
+	"isString": func(fieldType string) bool {
+		return fieldType == schemax.TypeString || fieldType == schemax.TypeStringArray
+	},
+	"isNumeric": func(fieldType string) bool {
+		return strings.HasPrefix(fieldType, "int") || strings.HasPrefix(fieldType, "float")
+	},
+	"isBool": func(fieldType string) bool {
+		return fieldType == schemax.TypeBool || fieldType == schemax.TypeBoolArray
+	},
+}).Parse(`// Code generated by typesense-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	schemax "github.com/foomo/typesense/pkg/schema"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+var (
+	_ = fmt.Sprintf
+	_ = strings.Join
+)
+{{ range $index := .Indices }}
+// {{ .Type }} is the document of the {{ quote .Name }} index
+type {{ .Type }} struct {
+{{- if not .HasID }}
+	ID string ` + "`json:\"id\"`" + `
+{{- end }}
+{{- range .Fields }}
+{{- if .Deprecated }}
+	// Deprecated: {{ .Name }} is dropped from the schema after {{ .Deprecated }} revisions
+{{- end }}
+	{{ .GoName }} {{ .GoType }} ` + "`json:\"{{ .Tag }}\"`" + `
+{{- end }}
+}
+
+const (
+	// {{ .Type }}Index is the ID of the {{ quote .Name }} index
+	{{ .Type }}Index pkgx.IndexID = {{ quote .Name }}
+	// {{ .Type }}QueryBy are the fields searched by Search{{ .Type }}
+	{{ .Type }}QueryBy = {{ quote .QueryBy }}
+{{ range .Fields }}
+	{{ $index.Type }}Field{{ .GoName }} = {{ quote .Name }}
+{{- end }}
+{{ range .Fields }}{{ if .Sort }}
+	{{ $index.Type }}Sort{{ .GoName }}Asc  = "{{ .Name }}:asc"
+	{{ $index.Type }}Sort{{ .GoName }}Desc = "{{ .Name }}:desc"
+{{- end }}{{ end }}
+)
+
+// {{ .Type }}Schema returns the collection schema of the {{ quote .Name }} index
+func {{ .Type }}Schema() *api.CollectionSchema {
+	return schemax.NewBuilder().
+{{- range .Fields }}
+		Field({{ quote .Name }}, {{ quote .Type }}{{ range .Options }}, schemax.{{ . }}{{ end }}).
+{{- end }}
+{{- if .DefaultSortingField }}
+		DefaultSortingField({{ quote .DefaultSortingField }}).
+{{- end }}
+{{- if .Locale }}
+		Locale({{ quote .Locale }}).
+{{- end }}
+		MustBuild()
+}
+{{ range .Fields }}{{ if .Facet }}{{ if isString .Type }}
+// {{ $index.Type }}Filter{{ .GoName }} returns a filter matching any of the values of {{ quote .Name }}
+func {{ $index.Type }}Filter{{ .GoName }}(values ...string) string {
+	filterValues := make([]any, len(values))
+	for i, value := range values {
+		filterValues[i] = value
+	}
+	return pkgx.NewFilterBuilder().In({{ quote .Name }}, filterValues...).Build()
+}
+{{ else if isNumeric .Type }}
+// {{ $index.Type }}Filter{{ .GoName }}Range returns a filter matching values of {{ quote .Name }} between min and max
+func {{ $index.Type }}Filter{{ .GoName }}Range(minValue, maxValue float64) string {
+	return pkgx.NewFilterBuilder().Range({{ quote .Name }}, minValue, maxValue).Build()
+}
+{{ else if isBool .Type }}
+// {{ $index.Type }}Filter{{ .GoName }} returns a filter matching the value of {{ quote .Name }}
+func {{ $index.Type }}Filter{{ .GoName }}(value bool) string {
+	return pkgx.NewFilterBuilder().Eq({{ quote .Name }}, value).Build()
+}
+{{ end }}{{ end }}{{ end }}
+// Search{{ .Type }} searches the {{ quote .Name }} index by the query_by fields of the definition,
+// filterBy and sortBy are optional
+func Search{{ .Type }}[returnType any](
+	ctx context.Context,
+	searchAPI pkgx.API[{{ .Type }}, returnType],
+	query, filterBy, sortBy string,
+	page, perPage int,
+) (*pkgx.SearchResult[returnType], error) {
+	params := &api.SearchCollectionParams{
+		Q:       pointer.String(query),
+		QueryBy: pointer.String({{ .Type }}QueryBy),
+		Page:    pointer.Int(max(page, 1)),
+	}
+	if perPage > 0 {
+		params.PerPage = pointer.Int(perPage)
+	}
+	if filterBy != "" {
+		params.FilterBy = pointer.String(filterBy)
+	}
+	if sortBy != "" {
+		params.SortBy = pointer.String(sortBy)
+	}
+	return searchAPI.ExpertSearchResult(ctx, {{ .Type }}Index, params)
+}
+{{ end }}
+// Schemas returns the collection schemas of all indices
+func Schemas() map[pkgx.IndexID]*api.CollectionSchema {
+	return map[pkgx.IndexID]*api.CollectionSchema{
+{{- range .Indices }}
+		{{ .Type }}Index: {{ .Type }}Schema(),
+{{- end }}
+	}
+}
+
+// DeprecatedFields returns the revisions the deprecated fields are kept by index and field,
+// see typesenseapi.WithDeprecatedFields
+func DeprecatedFields() map[pkgx.IndexID]map[string]int {
+	return map[pkgx.IndexID]map[string]int{
+{{- range .Indices }}{{ $index := . }}{{ range .Fields }}{{ if .Deprecated }}
+		{{ $index.Type }}Index: {
+{{- range $index.Fields }}{{ if .Deprecated }}
+			{{ quote .Name }}: {{ .Deprecated }},
+{{- end }}{{ end }}
+		},{{ break }}
+{{- end }}{{ end }}{{ end }}
+	}
+}
+
+// Presets returns the search presets of all indices by name
+func Presets() map[string]*api.PresetUpsertSchema {
+	return map[string]*api.PresetUpsertSchema{
+{{- range .Indices }}{{ range .Presets }}
+		{{ quote .Name }}: mustPreset({{ quote .Value }}),
+{{- end }}{{ end }}
+	}
+}
+
+// Synonyms returns the synonyms of all indices by synonym ID
+func Synonyms() map[pkgx.IndexID]map[string]api.SearchSynonymSchema {
+	return map[pkgx.IndexID]map[string]api.SearchSynonymSchema{
+{{- range .Indices }}{{ if .Synonyms }}
+		{{ .Type }}Index: {
+{{- range .Synonyms }}
+			{{ quote .ID }}: {
+{{- if .Root }}
+				Root: pointer.String({{ quote .Root }}),
+{{- end }}
+				Synonyms: []string{ {{- range $i, $s := .Synonyms }}{{ if $i }}, {{ end }}{{ quote $s }}{{ end -}} },
+			},
+{{- end }}
+		},
+{{- end }}{{ end }}
+	}
+}
+
+// SearchOverrides returns the curation overrides of all indices by override ID,
+// see typesenseapi.WithSearchOverrides
+func SearchOverrides() map[pkgx.IndexID]map[string]*api.SearchOverrideSchema {
+	return map[pkgx.IndexID]map[string]*api.SearchOverrideSchema{
+{{- range .Indices }}{{ if .Overrides }}
+		{{ .Type }}Index: {
+{{- range .Overrides }}
+			{{ quote .ID }}: mustOverride({{ quote .Value }}),
+{{- end }}
+		},
+{{- end }}{{ end }}
+	}
+}
+
+func mustPreset(value string) *api.PresetUpsertSchema {
+	preset := &api.PresetUpsertSchema{}
+	if err := preset.Value.UnmarshalJSON([]byte(value)); err != nil {
+		panic(err)
+	}
+	return preset
+}
+
+func mustOverride(value string) *api.SearchOverrideSchema {
+	override := &api.SearchOverrideSchema{}
+	if err := json.Unmarshal([]byte(value), override); err != nil {
+		panic(err)
+	}
+	return override
+}
+`))
@@ -0,0 +1,263 @@
+package typesensehttpserver
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const openAPIVersion = "3.0.3"
+
+var (
+	packagePathRegexp = regexp.MustCompile(`[\w./-]*\.`)
+	nonAlnumRegexp    = regexp.MustCompile(`[^A-Za-z0-9]`)
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// OpenAPI returns an OpenAPI 3 document describing the enabled endpoints.
+// The schemas of the responses, including the returnType, are derived from the Go types via reflection.
+func (s *Server[indexDocument, returnType]) OpenAPI() map[string]any {
+	g := &schemaGenerator{components: map[string]any{}}
+
+	errorResponse := response("error", g.schema(reflect.TypeOf(ErrorResponse{})))
+	searchParameters := []any{
+		parameter("q", "query", "search query", false, map[string]any{"type": "string"}),
+		parameter("page", "query", "page number starting at 1", false, map[string]any{"type": "integer", "minimum": 1}),
+	}
+	if sorts := s.binder.Sorts(); len(sorts) > 0 {
+		searchParameters = append(searchParameters,
+			parameter("sort", "query", "sort order", false, map[string]any{"type": "string", "enum": sorts}),
+		)
+	}
+	if profiles := s.binder.Profiles(); len(profiles) > 0 {
+		searchParameters = append(searchParameters,
+			parameter("profile", "query", "projection profile selecting the returned fields", false, map[string]any{"type": "string", "enum": profiles}),
+		)
+	}
+	for _, field := range s.binder.Filters() {
+		searchParameters = append(searchParameters,
+			parameter("filters["+field+"]", "query", "filter by "+field+", may be repeated", false, map[string]any{"type": "string"}),
+		)
+	}
+	indexParameter := parameter("index", "path", "index ID", true, map[string]any{"type": "string"})
+
+	paths := map[string]any{
+		"/search/{index}": map[string]any{
+			"get": map[string]any{
+				"operationId": "search",
+				"parameters":  append([]any{indexParameter}, searchParameters...),
+				"responses": map[string]any{
+					"200": response("search result", g.schema(reflect.TypeOf(SearchResponse[returnType]{}))),
+					"400": errorResponse,
+					"403": errorResponse,
+					"404": errorResponse,
+					"502": errorResponse,
+				},
+			},
+		},
+	}
+	if s.suggester != nil {
+		paths["/suggest/{index}"] = map[string]any{
+			"get": map[string]any{
+				"operationId": "suggest",
+				"parameters": []any{
+					indexParameter,
+					parameter("q", "query", "query prefix", false, map[string]any{"type": "string"}),
+					parameter("limit", "query", "maximum number of suggestions", false, map[string]any{"type": "integer", "minimum": 1}),
+				},
+				"responses": map[string]any{
+					"200": response("query suggestions", g.schema(reflect.TypeOf(SuggestResponse{}))),
+					"400": errorResponse,
+					"403": errorResponse,
+					"404": errorResponse,
+					"502": errorResponse,
+				},
+			},
+		}
+	}
+	if s.multiSearcher != nil {
+		paths["/multi-search"] = map[string]any{
+			"get": map[string]any{
+				"operationId": "multiSearch",
+				"parameters": append([]any{
+					parameter("indices", "query", "comma separated index IDs", true, map[string]any{"type": "string"}),
+				}, searchParameters...),
+				"responses": map[string]any{
+					"200": response("merged search result", g.schema(reflect.TypeOf(MultiSearchResponse[returnType]{}))),
+					"400": errorResponse,
+					"403": errorResponse,
+					"404": errorResponse,
+					"502": errorResponse,
+				},
+			},
+		}
+	}
+
+	if s.facetSearcher != nil {
+		facetParameters := []any{
+			indexParameter,
+			parameter("facet_by", "query", "comma separated facet fields", true, map[string]any{"type": "string"}),
+		}
+		for _, field := range s.binder.Filters() {
+			facetParameters = append(facetParameters,
+				parameter("filters["+field+"]", "query", "filter by "+field+", may be repeated", false, map[string]any{"type": "string"}),
+			)
+		}
+		paths["/facets/{index}"] = map[string]any{
+			"get": map[string]any{
+				"operationId": "facets",
+				"parameters":  facetParameters,
+				"responses": map[string]any{
+					"200": response("facet counts", g.schema(reflect.TypeOf(FacetsResponse{}))),
+					"400": errorResponse,
+					"403": errorResponse,
+					"404": errorResponse,
+					"502": errorResponse,
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   "Typesense Search API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": g.components,
+		},
+	}
+}
+
+func (s *Server[indexDocument, returnType]) openAPI(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.OpenAPI())
+}
+
+func parameter(name, in, description string, required bool, schema map[string]any) map[string]any {
+	return map[string]any{
+		"name":        name,
+		"in":          in,
+		"description": description,
+		"required":    required,
+		"schema":      schema,
+	}
+}
+
+func response(description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{
+				"schema": schema,
+			},
+		},
+	}
+}
+
+// schemaGenerator derives JSON schemas from Go types, named structs are added to the components
+type schemaGenerator struct {
+	components map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := g.schema(t.Elem())
+		if _, ok := schema["$ref"]; ok {
+			// siblings of $ref are ignored, so references have to be wrapped
+			return map[string]any{"allOf": []any{schema}, "nullable": true}
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return map[string]any{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]any{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]any{"type": "string", "format": "date-time"}
+		}
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := g.components[name]; !ok {
+			// register the name first to support recursive types
+			g.components[name] = map[string]any{}
+			g.components[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	default:
+		// interfaces and other kinds accept any value
+		return map[string]any{}
+	}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+	g.addFields(t, properties, &required)
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, properties, required)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schema(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaName returns a component name without package paths, e.g. "SearchResponseProduct" for SearchResponse[shop.Product]
+func schemaName(t reflect.Type) string {
+	return nonAlnumRegexp.ReplaceAllString(packagePathRegexp.ReplaceAllString(t.Name(), ""), "")
+}
@@ -0,0 +1,345 @@
+package typesensehttpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	apix "github.com/foomo/typesense/pkg/api"
+	bindingx "github.com/foomo/typesense/pkg/binding"
+	"go.uber.org/zap"
+)
+
+const defaultSuggestLimit = 10
+
+// QuerySuggester provides query suggestions for the suggest endpoint, e.g. *typesensesuggestions.QuerySuggestions
+type QuerySuggester interface {
+	SuggestQueries(ctx context.Context, indexID pkgx.IndexID, prefix string, limit int) ([]string, error)
+}
+
+// FacetSearcher returns facet counts without hits for the facets endpoint, e.g. *typesenseapi.BaseAPI
+type FacetSearcher interface {
+	Facets(ctx context.Context, indexID pkgx.IndexID, facetFields []string, filter string) ([]pkgx.Facet, error)
+}
+
+// MultiSearcher searches several indices at once for the multi-search endpoint, e.g. *typesenseapi.BaseAPI
+type MultiSearcher[returnType any] interface {
+	SearchMany(ctx context.Context, indices []pkgx.IndexID, parameters *pkgx.SearchParameters) (*pkgx.MultiSearchResult[returnType], error)
+}
+
+// SearchResponse is the response of the search endpoint
+type SearchResponse[returnType any] struct {
+	Results     []returnType    `json:"results"`
+	Total       int             `json:"total"`
+	Page        int             `json:"page"`
+	Suggestions []string        `json:"suggestions,omitempty"`
+	Pagination  pkgx.Pagination `json:"pagination"`
+	// Redirect is the URL to navigate to instead of showing the results
+	Redirect string `json:"redirect,omitempty"`
+}
+
+// MultiSearchHit is a single hit of the multi-search endpoint
+type MultiSearchHit[returnType any] struct {
+	Index    string     `json:"index"`
+	Document returnType `json:"document"`
+	Score    float64    `json:"score"`
+}
+
+// MultiSearchResponse is the response of the multi-search endpoint
+type MultiSearchResponse[returnType any] struct {
+	Hits   []MultiSearchHit[returnType] `json:"hits"`
+	Totals map[string]int               `json:"totals"`
+	Total  int                          `json:"total"`
+}
+
+// FacetsResponse is the response of the facets endpoint
+type FacetsResponse struct {
+	Facets []pkgx.Facet `json:"facets"`
+}
+
+// SuggestResponse is the response of the suggest endpoint
+type SuggestResponse struct {
+	Queries []string `json:"queries"`
+}
+
+// ErrorResponse is returned with all 4xx and 5xx responses
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// Option configures the Server
+type Option[indexDocument any, returnType any] func(s *Server[indexDocument, returnType])
+
+// WithQuerySuggester enables the suggest endpoint
+func WithQuerySuggester[indexDocument any, returnType any](suggester QuerySuggester) Option[indexDocument, returnType] {
+	return func(s *Server[indexDocument, returnType]) {
+		s.suggester = suggester
+	}
+}
+
+// WithMultiSearcher enables the multi-search endpoint
+func WithMultiSearcher[indexDocument any, returnType any](searcher MultiSearcher[returnType]) Option[indexDocument, returnType] {
+	return func(s *Server[indexDocument, returnType]) {
+		s.multiSearcher = searcher
+	}
+}
+
+// WithFacetSearcher enables the facets endpoint
+func WithFacetSearcher[indexDocument any, returnType any](searcher FacetSearcher) Option[indexDocument, returnType] {
+	return func(s *Server[indexDocument, returnType]) {
+		s.facetSearcher = searcher
+	}
+}
+
+// Server exposes the search API over HTTP:
+//
+//	GET /search/{index}     search a single index, see typesensebinding for the supported parameters
+//	GET /suggest/{index}    query suggestions for the "q" prefix
+//	GET /multi-search       search all indices given by "indices=a,b"
+//	GET /facets/{index}     facet counts of the fields given by "facet_by=a,b" without hits
+//	GET /openapi.json       OpenAPI 3 document of the endpoints
+type Server[indexDocument any, returnType any] struct {
+	l             *zap.Logger
+	api           pkgx.API[indexDocument, returnType]
+	binder        *bindingx.Binder
+	suggester     QuerySuggester
+	multiSearcher MultiSearcher[returnType]
+	facetSearcher FacetSearcher
+}
+
+func NewServer[indexDocument any, returnType any](
+	l *zap.Logger,
+	api pkgx.API[indexDocument, returnType],
+	binder *bindingx.Binder,
+	opts ...Option[indexDocument, returnType],
+) *Server[indexDocument, returnType] {
+	s := &Server[indexDocument, returnType]{
+		l:      l,
+		api:    api,
+		binder: binder,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	return s
+}
+
+// Handler returns the http.Handler serving all endpoints
+func (s *Server[indexDocument, returnType]) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search/{index}", s.search)
+	if s.suggester != nil {
+		mux.HandleFunc("GET /suggest/{index}", s.suggest)
+	}
+	if s.multiSearcher != nil {
+		mux.HandleFunc("GET /multi-search", s.multiSearch)
+	}
+	if s.facetSearcher != nil {
+		mux.HandleFunc("GET /facets/{index}", s.facets)
+	}
+	mux.HandleFunc("GET /openapi.json", s.openAPI)
+	return withRequestID(mux)
+}
+
+// withRequestID attaches the X-Request-Id header of the request to the log lines emitted while serving it
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestID := r.Header.Get("X-Request-Id"); requestID != "" {
+			r = r.WithContext(pkgx.WithLoggerFields(r.Context(), zap.String("request_id", requestID)))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server[indexDocument, returnType]) search(w http.ResponseWriter, r *http.Request) {
+	indexID, ok := s.index(w, r.PathValue("index"))
+	if !ok {
+		return
+	}
+	params, err := s.binder.Bind(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := s.api.SimpleSearchResult(r.Context(), indexID, params)
+	if err != nil {
+		s.writeError(w, errorStatus(err), err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, &SearchResponse[returnType]{
+		Results:     result.Results,
+		Total:       result.Total,
+		Page:        params.Page,
+		Suggestions: result.Suggestions,
+		Pagination:  result.Pagination,
+		Redirect:    result.Redirect,
+	})
+}
+
+func (s *Server[indexDocument, returnType]) suggest(w http.ResponseWriter, r *http.Request) {
+	indexID, ok := s.index(w, r.PathValue("index"))
+	if !ok {
+		return
+	}
+	limit := defaultSuggestLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		l, err := strconv.Atoi(value)
+		if err != nil || l < 1 {
+			s.writeError(w, http.StatusBadRequest, &bindingx.ValidationError{Parameter: "limit", Message: "must be a positive number"})
+			return
+		}
+		limit = l
+	}
+
+	queries, err := s.suggester.SuggestQueries(r.Context(), indexID, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		s.writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if queries == nil {
+		queries = []string{}
+	}
+	s.writeJSON(w, http.StatusOK, &SuggestResponse{Queries: queries})
+}
+
+func (s *Server[indexDocument, returnType]) multiSearch(w http.ResponseWriter, r *http.Request) {
+	var indices []pkgx.IndexID
+	for _, index := range strings.Split(r.URL.Query().Get("indices"), ",") {
+		if index = strings.TrimSpace(index); index == "" {
+			continue
+		}
+		indexID, ok := s.index(w, index)
+		if !ok {
+			return
+		}
+		indices = append(indices, indexID)
+	}
+	if len(indices) == 0 {
+		s.writeError(w, http.StatusBadRequest, &bindingx.ValidationError{Parameter: "indices", Message: "must not be empty"})
+		return
+	}
+
+	params, err := s.binder.Bind(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	result, err := s.multiSearcher.SearchMany(r.Context(), indices, params)
+	if err != nil {
+		s.writeError(w, errorStatus(err), err)
+		return
+	}
+
+	response := &MultiSearchResponse[returnType]{
+		Hits:   make([]MultiSearchHit[returnType], 0, len(result.Hits)),
+		Totals: make(map[string]int, len(result.Totals)),
+		Total:  result.Total,
+	}
+	for _, hit := range result.Hits {
+		response.Hits = append(response.Hits, MultiSearchHit[returnType]{
+			Index:    string(hit.IndexID),
+			Document: hit.Document,
+			Score:    hit.NormalizedScore,
+		})
+	}
+	for indexID, total := range result.Totals {
+		response.Totals[string(indexID)] = total
+	}
+	s.writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server[indexDocument, returnType]) facets(w http.ResponseWriter, r *http.Request) {
+	indexID, ok := s.index(w, r.PathValue("index"))
+	if !ok {
+		return
+	}
+	filters := s.binder.Filters()
+	var facetFields []string
+	for _, field := range strings.Split(r.URL.Query().Get("facet_by"), ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		if len(filters) > 0 && !slices.Contains(filters, field) {
+			s.writeError(w, http.StatusBadRequest, &bindingx.ValidationError{Parameter: "facet_by", Message: "unsupported field " + field})
+			return
+		}
+		facetFields = append(facetFields, field)
+	}
+	if len(facetFields) == 0 {
+		s.writeError(w, http.StatusBadRequest, &bindingx.ValidationError{Parameter: "facet_by", Message: "must not be empty"})
+		return
+	}
+
+	params, err := s.binder.Bind(r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	facets, err := s.facetSearcher.Facets(r.Context(), indexID, facetFields, params.FilterBy)
+	if err != nil {
+		s.writeError(w, errorStatus(err), err)
+		return
+	}
+	if facets == nil {
+		facets = []pkgx.Facet{}
+	}
+	s.writeJSON(w, http.StatusOK, &FacetsResponse{Facets: facets})
+}
+
+// index returns the configured index with the given ID and writes 404 for all other IDs, e.g. collection, canary or
+// registry names, so they can't be searched over HTTP
+func (s *Server[indexDocument, returnType]) index(w http.ResponseWriter, index string) (pkgx.IndexID, bool) {
+	indices, err := s.api.Indices()
+	if err != nil {
+		s.writeError(w, http.StatusBadGateway, err)
+		return "", false
+	}
+	if !slices.Contains(indices, pkgx.IndexID(index)) {
+		s.writeError(w, http.StatusNotFound, fmt.Errorf("%w %s", apix.ErrUnknownIndex, index))
+		return "", false
+	}
+	return pkgx.IndexID(index), true
+}
+
+// errorStatus maps the errors of searches to the status of the response
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, apix.ErrUnknownIndex):
+		return http.StatusNotFound
+	case errors.Is(err, pkgx.ErrIndexNotAllowed), errors.Is(err, pkgx.ErrMissingRoles):
+		return http.StatusForbidden
+	case errors.Is(err, apix.ErrPageOutOfRange), errors.Is(err, apix.ErrUnknownProfile):
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func (s *Server[indexDocument, returnType]) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.l.Warn("failed to encode response", zap.Error(err))
+	}
+}
+
+func (s *Server[indexDocument, returnType]) writeError(w http.ResponseWriter, status int, err error) {
+	if status >= http.StatusInternalServerError {
+		s.l.Error("search request failed", zap.Error(err))
+		s.writeJSON(w, status, &ErrorResponse{Error: http.StatusText(status)})
+		return
+	}
+	s.writeJSON(w, status, &ErrorResponse{Error: err.Error()})
+}
@@ -0,0 +1,113 @@
+package typesense
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IndexGroup maps a logical index, e.g. "www", to its locale specific indices, e.g. "www-de" and "www-en"
+type IndexGroup struct {
+	Name string
+	// Indices by normalized locale, e.g. "de" or "de-at"
+	Indices       map[string]IndexID
+	DefaultLocale string
+}
+
+// NewIndexGroup creates a group with an index named "<name>-<locale>" for every locale
+func NewIndexGroup(name, defaultLocale string, locales ...string) IndexGroup {
+	g := IndexGroup{
+		Name:          name,
+		Indices:       make(map[string]IndexID, len(locales)),
+		DefaultLocale: normalizeLocale(defaultLocale),
+	}
+	for _, locale := range locales {
+		locale = normalizeLocale(locale)
+		g.Indices[locale] = IndexID(name + "-" + locale)
+	}
+	return g
+}
+
+// IndexIDs returns all indices of the group in a stable order
+func (g IndexGroup) IndexIDs() []IndexID {
+	indices := make([]IndexID, 0, len(g.Indices))
+	for _, indexID := range g.Indices {
+		indices = append(indices, indexID)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i] < indices[j]
+	})
+	return indices
+}
+
+// Resolve returns the index of the given locale. Regional locales fall back to their language,
+// e.g. "de_AT" resolves "de-at" and then "de", unknown locales resolve the default locale.
+func (g IndexGroup) Resolve(locale string) (IndexID, bool) {
+	if indexID, ok := g.lookup(locale); ok {
+		return indexID, true
+	}
+	return g.lookup(g.DefaultLocale)
+}
+
+// ResolveAcceptLanguage returns the index of the most preferred locale of an Accept-Language header value
+func (g IndexGroup) ResolveAcceptLanguage(header string) (IndexID, bool) {
+	for _, locale := range parseAcceptLanguage(header) {
+		if indexID, ok := g.lookup(locale); ok {
+			return indexID, true
+		}
+	}
+	return g.lookup(g.DefaultLocale)
+}
+
+func (g IndexGroup) lookup(locale string) (IndexID, bool) {
+	locale = normalizeLocale(locale)
+	if locale == "" {
+		return "", false
+	}
+	if indexID, ok := g.Indices[locale]; ok {
+		return indexID, true
+	}
+	if language, _, ok := strings.Cut(locale, "-"); ok {
+		indexID, ok := g.Indices[language]
+		return indexID, ok
+	}
+	return "", false
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// parseAcceptLanguage returns the locales of the header ordered by their quality
+func parseAcceptLanguage(header string) []string {
+	type weightedLocale struct {
+		locale  string
+		quality float64
+	}
+
+	var locales []weightedLocale
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		if quality > 0 {
+			locales = append(locales, weightedLocale{locale: locale, quality: quality})
+		}
+	}
+
+	sort.SliceStable(locales, func(i, j int) bool {
+		return locales[i].quality > locales[j].quality
+	})
+	result := make([]string, len(locales))
+	for i, l := range locales {
+		result[i] = l.locale
+	}
+	return result
+}
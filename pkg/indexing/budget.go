@@ -0,0 +1,141 @@
+package typesenseindexing
+
+import (
+	"context"
+	"reflect"
+	"slices"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// pagedProvisioning checks if the documents of the index are provided page by page,
+// because paged indexing or a memory budget is configured
+func (b *BaseIndexer[indexDocument, returnType]) pagedProvisioning(indexID pkgx.IndexID) bool {
+	if b.opts.pagedIndexing && (len(b.opts.pagedIndices) == 0 || slices.Contains(b.opts.pagedIndices, indexID)) {
+		return true
+	}
+	return b.opts.maxDocumentsInMemory > 0 || b.opts.maxBytesInMemory > 0
+}
+
+// upsertPaged provides the documents of the index page by page and holds the pages until they exceed the memory
+// budget, so at most the budget and one page are held in memory. Without a budget every page is upserted at once.
+func (b *BaseIndexer[indexDocument, returnType]) upsertPaged(
+	ctx context.Context,
+	progress *progressTracker,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	routed map[pkgx.IndexID][]*indexDocument,
+) (int, error) {
+	l := pkgx.Logger(ctx, b.l)
+	progress.start(indexID, 0)
+	defer progress.finish(ctx)
+
+	maxDocuments, maxBytes := b.opts.maxDocumentsInMemory, b.opts.maxBytesInMemory
+	var held []*indexDocument
+	var heldBytes int64
+	exceeded := false
+	flush := func() error {
+		if err := b.upsertBatches(ctx, progress, revisionID, indexID, held); err != nil {
+			return err
+		}
+		held, heldBytes = nil, 0
+		return nil
+	}
+
+	count, pages := 0, 0
+	for offset := 0; ; {
+		documents, nextOffset, err := b.documentProvider.ProvidePaged(ctx, indexID, offset)
+		if err != nil {
+			l.Error("failed to fetch documents", zap.String("index", string(indexID)), zap.Int("offset", offset), zap.Error(err))
+			return count, err
+		}
+		documents = slices.DeleteFunc(documents, func(document *indexDocument) bool { return document == nil })
+		routeDocuments(indexID, documents, routed)
+		progress.grow(len(documents))
+		held = append(held, documents...)
+		if maxBytes > 0 {
+			for _, document := range documents {
+				heldBytes += estimateSize(reflect.ValueOf(document))
+			}
+		}
+		if (maxDocuments <= 0 && maxBytes <= 0) ||
+			(maxDocuments > 0 && len(held) > maxDocuments) ||
+			(maxBytes > 0 && heldBytes > maxBytes) {
+			if !exceeded && (maxDocuments > 0 || maxBytes > 0) {
+				exceeded = true
+				l.Info("documents exceed the memory budget, upserting held pages",
+					zap.String("index", string(indexID)),
+					zap.Int("max_documents", maxDocuments),
+					zap.Int64("max_bytes", maxBytes),
+				)
+			}
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+		count += len(documents)
+		pages++
+		if nextOffset <= offset {
+			break
+		}
+		offset = nextOffset
+	}
+
+	// documents routed to the index by indices that have already been processed
+	documents := routed[indexID]
+	delete(routed, indexID)
+	progress.grow(len(documents))
+	held = append(held, documents...)
+	if err := flush(); err != nil {
+		return count, err
+	}
+	count += len(documents)
+
+	l.Info("successfully upserted paged documents",
+		zap.String("index", string(indexID)),
+		zap.Int("count", count),
+		zap.Int("pages", pages),
+	)
+	return count, nil
+}
+
+// estimateSize estimates the memory held by a value by walking it instead of encoding it,
+// strings and byte slices are counted by their length, every other scalar by its size
+func estimateSize(v reflect.Value) int64 {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return 0
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return estimateSize(v.Elem())
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Struct:
+		var size int64
+		for i := range v.NumField() {
+			size += estimateSize(v.Field(i))
+		}
+		return size
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return int64(v.Len())
+		}
+		var size int64
+		for i := range v.Len() {
+			size += estimateSize(v.Index(i))
+		}
+		return size
+	case reflect.Map:
+		var size int64
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateSize(iter.Key()) + estimateSize(iter.Value())
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
@@ -2,9 +2,12 @@ package typesenseindexing
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
 	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	contentserverclient "github.com/foomo/contentserver/client"
 	"github.com/foomo/contentserver/content"
@@ -14,11 +17,64 @@ import (
 
 const ContentserverDataAttributeNoIndex = "typesenseIndexing-noIndex"
 
+// defaultContentServerPageSize is the number of documents provided per page by ProvidePaged
+const defaultContentServerPageSize = 500
+
 type ContentServer[indexDocument any] struct {
 	l                     *zap.Logger
 	contentserverClient   *contentserverclient.Client
 	documentProviderFuncs map[pkgx.DocumentType]pkgx.DocumentProviderFunc[indexDocument]
 	supportedMimeTypes    []string
+	urlResolver           pkgx.URLResolver
+	limiter               *callLimiter
+	pageSize              int
+	nodeReportSink        NodeReportSink
+	documentTimeout       time.Duration
+	indexTimeout          time.Duration
+	pages                 *pagedDocumentInfos
+}
+
+// pagedDocumentInfos holds the sorted document infos of the indices provided page by page, so the node tree is
+// fetched and sorted once per run instead of once per page
+type pagedDocumentInfos struct {
+	mu    sync.Mutex
+	infos map[pkgx.IndexID][]pkgx.DocumentInfo
+}
+
+// ContentServerOption configures the ContentServer
+type ContentServerOption[indexDocument any] func(c *ContentServer[indexDocument])
+
+// WithURLResolver replaces the URL resolution of the contentserver, e.g. with permalinks of another source
+func WithURLResolver[indexDocument any](resolver pkgx.URLResolver) ContentServerOption[indexDocument] {
+	return func(c *ContentServer[indexDocument]) {
+		c.urlResolver = resolver
+	}
+}
+
+// WithRateLimit caps the calls to the contentserver, e.g. GetRepo and GetURIs, per second and the concurrent calls,
+// so index runs don't overload a shared contentserver. 0 disables a limit.
+func WithRateLimit[indexDocument any](callsPerSecond float64, maxConcurrent int) ContentServerOption[indexDocument] {
+	return func(c *ContentServer[indexDocument]) {
+		c.limiter = newCallLimiter(callsPerSecond, maxConcurrent)
+	}
+}
+
+// WithPageSize sets the number of documents provided per page by ProvidePaged
+func WithPageSize[indexDocument any](size int) ContentServerOption[indexDocument] {
+	return func(c *ContentServer[indexDocument]) {
+		c.pageSize = size
+	}
+}
+
+// WithProviderTimeouts limits the time a document provider func may take per document and the time all provider funcs
+// of an index may take per call of Provide, ProvideScope or ProvidePaged, 0 disables a limit. Slow providers are
+// cancelled and their nodes are reported as failed with NodeReasonProviderTimeout, so a hanging upstream call can't
+// stall the run. Providers ignoring the context are abandoned, their results are discarded.
+func WithProviderTimeouts[indexDocument any](perDocument, perIndex time.Duration) ContentServerOption[indexDocument] {
+	return func(c *ContentServer[indexDocument]) {
+		c.documentTimeout = perDocument
+		c.indexTimeout = perIndex
+	}
 }
 
 func NewContentServer[indexDocument any](
@@ -26,13 +82,25 @@ func NewContentServer[indexDocument any](
 	client *contentserverclient.Client,
 	documentProviderFuncs map[pkgx.DocumentType]pkgx.DocumentProviderFunc[indexDocument],
 	supportedMimeTypes []string,
+	opts ...ContentServerOption[indexDocument],
 ) *ContentServer[indexDocument] {
-	return &ContentServer[indexDocument]{
+	c := &ContentServer[indexDocument]{
 		l:                     l,
 		contentserverClient:   client,
 		documentProviderFuncs: documentProviderFuncs,
 		supportedMimeTypes:    supportedMimeTypes,
+		pageSize:              defaultContentServerPageSize,
+		pages:                 &pagedDocumentInfos{infos: map[pkgx.IndexID][]pkgx.DocumentInfo{}},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	if c.urlResolver == nil {
+		c.urlResolver = &contentserverURLResolver{l: l, client: client, limiter: c.limiter}
 	}
+	return c
 }
 
 // Provide retrieves documents for the given indexID from the content server.
@@ -45,75 +113,265 @@ func (c ContentServer[indexDocument]) Provide(
 	ctx context.Context,
 	indexID pkgx.IndexID,
 ) ([]*indexDocument, error) {
-	documentInfos, err := c.getDocumentIDsByIndexID(ctx, indexID)
+	documentInfos, skipped, err := c.getDocumentIDsByIndexID(ctx, indexID, "")
 	if err != nil {
 		return nil, err
 	}
+	documents, failed, err := c.provideDocuments(ctx, indexID, documentInfos)
+	c.reportNodes(ctx, indexID, append(skipped, failed...))
+	return documents, err
+}
 
-	urlsByIDs, err := c.fetchURLsByDocumentIDs(ctx, indexID, documentInfos)
+// ProvideScope provides the documents of the subtree of the node whose ID matches the selector of the scope,
+// if no node matches, the documents whose URI starts with the selector are provided
+func (c ContentServer[indexDocument]) ProvideScope(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	scope pkgx.Scope,
+) ([]*indexDocument, error) {
+	documentInfos, skipped, err := c.getDocumentIDsByIndexID(ctx, indexID, scope.Selector)
 	if err != nil {
 		return nil, err
 	}
+	documents, failed, err := c.provideDocuments(ctx, indexID, documentInfos)
+	c.reportNodes(ctx, indexID, append(skipped, failed...))
+	return documents, err
+}
+
+// provideDocuments creates the documents with the document provider functions
+// and returns the nodes no document was created for
+func (c ContentServer[indexDocument]) provideDocuments(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documentInfos []pkgx.DocumentInfo,
+) ([]*indexDocument, []SkippedNode, error) {
+	urlsByIDs, err := c.fetchURLsByDocumentIDs(ctx, indexID, documentInfos)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	documents := make([]*indexDocument, len(documentInfos))
-	for index, documentInfo := range documentInfos {
+	providerCtx := ctx
+	if c.indexTimeout > 0 {
+		var cancel context.CancelFunc
+		providerCtx, cancel = context.WithTimeout(ctx, c.indexTimeout)
+		defer cancel()
+	}
+
+	var failed []SkippedNode
+	documents := make([]*indexDocument, 0, len(documentInfos))
+	for _, documentInfo := range documentInfos {
+		node := SkippedNode{
+			ID:       string(documentInfo.DocumentID),
+			URI:      urlsByIDs[documentInfo.DocumentID],
+			MimeType: string(documentInfo.DocumentType),
+		}
 		if documentProvider, ok := c.documentProviderFuncs[documentInfo.DocumentType]; !ok {
 			c.l.Warn(
 				"no document provider available for document type",
 				zap.String("documentType", string(documentInfo.DocumentType)),
 			)
+			node.Reason = NodeReasonNoDocumentProvider
+			failed = append(failed, node)
 		} else {
-			document, err := documentProvider(ctx, indexID, documentInfo.DocumentID, urlsByIDs)
+			document, err := c.callProvider(providerCtx, documentProvider, indexID, documentInfo.DocumentID, urlsByIDs)
 			if err != nil {
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, nil, ctxErr
+				}
 				c.l.Error(
 					"index document not created",
 					zap.Error(err),
 					zap.String("documentID", string(documentInfo.DocumentID)),
 					zap.String("documentType", string(documentInfo.DocumentType)),
 				)
+				node.Reason, node.Error = NodeReasonProviderError, err.Error()
+				if errors.Is(err, context.DeadlineExceeded) {
+					node.Reason = NodeReasonProviderTimeout
+				}
+				failed = append(failed, node)
 				continue
 			}
 			if document != nil {
-				documents[index] = document
+				documents = append(documents, document)
+			} else {
+				node.Reason = NodeReasonNoDocument
+				failed = append(failed, node)
 			}
 		}
 	}
-	return documents, nil
+	return documents, failed, nil
+}
+
+// callProvider calls the document provider func with the document timeout and gives up once the context is done,
+// even if the provider ignores it
+func (c ContentServer[indexDocument]) callProvider(
+	ctx context.Context,
+	documentProvider pkgx.DocumentProviderFunc[indexDocument],
+	indexID pkgx.IndexID,
+	documentID pkgx.DocumentID,
+	urlsByIDs map[pkgx.DocumentID]string,
+) (*indexDocument, error) {
+	if c.documentTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.documentTimeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		return documentProvider(ctx, indexID, documentID, urlsByIDs)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		document *indexDocument
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		document, err := documentProvider(ctx, indexID, documentID, urlsByIDs)
+		done <- result{document: document, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.document, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// reportNodes sends the report of the skipped and failed nodes to the sink, failures are logged only
+func (c ContentServer[indexDocument]) reportNodes(ctx context.Context, indexID pkgx.IndexID, nodes []SkippedNode) {
+	if c.nodeReportSink == nil {
+		return
+	}
+	report := NodeReport{IndexID: indexID, ReportedAt: time.Now(), Nodes: nodes}
+	if report.Nodes == nil {
+		report.Nodes = []SkippedNode{}
+	}
+	if err := c.nodeReportSink.ReportNodes(ctx, report); err != nil {
+		pkgx.Logger(ctx, c.l).Warn("failed to report skipped nodes", zap.String("index", string(indexID)), zap.Error(err))
+	}
 }
 
-// ProvidePaged
+// ProvidePaged provides one page of the documents ordered by document ID, starting at the offset. It returns the
+// offset of the next page or 0 after the last page. Only the documents of the page are created and their URLs resolved.
+// The node tree is fetched with the first page and kept until the last page, so all pages of a run page through the
+// same snapshot of the tree.
 func (c ContentServer[indexDocument]) ProvidePaged(
 	ctx context.Context,
 	indexID pkgx.IndexID,
 	offset int,
 ) ([]*indexDocument, int, error) {
-	panic("implement me")
+	documentInfos, skipped, err := c.pagedDocumentInfos(ctx, indexID, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if offset < 0 || offset >= len(documentInfos) {
+		c.pages.forget(indexID)
+		return nil, 0, nil
+	}
+
+	pageSize := c.pageSize
+	if pageSize <= 0 {
+		pageSize = defaultContentServerPageSize
+	}
+	nextOffset := offset + pageSize
+	if nextOffset >= len(documentInfos) {
+		documentInfos, nextOffset = documentInfos[offset:], 0
+		c.pages.forget(indexID)
+	} else {
+		documentInfos = documentInfos[offset:nextOffset]
+	}
+
+	documents, failed, err := c.provideDocuments(ctx, indexID, documentInfos)
+	c.reportNodes(ctx, indexID, append(skipped, failed...))
+	if err != nil {
+		c.pages.forget(indexID)
+		return nil, 0, err
+	}
+	return documents, nextOffset, nil
+}
+
+// pagedDocumentInfos returns the document infos sorted by document ID, they are fetched for the first page and
+// pages requested without a preceding first page and kept for the following pages. The skipped nodes are returned
+// with the first page only.
+func (c ContentServer[indexDocument]) pagedDocumentInfos(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	offset int,
+) ([]pkgx.DocumentInfo, []SkippedNode, error) {
+	c.pages.mu.Lock()
+	documentInfos, ok := c.pages.infos[indexID]
+	c.pages.mu.Unlock()
+	if ok && offset > 0 {
+		return documentInfos, nil, nil
+	}
+
+	documentInfos, skipped, err := c.getDocumentIDsByIndexID(ctx, indexID, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	slices.SortFunc(documentInfos, func(a, b pkgx.DocumentInfo) int {
+		return strings.Compare(string(a.DocumentID), string(b.DocumentID))
+	})
+	c.pages.mu.Lock()
+	c.pages.infos[indexID] = documentInfos
+	c.pages.mu.Unlock()
+	if offset > 0 {
+		skipped = nil
+	}
+	return documentInfos, skipped, nil
+}
+
+// forget drops the document infos of the index after its last page
+func (p *pagedDocumentInfos) forget(indexID pkgx.IndexID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.infos, indexID)
 }
 
 func (c ContentServer[indexDocument]) getDocumentIDsByIndexID(
 	ctx context.Context,
 	indexID pkgx.IndexID,
-) ([]pkgx.DocumentInfo, error) {
+	selector string,
+) ([]pkgx.DocumentInfo, []SkippedNode, error) {
 	// get the contentserver dimension defined by indexID
 	// create the list of document infos
+	release, err := c.limiter.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	repo, err := c.contentserverClient.GetRepo(ctx)
+	release()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	rootRepoNode, ok := repo[string(indexID)]
 	if !ok {
-		return nil, fmt.Errorf("contenserver dimension %s not found", indexID)
+		return nil, nil, fmt.Errorf("contenserver dimension %s not found", indexID)
 	}
 
 	nodeMap := createFlatRepoNodeMap(rootRepoNode, map[string]*content.RepoNode{})
+	if selector != "" {
+		nodeMap = selectRepoNodes(nodeMap, selector)
+	}
 	documentInfos := make([]pkgx.DocumentInfo, 0, len(nodeMap))
+	var skipped []SkippedNode
 	for _, repoNode := range nodeMap {
-		if !includeNode(c.supportedMimeTypes, repoNode) {
+		if reason := skipReason(c.supportedMimeTypes, repoNode); reason != "" {
 			c.l.Debug("skipping document indexing",
 				zap.String("path", repoNode.URI),
 				zap.String("mimeType", repoNode.MimeType),
 				zap.Bool("hidden", repoNode.Hidden),
+				zap.String("reason", reason),
 			)
+			skipped = append(skipped, SkippedNode{
+				ID:       repoNode.ID,
+				URI:      repoNode.URI,
+				MimeType: repoNode.MimeType,
+				Reason:   reason,
+			})
 			continue
 		}
 
@@ -123,25 +381,47 @@ func (c ContentServer[indexDocument]) getDocumentIDsByIndexID(
 		})
 	}
 
-	return documentInfos, nil
+	return documentInfos, skipped, nil
 }
 
-// fetchURLsByDocumentIDs fetches the URLs for the given document IDs from the content server.
-// It uses the contentserverClient to retrieve the URIs and maps them to DocumentID.
+// fetchURLsByDocumentIDs resolves the URLs of the given documents with the URL resolver
 func (c ContentServer[indexDocument]) fetchURLsByDocumentIDs(
 	ctx context.Context,
 	indexID pkgx.IndexID,
 	documentInfos []pkgx.DocumentInfo,
 ) (map[pkgx.DocumentID]string, error) {
-	ids := make([]string, len(documentInfos))
-
+	documentIDs := make([]pkgx.DocumentID, len(documentInfos))
 	for i, documentInfo := range documentInfos {
-		ids[i] = string(documentInfo.DocumentID)
+		documentIDs[i] = documentInfo.DocumentID
+	}
+	return c.urlResolver.ResolveURLs(ctx, indexID, documentIDs)
+}
+
+// contentserverURLResolver fetches the URIs of the documents from the content server
+type contentserverURLResolver struct {
+	l       *zap.Logger
+	client  *contentserverclient.Client
+	limiter *callLimiter
+}
+
+func (r *contentserverURLResolver) ResolveURLs(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documentIDs []pkgx.DocumentID,
+) (map[pkgx.DocumentID]string, error) {
+	ids := make([]string, len(documentIDs))
+	for i, documentID := range documentIDs {
+		ids[i] = string(documentID)
 	}
 
-	uriMap, err := c.contentserverClient.GetURIs(ctx, string(indexID), ids)
+	release, err := r.limiter.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	uriMap, err := r.client.GetURIs(ctx, string(indexID), ids)
+	release()
 	if err != nil {
-		c.l.Error("failed to get URIs", zap.Error(err))
+		pkgx.Logger(ctx, r.l).Error("failed to get URIs", zap.Error(err))
 		return nil, err
 	}
 
@@ -158,20 +438,31 @@ func convertMapStringToDocumentID(input map[string]string) map[pkgx.DocumentID]s
 	return output
 }
 
-// includeNode checks if the node should be included in the indexing process.
-// It checks if the node is nil, if it has the noIndex attribute set to true,
-// and if its mime type is in the list of supported mime types.
-func includeNode(supportedMimeTypes []string, node *content.RepoNode) bool {
-	if node == nil {
-		return false
-	}
+// skipReason returns why the node is excluded from the indexing process or "" if it is included.
+// Nodes with the noIndex attribute set to true and nodes whose mime type is not in the list of
+// supported mime types are excluded.
+func skipReason(supportedMimeTypes []string, node *content.RepoNode) string {
 	if noIndex, noIndexSet := node.Data[ContentserverDataAttributeNoIndex].(bool); noIndexSet && noIndex {
-		return false
+		return NodeReasonNoIndex
 	}
 	if !slices.Contains(supportedMimeTypes, node.MimeType) {
-		return false
+		return NodeReasonUnsupportedMimeType
+	}
+	return ""
+}
+
+// selectRepoNodes returns the subtree of the node with the selector as ID or the nodes whose URI starts with the selector
+func selectRepoNodes(nodeMap map[string]*content.RepoNode, selector string) map[string]*content.RepoNode {
+	if node, ok := nodeMap[selector]; ok {
+		return createFlatRepoNodeMap(node, map[string]*content.RepoNode{})
+	}
+	selected := map[string]*content.RepoNode{}
+	for id, node := range nodeMap {
+		if strings.HasPrefix(node.URI, selector) {
+			selected[id] = node
+		}
 	}
-	return true
+	return selected
 }
 
 // createFlatRepoNodeMap recursively retrieves all nodes from the tree and returns them in a flat map.
@@ -0,0 +1,206 @@
+package typesenseindexing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// RunHistoryCollectionName is the collection holding one document per indexer run, see CollectionRunHistory
+const RunHistoryCollectionName = "_index_runs"
+
+// maxHistoryPageSize is the maximum number of documents typesense returns per page
+const maxHistoryPageSize = 250
+
+// RunStatus is the outcome of an indexer run
+type RunStatus string
+
+const (
+	RunStatusCommitted RunStatus = "committed"
+	// RunStatusPartiallyCommitted is the status of runs committed to all indices except the pinned ones
+	RunStatusPartiallyCommitted RunStatus = "partially_committed"
+	RunStatusReverted           RunStatus = "reverted"
+	RunStatusRefreshed          RunStatus = "refreshed"
+	RunStatusFailed             RunStatus = "failed"
+)
+
+// RunReport describes a finished indexer run and the reports of its indices
+type RunReport struct {
+	ID         string          `json:"id"`
+	RevisionID pkgx.RevisionID `json:"revision,omitempty"`
+	Status     RunStatus       `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  int64           `json:"started_at"`
+	Duration   time.Duration   `json:"duration"`
+	Documents  int             `json:"documents"`
+	Failed     int             `json:"failed"`
+	// Indices holds the reports of the indices without samples
+	Indices []IndexReport `json:"indices"`
+}
+
+// Succeeded checks if the run changed the served documents
+func (r RunReport) Succeeded() bool {
+	return r.Status == RunStatusCommitted || r.Status == RunStatusPartiallyCommitted || r.Status == RunStatusRefreshed
+}
+
+// Index returns the report of the given index in the run
+func (r RunReport) Index(indexID pkgx.IndexID) (IndexReport, bool) {
+	for _, report := range r.Indices {
+		if report.IndexID == indexID {
+			return report, true
+		}
+	}
+	return IndexReport{}, false
+}
+
+// RunHistory persists the reports of indexer runs
+type RunHistory interface {
+	SaveRun(ctx context.Context, run RunReport) error
+	// History returns the latest runs first, up to limit
+	History(ctx context.Context, limit int) ([]RunReport, error)
+}
+
+// CollectionRunHistory stores the run reports in the RunHistoryCollectionName collection of the cluster
+type CollectionRunHistory struct {
+	l      *zap.Logger
+	client *typesense.Client
+}
+
+func NewCollectionRunHistory(l *zap.Logger, client *typesense.Client) *CollectionRunHistory {
+	return &CollectionRunHistory{
+		l:      l,
+		client: client,
+	}
+}
+
+func (h *CollectionRunHistory) SaveRun(ctx context.Context, run RunReport) error {
+	l := pkgx.Logger(ctx, h.l)
+	_, err := h.client.Collection(RunHistoryCollectionName).Documents().Upsert(ctx, run, &api.DocumentIndexParameters{})
+	if isHTTPStatus(err, http.StatusNotFound) {
+		if err := h.createCollection(ctx); err != nil {
+			return err
+		}
+		_, err = h.client.Collection(RunHistoryCollectionName).Documents().Upsert(ctx, run, &api.DocumentIndexParameters{})
+	}
+	if err != nil {
+		l.Error("failed to save indexer run", zap.String("run", run.ID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (h *CollectionRunHistory) History(ctx context.Context, limit int) ([]RunReport, error) {
+	l := pkgx.Logger(ctx, h.l)
+	if limit < 1 || limit > maxHistoryPageSize {
+		limit = maxHistoryPageSize
+	}
+	response, err := h.client.Collection(RunHistoryCollectionName).Documents().Search(ctx, &api.SearchCollectionParams{
+		Q:       pointer.String("*"),
+		SortBy:  pointer.String("started_at:desc"),
+		PerPage: pointer.Int(limit),
+	})
+	if isHTTPStatus(err, http.StatusNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		l.Error("failed to retrieve indexer runs", zap.Error(err))
+		return nil, err
+	}
+	if response.Hits == nil {
+		return nil, nil
+	}
+
+	runs := make([]RunReport, 0, len(*response.Hits))
+	for _, hit := range *response.Hits {
+		if hit.Document == nil {
+			continue
+		}
+		data, err := json.Marshal(*hit.Document)
+		if err != nil {
+			return nil, err
+		}
+		var run RunReport
+		if err := json.Unmarshal(data, &run); err != nil {
+			l.Warn("invalid indexer run", zap.Error(err))
+			continue
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// createCollection creates the history collection, tolerating concurrent creation
+func (h *CollectionRunHistory) createCollection(ctx context.Context) error {
+	_, err := h.client.Collections().Create(ctx, &api.CollectionSchema{
+		Name: RunHistoryCollectionName,
+		Fields: []api.Field{
+			{Name: "status", Type: "string", Facet: pointer.True()},
+			{Name: "started_at", Type: "int64", Sort: pointer.True()},
+		},
+	})
+	if err != nil && !isHTTPStatus(err, http.StatusConflict) {
+		pkgx.Logger(ctx, h.l).Error("failed to create run history collection", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// History returns the latest runs of the indexer first, see WithRunHistory
+func (b *BaseIndexer[indexDocument, returnType]) History(ctx context.Context, limit int) ([]RunReport, error) {
+	if b.opts.runHistory == nil {
+		return nil, errors.New("run history is not configured")
+	}
+	return b.opts.runHistory.History(ctx, limit)
+}
+
+// recordRun completes the report of the run and saves it, failures to save it are logged only
+func (b *BaseIndexer[indexDocument, returnType]) recordRun(
+	ctx context.Context,
+	progress *progressTracker,
+	started time.Time,
+	run *RunReport,
+	err error,
+) {
+	if b.opts.runHistory == nil {
+		return
+	}
+	run.StartedAt = started.Unix()
+	run.Duration = time.Since(started)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	if err != nil || run.Status == "" {
+		run.Status = RunStatusFailed
+	}
+
+	progress.mu.Lock()
+	for _, report := range progress.reports {
+		report.Sample = nil
+		run.Indices = append(run.Indices, report)
+		run.Documents += report.Upserted
+		run.Failed += report.Failed
+	}
+	progress.mu.Unlock()
+	sort.Slice(run.Indices, func(i, j int) bool {
+		return run.Indices[i].IndexID < run.Indices[j].IndexID
+	})
+
+	if err := b.opts.runHistory.SaveRun(context.WithoutCancel(ctx), *run); err != nil {
+		pkgx.Logger(ctx, b.l).Warn("failed to record indexer run", zap.String("run", run.ID), zap.Error(err))
+	}
+}
+
+// isHTTPStatus checks if the typesense request failed with the given status
+func isHTTPStatus(err error, status int) bool {
+	var httpErr *typesense.HTTPError
+	return errors.As(err, &httpErr) && httpErr.Status == status
+}
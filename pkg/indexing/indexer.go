@@ -2,6 +2,11 @@ package typesenseindexing
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	pkgx "github.com/foomo/typesense/pkg"
 	"go.uber.org/zap"
@@ -11,92 +16,339 @@ type BaseIndexer[indexDocument any, returnType any] struct {
 	l                *zap.Logger
 	typesenseAPI     pkgx.API[indexDocument, returnType]
 	documentProvider pkgx.DocumentProvider[indexDocument]
+	opts             options
 }
 
 func NewBaseIndexer[indexDocument any, returnType any](
 	l *zap.Logger,
 	typesenseAPI pkgx.API[indexDocument, returnType],
 	documentProvider pkgx.DocumentProvider[indexDocument],
+	opts ...Option,
 ) *BaseIndexer[indexDocument, returnType] {
 	return &BaseIndexer[indexDocument, returnType]{
 		l:                l,
 		typesenseAPI:     typesenseAPI,
 		documentProvider: documentProvider,
+		opts:             newOptions(opts...),
 	}
 }
 
+type runIDContextKey struct{}
+
+func withRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDContextKey{}, runID)
+}
+
+// RunID returns the ID of the indexer run providing the documents, e.g. for stages keeping state across batches
+func RunID(ctx context.Context) string {
+	runID, _ := ctx.Value(runIDContextKey{}).(string)
+	return runID
+}
+
 func (b *BaseIndexer[indexDocument, returnType]) Healthz(ctx context.Context) error {
 	return b.typesenseAPI.Healthz(ctx)
 }
 
 func (b *BaseIndexer[indexDocument, returnType]) Run(ctx context.Context) error {
-	// Step 1: Ensure Typesense is initialized
-	revisionID, err := b.typesenseAPI.Initialize(ctx)
+	started := time.Now()
+	progress := &progressTracker{l: b.l, reporter: b.opts.progressReporter}
+	run := &RunReport{ID: strconv.FormatInt(started.UnixNano(), 10)}
+	ctx = withRunID(ctx, run.ID)
+	var err error
+	if b.opts.inPlaceRefresh {
+		err = b.refreshInPlace(ctx, progress, run)
+	} else {
+		err = b.run(ctx, progress, run)
+	}
+	b.recordRun(ctx, progress, started, run, err)
+	return err
+}
+
+// run indexes all documents into a new revision and commits it unless errors occurred
+func (b *BaseIndexer[indexDocument, returnType]) run(ctx context.Context, progress *progressTracker, run *RunReport) error {
+	l := pkgx.Logger(ctx, b.l)
+	// Step 1: Create a new revision
+	revisionID, err := b.typesenseAPI.NewRevision(ctx)
 	if err != nil || revisionID == "" {
-		b.l.Error("failed to initialize typesense", zap.Error(err))
+		l.Error("failed to create new revision", zap.Error(err))
 		return err
 	}
+	run.RevisionID = revisionID
 
 	// Step 2: Retrieve all configured indices
 	indices, err := b.typesenseAPI.Indices()
 	if err != nil {
-		b.l.Error("failed to retrieve indices from typesense", zap.Error(err))
+		l.Error("failed to retrieve indices from typesense", zap.Error(err))
 		return err
 	}
 
-	// Step 3: Track errors while upserting
+	// Step 3: Track errors and progress while upserting
 	tainted := false
+	if b.opts.heartbeatInterval > 0 {
+		heartbeatCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go progress.heartbeat(heartbeatCtx, b.opts.heartbeatInterval)
+	}
 	indexedDocuments := 0
 
+	// documents routed to additional indices by their source documents
+	routed := map[pkgx.IndexID][]*indexDocument{}
+
 	for _, indexID := range indices {
+		// Stream paged indices and indices with a memory budget page by page
+		if b.pagedProvisioning(indexID) {
+			count, err := b.upsertPaged(ctx, progress, revisionID, indexID, routed)
+			if err != nil {
+				tainted = true
+				continue
+			}
+			indexedDocuments += count
+			continue
+		}
+
 		// Fetch documents from the provider
 		documents, err := b.documentProvider.Provide(ctx, indexID)
 		if err != nil {
-			b.l.Error("failed to fetch documents", zap.String("index", string(indexID)), zap.Error(err))
+			l.Error("failed to fetch documents", zap.String("index", string(indexID)), zap.Error(err))
 			tainted = true
 			continue
 		}
+		documents = slices.DeleteFunc(documents, func(document *indexDocument) bool { return document == nil })
 
-		err = b.typesenseAPI.UpsertDocuments(ctx, revisionID, indexID, documents)
-		if err != nil {
-			b.l.Error(
-				"failed to upsert documents",
-				zap.String("index", string(indexID)),
-				zap.String("revision", string(revisionID)),
-				zap.Int("documents", len(documents)),
-				zap.Error(err),
-			)
+		routeDocuments(indexID, documents, routed)
+		documents = append(documents, routed[indexID]...)
+		delete(routed, indexID)
+
+		if err := b.upsertDocuments(ctx, progress, revisionID, indexID, documents); err != nil {
 			tainted = true
 			continue
 		}
+		indexedDocuments += len(documents)
+	}
 
+	// Upsert documents routed to indices that have already been processed
+	for indexID, documents := range routed {
+		if !slices.Contains(indices, indexID) {
+			l.Warn("skipping documents routed to unknown index", zap.String("index", string(indexID)), zap.Int("count", len(documents)))
+			continue
+		}
+		if err := b.upsertDocuments(ctx, progress, revisionID, indexID, documents); err != nil {
+			tainted = true
+			continue
+		}
 		indexedDocuments += len(documents)
-		b.l.Info("successfully upserted documents",
-			zap.String("index", string(indexID)),
-			zap.Int("count", len(documents)),
-		)
 	}
 
-	// Step 4: Commit or Revert the Revision
+	// Step 4: Verify a sample of the revision
+	if !tainted && indexedDocuments > 0 && b.opts.sampleSize > 0 {
+		if err := b.verifySample(ctx, progress, revisionID, indices); err != nil {
+			tainted = true
+		}
+	}
+
+	// Step 5: Validate the revision on the canary aliases
+	if !tainted && indexedDocuments > 0 && b.opts.canaryValidator != nil {
+		if err := b.validateCanary(ctx, revisionID); err != nil {
+			tainted = true
+		}
+	}
+
+	// Step 6: Commit or Revert the Revision
 	if !tainted && indexedDocuments > 0 {
 		// No errors encountered, commit the revision
 		err = b.typesenseAPI.CommitRevision(ctx, revisionID)
-		if err != nil {
-			b.l.Error("failed to commit revision", zap.String("revision", string(revisionID)), zap.Error(err))
+		committed := indices
+		var pinned *pkgx.PinnedError
+		switch {
+		case errors.As(err, &pinned) && err == error(pinned): //nolint:errorlint // other errors fail the commit
+			// Pinned indices keep serving their revision, the other indices are committed
+			committed = slices.DeleteFunc(slices.Clone(indices), func(indexID pkgx.IndexID) bool {
+				return slices.Contains(pinned.Indices, indexID)
+			})
+			l.Warn("partially committed revision", zap.String("revision", string(revisionID)), zap.Error(err))
+			run.Status = RunStatusPartiallyCommitted
+			run.Error = err.Error()
+		case err != nil:
+			l.Error("failed to commit revision", zap.String("revision", string(revisionID)), zap.Error(err))
 			return err
+		default:
+			l.Info("successfully committed revision", zap.String("revision", string(revisionID)))
+			run.Status = RunStatusCommitted
+		}
+
+		for _, extension := range b.opts.extensions {
+			if err := extension.AfterCommit(ctx, revisionID, committed); err != nil {
+				l.Error("indexer extension failed", zap.String("revision", string(revisionID)), zap.Error(err))
+			}
 		}
-		b.l.Info("successfully committed revision", zap.String("revision", string(revisionID)))
 	} else {
 		// If errors occurred, revert the revision
-		b.l.Warn("errors detected during upsert, reverting revision", zap.String("revision", string(revisionID)))
+		l.Warn("errors detected during upsert, reverting revision", zap.String("revision", string(revisionID)))
 
 		err = b.typesenseAPI.RevertRevision(ctx, revisionID)
 		if err != nil {
-			b.l.Error("failed to revert revision", zap.String("revision", string(revisionID)), zap.Error(err))
+			l.Error("failed to revert revision", zap.String("revision", string(revisionID)), zap.Error(err))
+			return err
+		}
+		l.Info("successfully reverted revision", zap.String("revision", string(revisionID)))
+		run.Status = RunStatusReverted
+	}
+
+	return nil
+}
+
+// verifySample samples documents of every index of the revision and runs the configured assertions on them
+func (b *BaseIndexer[indexDocument, returnType]) verifySample(
+	ctx context.Context,
+	progress *progressTracker,
+	revisionID pkgx.RevisionID,
+	indices []pkgx.IndexID,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	sampler, ok := b.typesenseAPI.(pkgx.RevisionSampler[returnType])
+	if !ok {
+		l.Error("typesense api does not support revision sampling, the sample can not be verified")
+		return fmt.Errorf("%w: revision sampling", pkgx.ErrUnsupported)
+	}
+
+	var errs []error
+	for _, indexID := range indices {
+		var sampleErrors []string
+		documents, err := sampler.SampleRevision(ctx, revisionID, indexID, b.opts.sampleSize)
+		if err != nil {
+			sampleErrors = append(sampleErrors, err.Error())
+			errs = append(errs, err)
+		}
+		sample := make([]any, len(documents))
+		for i, document := range documents {
+			sample[i] = document
+			if err := b.opts.sampleAssertion(indexID, document); err != nil {
+				sampleErrors = append(sampleErrors, err.Error())
+				errs = append(errs, err)
+			}
+		}
+		progress.attachSample(ctx, indexID, sample, sampleErrors)
+
+		if len(sampleErrors) > 0 {
+			l.Error("sample verification failed",
+				zap.String("index", string(indexID)),
+				zap.String("revision", string(revisionID)),
+				zap.Strings("errors", sampleErrors),
+			)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validateCanary deploys the revision to the canary aliases and runs the configured validator
+func (b *BaseIndexer[indexDocument, returnType]) validateCanary(ctx context.Context, revisionID pkgx.RevisionID) error {
+	l := pkgx.Logger(ctx, b.l)
+	deployer, ok := b.typesenseAPI.(pkgx.CanaryDeployer)
+	if !ok {
+		l.Error("typesense api does not support canary aliases, the canary validator can not run")
+		return fmt.Errorf("%w: canary aliases", pkgx.ErrUnsupported)
+	}
+	if err := deployer.DeployCanary(ctx, revisionID); err != nil {
+		l.Error("failed to deploy canary", zap.String("revision", string(revisionID)), zap.Error(err))
+		return err
+	}
+	if err := b.opts.canaryValidator(ctx, revisionID); err != nil {
+		l.Error("canary validation failed", zap.String("revision", string(revisionID)), zap.Error(err))
+		return err
+	}
+	l.Info("canary validation succeeded", zap.String("revision", string(revisionID)))
+	return nil
+}
+
+// upsertDocuments upserts the documents in batches and reports the progress after every batch
+func (b *BaseIndexer[indexDocument, returnType]) upsertDocuments(
+	ctx context.Context,
+	progress *progressTracker,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	progress.start(indexID, len(documents))
+	defer progress.finish(ctx)
+
+	if err := b.upsertBatches(ctx, progress, revisionID, indexID, documents); err != nil {
+		return err
+	}
+
+	l.Info("successfully upserted documents",
+		zap.String("index", string(indexID)),
+		zap.Int("count", len(documents)),
+	)
+	return nil
+}
+
+// upsertBatches upserts the documents in batches and records the progress of every batch
+func (b *BaseIndexer[indexDocument, returnType]) upsertBatches(
+	ctx context.Context,
+	progress *progressTracker,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	batchSize := b.opts.batchSize
+	if batchSize < 1 {
+		batchSize = len(documents)
+	}
+
+	for start := 0; start < len(documents); start += batchSize {
+		end := min(start+batchSize, len(documents))
+		err := b.typesenseAPI.UpsertDocuments(ctx, revisionID, indexID, documents[start:end])
+		if err != nil {
+			progress.add(ctx, 0, end-start)
+			l.Error(
+				"failed to upsert documents",
+				zap.String("index", string(indexID)),
+				zap.String("revision", string(revisionID)),
+				zap.Int("documents", end-start),
+				zap.Error(err),
+			)
 			return err
 		}
-		b.l.Info("successfully reverted revision", zap.String("revision", string(revisionID)))
+		progress.add(ctx, end-start, 0)
+	}
+	return nil
+}
+
+// ReindexScope rebuilds a part of an index in place: the documents of the scope are provided, upserted into the
+// collection served by the alias and the documents of the scope that no longer exist are deleted, e.g. after a
+// section of the site has been restructured. The provider has to implement pkgx.ScopedDocumentProvider and the API
+// pkgx.ScopeReplacer.
+func (b *BaseIndexer[indexDocument, returnType]) ReindexScope(ctx context.Context, indexID pkgx.IndexID, scope pkgx.Scope) error {
+	l := pkgx.Logger(ctx, b.l)
+	provider, ok := b.documentProvider.(pkgx.ScopedDocumentProvider[indexDocument])
+	if !ok {
+		return errors.New("document provider does not support scopes")
+	}
+	replacer, ok := b.typesenseAPI.(pkgx.ScopeReplacer[indexDocument])
+	if !ok {
+		return errors.New("typesense api does not support scopes")
+	}
+
+	documents, err := provider.ProvideScope(ctx, indexID, scope)
+	if err != nil {
+		l.Error("failed to fetch documents of scope", zap.String("index", string(indexID)), zap.String("scope", scope.Selector), zap.Error(err))
+		return err
 	}
+	documents = slices.DeleteFunc(documents, func(document *indexDocument) bool { return document == nil })
 
+	if err := replacer.ReplaceScope(ctx, indexID, scope, documents); err != nil {
+		l.Error("failed to replace scope", zap.String("index", string(indexID)), zap.String("scope", scope.Selector), zap.Error(err))
+		return err
+	}
+	for _, extension := range b.opts.extensions {
+		if updateExtension, ok := extension.(pkgx.IndexerUpdateExtension); ok {
+			if err := updateExtension.AfterUpdate(ctx, indexID); err != nil {
+				l.Error("indexer extension failed", zap.String("index", string(indexID)), zap.Error(err))
+			}
+		}
+	}
+	l.Info("reindexed scope", zap.String("index", string(indexID)), zap.String("scope", scope.Selector), zap.Int("count", len(documents)))
 	return nil
 }
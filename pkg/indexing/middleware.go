@@ -0,0 +1,74 @@
+package typesenseindexing
+
+import (
+	"context"
+	"errors"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// DocumentStageFunc processes a batch of provided documents before they are upserted.
+// Batches may contain nil entries for documents that could not be provided.
+type DocumentStageFunc[indexDocument any] func(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) ([]*indexDocument, error)
+
+// ChainDocumentProvider wraps the provider with the given middlewares, the first middleware being the outermost one
+func ChainDocumentProvider[indexDocument any](
+	provider pkgx.DocumentProvider[indexDocument],
+	middlewares ...pkgx.DocumentProviderMiddleware[indexDocument],
+) pkgx.DocumentProvider[indexDocument] {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		provider = middlewares[i](provider)
+	}
+	return provider
+}
+
+// NewDocumentStage returns a middleware that applies the stage to every batch returned by Provide and ProvidePaged
+func NewDocumentStage[indexDocument any](stage DocumentStageFunc[indexDocument]) pkgx.DocumentProviderMiddleware[indexDocument] {
+	return func(next pkgx.DocumentProvider[indexDocument]) pkgx.DocumentProvider[indexDocument] {
+		return &documentStage[indexDocument]{
+			next:  next,
+			stage: stage,
+		}
+	}
+}
+
+type documentStage[indexDocument any] struct {
+	next  pkgx.DocumentProvider[indexDocument]
+	stage DocumentStageFunc[indexDocument]
+}
+
+func (s *documentStage[indexDocument]) Provide(ctx context.Context, indexID pkgx.IndexID) ([]*indexDocument, error) {
+	documents, err := s.next.Provide(ctx, indexID)
+	if err != nil {
+		return nil, err
+	}
+	return s.stage(ctx, indexID, documents)
+}
+
+func (s *documentStage[indexDocument]) ProvidePaged(ctx context.Context, indexID pkgx.IndexID, offset int) ([]*indexDocument, int, error) {
+	documents, nextOffset, err := s.next.ProvidePaged(ctx, indexID, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	documents, err = s.stage(ctx, indexID, documents)
+	if err != nil {
+		return nil, 0, err
+	}
+	return documents, nextOffset, nil
+}
+
+func (s *documentStage[indexDocument]) ProvideScope(ctx context.Context, indexID pkgx.IndexID, scope pkgx.Scope) ([]*indexDocument, error) {
+	next, ok := s.next.(pkgx.ScopedDocumentProvider[indexDocument])
+	if !ok {
+		return nil, errors.New("document provider does not support scopes")
+	}
+	documents, err := next.ProvideScope(ctx, indexID, scope)
+	if err != nil {
+		return nil, err
+	}
+	return s.stage(ctx, indexID, documents)
+}
@@ -0,0 +1,73 @@
+package typesenseindexing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// Reasons why a contentserver node is not indexed
+const (
+	NodeReasonNoIndex             = "no_index"
+	NodeReasonUnsupportedMimeType = "unsupported_mime_type"
+	NodeReasonNoDocumentProvider  = "no_document_provider"
+	NodeReasonProviderError       = "provider_error"
+	NodeReasonProviderTimeout     = "provider_timeout"
+	NodeReasonNoDocument          = "no_document"
+)
+
+// SkippedNode describes a contentserver node that is not indexed and why
+type SkippedNode struct {
+	ID       string `json:"id"`
+	URI      string `json:"uri,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	Reason   string `json:"reason"`
+	Error    string `json:"error,omitempty"`
+}
+
+// NodeReport lists the skipped nodes of an index provided by the ContentServer
+type NodeReport struct {
+	IndexID    pkgx.IndexID  `json:"index"`
+	ReportedAt time.Time     `json:"reported_at"`
+	Nodes      []SkippedNode `json:"nodes"`
+}
+
+// NodeReportSink receives the node report of every provided index, e.g. to show editors why a page is not searchable
+type NodeReportSink interface {
+	ReportNodes(ctx context.Context, report NodeReport) error
+}
+
+// NodeReportSinkFunc adapts a function to a NodeReportSink
+type NodeReportSinkFunc func(ctx context.Context, report NodeReport) error
+
+func (f NodeReportSinkFunc) ReportNodes(ctx context.Context, report NodeReport) error {
+	return f(ctx, report)
+}
+
+// JSONNodeReportSink writes every node report as a line of JSON to the writer
+type JSONNodeReportSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewJSONNodeReportSink(w io.Writer) *JSONNodeReportSink {
+	return &JSONNodeReportSink{w: w}
+}
+
+func (s *JSONNodeReportSink) ReportNodes(ctx context.Context, report NodeReport) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.NewEncoder(s.w).Encode(report)
+}
+
+// WithNodeReportSink sends a report of the nodes that were skipped or failed with their reason to the sink
+// after every Provide, ProvideScope and ProvidePaged call
+func WithNodeReportSink[indexDocument any](sink NodeReportSink) ContentServerOption[indexDocument] {
+	return func(c *ContentServer[indexDocument]) {
+		c.nodeReportSink = sink
+	}
+}
@@ -0,0 +1,150 @@
+package typesenseindexing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+const (
+	defaultBatchSize         = 1000
+	defaultHeartbeatInterval = 30 * time.Second
+)
+
+// CanaryValidator decides if a revision deployed to the canary aliases may be committed,
+// e.g. by waiting for and evaluating the metrics of the canary traffic
+type CanaryValidator func(ctx context.Context, revisionID pkgx.RevisionID) error
+
+// SampleAssertion checks a converted sample document of a revision, e.g. that mandatory fields are mapped
+type SampleAssertion[returnType any] func(indexID pkgx.IndexID, document returnType) error
+
+// Option configures optional behavior of the BaseIndexer
+type Option func(o *options)
+
+type options struct {
+	extensions        []pkgx.IndexerExtension
+	progressReporter  ProgressReporter
+	batchSize         int
+	heartbeatInterval time.Duration
+	canaryValidator   CanaryValidator
+	sampleSize        int
+	sampleAssertion   func(indexID pkgx.IndexID, document any) error
+
+	maxDocumentsInMemory int
+	maxBytesInMemory     int64
+	pagedIndexing        bool
+	pagedIndices         []pkgx.IndexID
+
+	inPlaceRefresh bool
+
+	runHistory RunHistory
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		batchSize:         defaultBatchSize,
+		heartbeatInterval: defaultHeartbeatInterval,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithExtensions registers extensions that are invoked after a revision has been committed
+func WithExtensions(extensions ...pkgx.IndexerExtension) Option {
+	return func(o *options) {
+		o.extensions = append(o.extensions, extensions...)
+	}
+}
+
+// WithProgressReporter reports the progress, throughput and ETA of every index during a run
+func WithProgressReporter(reporter ProgressReporter) Option {
+	return func(o *options) {
+		o.progressReporter = reporter
+	}
+}
+
+// WithBatchSize sets the number of documents upserted at once, progress is reported after every batch
+func WithBatchSize(batchSize int) Option {
+	return func(o *options) {
+		o.batchSize = batchSize
+	}
+}
+
+// WithHeartbeat sets the interval of the progress log during a run, 0 disables it
+func WithHeartbeat(interval time.Duration) Option {
+	return func(o *options) {
+		o.heartbeatInterval = interval
+	}
+}
+
+// WithCanary deploys every revision to the canary aliases before committing it and reverts it
+// if the validator fails, the API has to implement pkgx.CanaryDeployer, otherwise every revision is reverted
+func WithCanary(validator CanaryValidator) Option {
+	return func(o *options) {
+		o.canaryValidator = validator
+	}
+}
+
+// WithSampling samples n random documents of every index of a revision before it is committed, converts them with
+// the DocumentConverter and runs the assertions. The revision is reverted if any sample fails.
+// The sample is attached to the IndexReport, the API has to implement pkgx.RevisionSampler,
+// otherwise every revision is reverted.
+func WithSampling[returnType any](n int, assertions ...SampleAssertion[returnType]) Option {
+	return func(o *options) {
+		o.sampleSize = n
+		o.sampleAssertion = func(indexID pkgx.IndexID, document any) error {
+			doc, ok := document.(returnType)
+			if !ok {
+				return fmt.Errorf("unexpected sample document type %T", document)
+			}
+			for _, assertion := range assertions {
+				if err := assertion(indexID, doc); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// WithMemoryBudget limits the documents of an index held in memory by their count and estimated size in bytes,
+// 0 disables a limit. The documents are provided with ProvidePaged and held until they exceed the budget.
+func WithMemoryBudget(maxDocuments int, maxBytes int64) Option {
+	return func(o *options) {
+		o.maxDocumentsInMemory = maxDocuments
+		o.maxBytesInMemory = maxBytes
+	}
+}
+
+// WithPagedIndexing provides the documents of the given indices, or of all indices if none are given, page by page
+// with ProvidePaged and upserts every page in batches of WithBatchSize, so indices with millions of documents are
+// built without holding them in memory
+func WithPagedIndexing(indices ...pkgx.IndexID) Option {
+	return func(o *options) {
+		o.pagedIndexing = true
+		o.pagedIndices = append(o.pagedIndices, indices...)
+	}
+}
+
+// WithInPlaceRefresh makes Run refresh the collections served by the aliases in place instead of creating and
+// committing a new revision: provided documents are emplaced and documents that are no longer provided are deleted.
+// It is meant for small indices where alias swaps are overkill, the API has to implement pkgx.InPlaceRefresher.
+// Sampling, canary validation and the memory budget don't apply and extensions are notified with AfterUpdate.
+func WithInPlaceRefresh() Option {
+	return func(o *options) {
+		o.inPlaceRefresh = true
+	}
+}
+
+// WithRunHistory saves the report of every run, successful or not, e.g. to a CollectionRunHistory
+func WithRunHistory(history RunHistory) Option {
+	return func(o *options) {
+		o.runHistory = history
+	}
+}
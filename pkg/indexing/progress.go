@@ -0,0 +1,170 @@
+package typesenseindexing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// IndexReport describes the progress of upserting the documents of an index
+type IndexReport struct {
+	IndexID   pkgx.IndexID
+	Documents int
+	Upserted  int
+	Failed    int
+	Started   time.Time
+	Elapsed   time.Duration
+	// Throughput is the number of upserted documents per second
+	Throughput float64
+	// ETA estimates the remaining duration from the throughput, 0 if it is unknown
+	ETA  time.Duration
+	Done bool
+	// Sample holds the converted sample documents of the revision if sampling is enabled
+	Sample []any
+	// SampleErrors lists the conversion and assertion failures of the sample
+	SampleErrors []string
+}
+
+// ProgressReporter is notified after every upserted batch and when an index is done
+type ProgressReporter interface {
+	ReportProgress(ctx context.Context, report IndexReport)
+}
+
+// ProgressReporterFunc adapts a function to a ProgressReporter
+type ProgressReporterFunc func(ctx context.Context, report IndexReport)
+
+func (f ProgressReporterFunc) ReportProgress(ctx context.Context, report IndexReport) {
+	f(ctx, report)
+}
+
+// progressTracker tracks the index currently being upserted
+type progressTracker struct {
+	l        *zap.Logger
+	reporter ProgressReporter
+	mu       sync.Mutex
+	current  *IndexReport
+	reports  map[pkgx.IndexID]IndexReport
+}
+
+// start begins tracking a new index
+func (p *progressTracker) start(indexID pkgx.IndexID, documents int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = &IndexReport{
+		IndexID:   indexID,
+		Documents: documents,
+		Started:   time.Now(),
+	}
+}
+
+// grow adds documents to the current index, e.g. for every page of a paged provider
+func (p *progressTracker) grow(documents int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current.Documents += documents
+}
+
+// add records an upserted or failed batch and notifies the reporter
+func (p *progressTracker) add(ctx context.Context, upserted, failed int) {
+	p.mu.Lock()
+	p.current.Upserted += upserted
+	p.current.Failed += failed
+	report := p.snapshot()
+	p.mu.Unlock()
+
+	if p.reporter != nil {
+		p.reporter.ReportProgress(ctx, report)
+	}
+}
+
+// finish marks the current index as done and notifies the reporter
+func (p *progressTracker) finish(ctx context.Context) {
+	l := pkgx.Logger(ctx, p.l)
+	p.mu.Lock()
+	p.current.Done = true
+	report := p.snapshot()
+	p.current = nil
+	p.store(report)
+	p.mu.Unlock()
+
+	if p.reporter != nil {
+		p.reporter.ReportProgress(ctx, report)
+	}
+	l.Info("indexed documents",
+		zap.String("index", string(report.IndexID)),
+		zap.Int("upserted", report.Upserted),
+		zap.Int("failed", report.Failed),
+		zap.Duration("elapsed", report.Elapsed),
+		zap.Float64("docs_per_second", report.Throughput),
+	)
+}
+
+// attachSample adds the sample to the report of a finished index and notifies the reporter
+func (p *progressTracker) attachSample(ctx context.Context, indexID pkgx.IndexID, sample []any, sampleErrors []string) {
+	p.mu.Lock()
+	report := p.reports[indexID]
+	report.IndexID = indexID
+	report.Sample = sample
+	report.SampleErrors = sampleErrors
+	p.store(report)
+	p.mu.Unlock()
+
+	if p.reporter != nil {
+		p.reporter.ReportProgress(ctx, report)
+	}
+}
+
+// store keeps the report of a finished index, p.mu must be held
+func (p *progressTracker) store(report IndexReport) {
+	if p.reports == nil {
+		p.reports = map[pkgx.IndexID]IndexReport{}
+	}
+	p.reports[report.IndexID] = report
+}
+
+// snapshot returns a copy of the current report with up to date throughput and ETA, p.mu must be held
+func (p *progressTracker) snapshot() IndexReport {
+	report := *p.current
+	report.Elapsed = time.Since(report.Started)
+	if report.Elapsed > 0 {
+		report.Throughput = float64(report.Upserted) / report.Elapsed.Seconds()
+	}
+	remaining := report.Documents - report.Upserted - report.Failed
+	if !report.Done && remaining > 0 && report.Throughput > 0 {
+		report.ETA = time.Duration(float64(remaining) / report.Throughput * float64(time.Second))
+	}
+	return report
+}
+
+// heartbeat logs the progress of the current index in the given interval until the context is done,
+// so a slow run can be told apart from a hung one
+func (p *progressTracker) heartbeat(ctx context.Context, interval time.Duration) {
+	l := pkgx.Logger(ctx, p.l)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			if p.current == nil {
+				p.mu.Unlock()
+				continue
+			}
+			report := p.snapshot()
+			p.mu.Unlock()
+			l.Info("indexing in progress",
+				zap.String("index", string(report.IndexID)),
+				zap.Int("upserted", report.Upserted),
+				zap.Int("documents", report.Documents),
+				zap.Duration("elapsed", report.Elapsed),
+				zap.Float64("docs_per_second", report.Throughput),
+				zap.Duration("eta", report.ETA),
+			)
+		}
+	}
+}
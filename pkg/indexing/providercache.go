@@ -0,0 +1,136 @@
+package typesenseindexing
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"sync"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// DocumentCache stores provided documents by key, implementations have to be safe for concurrent use
+type DocumentCache[indexDocument any] interface {
+	Get(key string) (*indexDocument, bool)
+	Set(key string, document *indexDocument)
+}
+
+// ContentHashFunc returns a cheap version of the upstream content of a document, e.g. a revision or ETag,
+// so cached documents are rebuilt once their content changes. Documents with an empty hash are not cached.
+type ContentHashFunc func(
+	ctx context.Context,
+	indexID pkgx.IndexID,
+	documentID pkgx.DocumentID,
+	urlsByIDs map[pkgx.DocumentID]string,
+) (string, error)
+
+// CachedDocumentProviderFunc wraps the provider func with a cache keyed by index, document ID and content hash,
+// so re-runs only build documents whose content changed. The hash func is required, without one the provider
+// func is returned as is. Cached documents are copied, later stages may modify the provided documents.
+func CachedDocumentProviderFunc[indexDocument any](
+	providerFunc pkgx.DocumentProviderFunc[indexDocument],
+	cache DocumentCache[indexDocument],
+	hashFunc ContentHashFunc,
+) pkgx.DocumentProviderFunc[indexDocument] {
+	if hashFunc == nil {
+		return providerFunc
+	}
+	return func(
+		ctx context.Context,
+		indexID pkgx.IndexID,
+		documentID pkgx.DocumentID,
+		urlsByIDs map[pkgx.DocumentID]string,
+	) (*indexDocument, error) {
+		hash, err := hashFunc(ctx, indexID, documentID, urlsByIDs)
+		if err != nil || hash == "" {
+			return providerFunc(ctx, indexID, documentID, urlsByIDs)
+		}
+
+		key := string(indexID) + "\x00" + string(documentID) + "\x00" + hash
+		if cached, ok := cache.Get(key); ok {
+			if document, err := copyDocument(cached); err == nil {
+				return document, nil
+			}
+		}
+		document, err := providerFunc(ctx, indexID, documentID, urlsByIDs)
+		if err != nil || document == nil {
+			return document, err
+		}
+		if cached, err := copyDocument(document); err == nil {
+			cache.Set(key, cached)
+		}
+		return document, nil
+	}
+}
+
+// copyDocument deep copies a document by a JSON round trip, the way it is sent to typesense
+func copyDocument[indexDocument any](document *indexDocument) (*indexDocument, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	copied := new(indexDocument)
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
+
+// LRUDocumentCache is an in-memory DocumentCache evicting the least recently used documents
+type LRUDocumentCache[indexDocument any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type lruEntry[indexDocument any] struct {
+	key      string
+	document *indexDocument
+}
+
+// NewLRUDocumentCache creates a cache holding at most maxEntries documents
+func NewLRUDocumentCache[indexDocument any](maxEntries int) *LRUDocumentCache[indexDocument] {
+	return &LRUDocumentCache[indexDocument]{
+		maxEntries: max(maxEntries, 1),
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+func (c *LRUDocumentCache[indexDocument]) Get(key string) (*indexDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	entry, _ := element.Value.(*lruEntry[indexDocument])
+	return entry.document, true
+}
+
+func (c *LRUDocumentCache[indexDocument]) Set(key string, document *indexDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		element.Value = &lruEntry[indexDocument]{key: key, document: document}
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry[indexDocument]{key: key, document: document})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		if entry, ok := oldest.Value.(*lruEntry[indexDocument]); ok {
+			delete(c.entries, entry.key)
+		}
+	}
+}
+
+// Len returns the number of cached documents
+func (c *LRUDocumentCache[indexDocument]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
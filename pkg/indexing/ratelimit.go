@@ -0,0 +1,72 @@
+package typesenseindexing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// callLimiter caps the rate and concurrency of calls to a shared backend, e.g. the contentserver.
+// A nil limiter does not limit.
+type callLimiter struct {
+	slots    chan struct{}
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// newCallLimiter limits the calls per second and the concurrent calls, 0 disables a limit
+func newCallLimiter(callsPerSecond float64, maxConcurrent int) *callLimiter {
+	if callsPerSecond <= 0 && maxConcurrent <= 0 {
+		return nil
+	}
+	l := &callLimiter{}
+	if callsPerSecond > 0 {
+		l.interval = time.Duration(float64(time.Second) / callsPerSecond)
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+// acquire waits for a free slot and the next permitted call time and returns the function releasing the slot
+func (l *callLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release := func() {
+		if l.slots != nil {
+			<-l.slots
+		}
+	}
+
+	if l.interval > 0 {
+		l.mu.Lock()
+		now := time.Now()
+		at := now
+		if l.next.After(now) {
+			at = l.next
+		}
+		l.next = at.Add(l.interval)
+		l.mu.Unlock()
+		if wait := at.Sub(now); wait > 0 {
+			timer := time.NewTimer(wait)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				release()
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return release, nil
+}
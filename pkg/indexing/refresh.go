@@ -0,0 +1,80 @@
+package typesenseindexing
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// refreshInPlace provides the documents of all indices and refreshes the collections served by their aliases
+// in place instead of creating and committing a new revision, see WithInPlaceRefresh
+func (b *BaseIndexer[indexDocument, returnType]) refreshInPlace(
+	ctx context.Context,
+	progress *progressTracker,
+	run *RunReport,
+) error {
+	l := pkgx.Logger(ctx, b.l)
+	refresher, ok := b.typesenseAPI.(pkgx.InPlaceRefresher[indexDocument])
+	if !ok {
+		return errors.New("typesense api does not support in-place refreshes")
+	}
+
+	indices, err := b.typesenseAPI.Indices()
+	if err != nil {
+		l.Error("failed to retrieve indices from typesense", zap.Error(err))
+		return err
+	}
+
+	// All documents are provided first, so documents routed to other indices are complete before refreshing them
+	documentsByIndex := make(map[pkgx.IndexID][]*indexDocument, len(indices))
+	routed := map[pkgx.IndexID][]*indexDocument{}
+	var errs []error
+	for _, indexID := range indices {
+		documents, err := b.documentProvider.Provide(ctx, indexID)
+		if err != nil {
+			l.Error("failed to fetch documents", zap.String("index", string(indexID)), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		documents = slices.DeleteFunc(documents, func(document *indexDocument) bool { return document == nil })
+		routeDocuments(indexID, documents, routed)
+		documentsByIndex[indexID] = documents
+	}
+
+	for _, indexID := range indices {
+		documents, ok := documentsByIndex[indexID]
+		if !ok {
+			// a partially provided index would lose its missing documents
+			continue
+		}
+		documents = append(documents, routed[indexID]...)
+
+		progress.start(indexID, len(documents))
+		if err := refresher.RefreshInPlace(ctx, indexID, documents); err != nil {
+			progress.add(ctx, 0, len(documents))
+			progress.finish(ctx)
+			l.Error("failed to refresh index in place", zap.String("index", string(indexID)), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+		progress.add(ctx, len(documents), 0)
+		progress.finish(ctx)
+
+		for _, extension := range b.opts.extensions {
+			if updateExtension, ok := extension.(pkgx.IndexerUpdateExtension); ok {
+				if err := updateExtension.AfterUpdate(ctx, indexID); err != nil {
+					l.Error("indexer extension failed", zap.String("index", string(indexID)), zap.Error(err))
+				}
+			}
+		}
+		l.Info("refreshed index in place", zap.String("index", string(indexID)), zap.Int("count", len(documents)))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	run.Status = RunStatusRefreshed
+	return nil
+}
@@ -0,0 +1,35 @@
+package typesenseindexing
+
+import (
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// routeDocuments collects the documents provided for indexID that declare additional target indices
+// into the routed map, applying the per target projection if the document implements it
+func routeDocuments[indexDocument any](
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+	routed map[pkgx.IndexID][]*indexDocument,
+) {
+	for _, document := range documents {
+		if document == nil {
+			continue
+		}
+		routedDocument, ok := any(document).(pkgx.RoutedDocument)
+		if !ok {
+			continue
+		}
+		for _, target := range routedDocument.TargetIndices() {
+			if target == indexID {
+				continue
+			}
+			targetDocument := document
+			if projected, ok := any(document).(pkgx.ProjectedDocument[indexDocument]); ok {
+				targetDocument = projected.ProjectFor(target)
+			}
+			if targetDocument != nil {
+				routed[target] = append(routed[target], targetDocument)
+			}
+		}
+	}
+}
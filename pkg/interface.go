@@ -2,6 +2,7 @@ package typesense
 
 import (
 	"context"
+	"time"
 
 	"github.com/typesense/typesense-go/v3/typesense/api"
 )
@@ -12,13 +13,18 @@ type API[indexDocument any, returnType any] interface {
 	RevertRevision(ctx context.Context, revisionID RevisionID) error
 	UpsertDocuments(ctx context.Context, revisionID RevisionID, indexID IndexID, documents []*indexDocument) error
 
-	// this will check the typesense connection and initialize the indices
+	// this will check the typesense connection and attach to the revision served by the aliases,
+	// indices without an alias are bootstrapped with an empty revision
 	// should be run directly in a main.go or similar to ensure the connection is working
 	Initialize(ctx context.Context) (RevisionID, error)
+	// this will create the collections of a new revision to upsert documents into
+	NewRevision(ctx context.Context) (RevisionID, error)
 
 	// perform a search operation on the given index
 	SimpleSearch(ctx context.Context, index IndexID, parameters *SearchParameters) ([]returnType, Scores, int, error)
 	ExpertSearch(ctx context.Context, index IndexID, parameters *api.SearchCollectionParams) ([]returnType, Scores, int, error)
+	SimpleSearchResult(ctx context.Context, index IndexID, parameters *SearchParameters) (*SearchResult[returnType], error)
+	ExpertSearchResult(ctx context.Context, index IndexID, parameters *api.SearchCollectionParams) (*SearchResult[returnType], error)
 	Healthz(ctx context.Context) error
 	Indices() ([]IndexID, error)
 }
@@ -31,3 +37,116 @@ type DocumentProvider[indexDocument any] interface {
 	Provide(ctx context.Context, index IndexID) ([]*indexDocument, error)
 	ProvidePaged(ctx context.Context, index IndexID, offset int) ([]*indexDocument, int, error)
 }
+
+// ScopedDocumentProvider provides only the documents of a scope, see Scope
+type ScopedDocumentProvider[indexDocument any] interface {
+	ProvideScope(ctx context.Context, index IndexID, scope Scope) ([]*indexDocument, error)
+}
+
+// ScopeReplacer replaces the documents of a scope in the collection currently served by the alias of an index
+type ScopeReplacer[indexDocument any] interface {
+	ReplaceScope(ctx context.Context, index IndexID, scope Scope, documents []*indexDocument) error
+}
+
+// InPlaceRefresher replaces all documents of the collection currently served by the alias of an index
+// without creating a new revision
+type InPlaceRefresher[indexDocument any] interface {
+	RefreshInPlace(ctx context.Context, index IndexID, documents []*indexDocument) error
+}
+
+// ExistenceChecker checks which documents exist in the collection currently served by the alias of an index
+type ExistenceChecker interface {
+	ExistingIDs(ctx context.Context, index IndexID, ids []DocumentID) (map[DocumentID]bool, error)
+}
+
+// URLResolver resolves the URLs or permalinks of documents in one batch, e.g. from the contentserver or a CMS
+type URLResolver interface {
+	ResolveURLs(ctx context.Context, indexID IndexID, documentIDs []DocumentID) (map[DocumentID]string, error)
+}
+
+// URLResolverFunc adapts a function to a URLResolver
+type URLResolverFunc func(ctx context.Context, indexID IndexID, documentIDs []DocumentID) (map[DocumentID]string, error)
+
+func (f URLResolverFunc) ResolveURLs(ctx context.Context, indexID IndexID, documentIDs []DocumentID) (map[DocumentID]string, error) {
+	return f(ctx, indexID, documentIDs)
+}
+
+// DocumentProviderMiddleware wraps a DocumentProvider, e.g. to transform or enrich the provided documents
+type DocumentProviderMiddleware[indexDocument any] func(next DocumentProvider[indexDocument]) DocumentProvider[indexDocument]
+
+// SearchRecorder collects search and click-through events, e.g. for search analytics
+type SearchRecorder interface {
+	RecordSearch(ctx context.Context, indexID IndexID, query string, found int)
+	RecordClick(ctx context.Context, indexID IndexID, query string, documentID DocumentID, position int) error
+}
+
+// SpellingSuggester provides "did you mean" suggestions for queries with few or no results
+type SpellingSuggester interface {
+	Suggest(ctx context.Context, indexID IndexID, query string) ([]string, error)
+}
+
+// RevisionSampler samples converted documents of a revision before it is committed
+type RevisionSampler[returnType any] interface {
+	SampleRevision(ctx context.Context, revisionID RevisionID, indexID IndexID, n int) ([]returnType, error)
+}
+
+// CanaryDeployer points canary aliases at a new revision before it is committed
+type CanaryDeployer interface {
+	DeployCanary(ctx context.Context, revisionID RevisionID) error
+}
+
+// IndexerExtension is invoked by the indexer after a revision has been committed successfully
+type IndexerExtension interface {
+	AfterCommit(ctx context.Context, revisionID RevisionID, indices []IndexID) error
+}
+
+// IndexerUpdateExtension can be implemented by indexer extensions that are additionally invoked after a part of an
+// index has been updated in place, e.g. by ReindexScope
+type IndexerUpdateExtension interface {
+	AfterUpdate(ctx context.Context, indexID IndexID) error
+}
+
+// RoutedDocument can be implemented by index documents that should additionally be written to other indices
+type RoutedDocument interface {
+	TargetIndices() []IndexID
+}
+
+// ProjectedDocument can be implemented by routed documents to adjust their fields per target index,
+// returning nil skips the target
+type ProjectedDocument[indexDocument any] interface {
+	ProjectFor(target IndexID) *indexDocument
+}
+
+// AccessFilterProvider derives a filter expression from the request context, e.g. from user roles, markets or
+// price lists. It is combined with the filter of every search, an empty filter grants access to all documents.
+type AccessFilterProvider interface {
+	AccessFilter(ctx context.Context, indexID IndexID) (string, error)
+}
+
+// SearchCurator adjusts the parameters of a search before it is sent to typesense, e.g. by merchandising rules.
+// It must not modify the given parameters but return a copy.
+type SearchCurator interface {
+	Curate(ctx context.Context, indexID IndexID, parameters *api.SearchCollectionParams) (*api.SearchCollectionParams, error)
+}
+
+// SearchRedirector resolves queries that should navigate to a fixed URL instead of showing results.
+// A SearchCurator implementing it is asked before every search, see SearchResult.Redirect.
+type SearchRedirector interface {
+	Redirect(ctx context.Context, indexID IndexID, query string) (string, bool)
+}
+
+// Identifiable can be implemented by index documents to provide their ID,
+// e.g. for schemas storing the business key in a field other than "id"
+type Identifiable interface {
+	DocumentID() DocumentID
+}
+
+// Clock provides the current time, it can be replaced to test the revision lifecycle deterministically
+type Clock interface {
+	Now() time.Time
+}
+
+// Locker acquires cluster wide locks, e.g. to serialize read-modify-write cycles of several replicas
+type Locker interface {
+	Lock(ctx context.Context, name string) (unlock func(), err error)
+}
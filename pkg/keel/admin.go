@@ -0,0 +1,40 @@
+package typesensekeel
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// AdminHandler returns the admin endpoints of the service, e.g. to be served with keel's service.NewHTTP on an
+// internal listener:
+//
+//	GET  /status   returns the Status as JSON
+//	POST /reindex  triggers an indexer run, it requires the bearer token configured with WithAdminToken
+//	               and is not served without one
+func (s *IndexerService) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(s.Status()); err != nil {
+			s.l.Warn("failed to encode status")
+		}
+	})
+	if s.opts.adminToken == "" {
+		return mux
+	}
+	mux.HandleFunc("POST /reindex", func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.adminToken)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		if !s.Trigger() {
+			http.Error(w, "indexer run already pending", http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
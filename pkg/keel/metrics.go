@@ -0,0 +1,71 @@
+package typesensekeel
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type metrics struct {
+	runs        *prometheus.CounterVec
+	duration    prometheus.Histogram
+	lastSuccess prometheus.Gauge
+}
+
+func newMetrics(registerer prometheus.Registerer, name string) *metrics {
+	labels := prometheus.Labels{"service": name}
+	m := &metrics{
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "typesense",
+			Subsystem:   "indexer",
+			Name:        "runs_total",
+			Help:        "Number of indexer runs by result",
+			ConstLabels: labels,
+		}, []string{"result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace:   "typesense",
+			Subsystem:   "indexer",
+			Name:        "run_duration_seconds",
+			Help:        "Duration of indexer runs",
+			ConstLabels: labels,
+			Buckets:     prometheus.ExponentialBuckets(1, 2, 12),
+		}),
+		lastSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   "typesense",
+			Subsystem:   "indexer",
+			Name:        "last_success_timestamp_seconds",
+			Help:        "Unix timestamp of the last successful indexer run",
+			ConstLabels: labels,
+		}),
+	}
+	if registerer != nil {
+		m.runs = register(registerer, m.runs)
+		m.duration = register(registerer, m.duration)
+		m.lastSuccess = register(registerer, m.lastSuccess)
+	}
+	return m
+}
+
+func (m *metrics) observe(start time.Time, duration time.Duration, err error) {
+	m.duration.Observe(duration.Seconds())
+	if err != nil {
+		m.runs.WithLabelValues("error").Inc()
+		return
+	}
+	m.runs.WithLabelValues("success").Inc()
+	m.lastSuccess.Set(float64(start.Unix()))
+}
+
+// register registers the collector or returns the already registered one, e.g. when a service is recreated
+func register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+	}
+	return collector
+}
@@ -0,0 +1,58 @@
+package typesensekeel
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Option configures the IndexerService
+type Option func(o *options)
+
+type options struct {
+	interval   time.Duration
+	runOnStart bool
+	registerer prometheus.Registerer
+	adminToken string
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		runOnStart: true,
+		registerer: prometheus.DefaultRegisterer,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithInterval runs the indexer periodically, by default it only runs on start and when triggered
+func WithInterval(interval time.Duration) Option {
+	return func(o *options) {
+		o.interval = interval
+	}
+}
+
+// WithRunOnStart controls if the indexer runs as soon as the service is started
+func WithRunOnStart(runOnStart bool) Option {
+	return func(o *options) {
+		o.runOnStart = runOnStart
+	}
+}
+
+// WithRegisterer registers the indexer metrics with the given registerer instead of the default one served by keel
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
+
+// WithAdminToken enables the reindex endpoint of the AdminHandler for requests with the given bearer token
+func WithAdminToken(token string) Option {
+	return func(o *options) {
+		o.adminToken = token
+	}
+}
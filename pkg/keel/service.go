@@ -0,0 +1,194 @@
+package typesensekeel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrNotIndexed is reported by IndexingHealthz until the first indexer run has completed
+	ErrNotIndexed = errors.New("indexer did not complete a run yet")
+	// ErrNotStarted is reported by Healthz until the service has been started
+	ErrNotStarted = errors.New("indexer service not started")
+)
+
+// Status describes the state of the indexer service
+type Status struct {
+	Running      bool          `json:"running"`
+	Runs         int           `json:"runs"`
+	LastRun      time.Time     `json:"last_run"`
+	LastSuccess  time.Time     `json:"last_success"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+// IndexerService runs an indexer on start, on a schedule and on demand.
+// It implements keel.Service and the keel healthz interfaces, so it can be registered with
+//
+//	svr.AddService(service)
+//	svr.AddReadinessHealthzers(service)
+//
+// Failed runs don't fail the readiness, they are reported by IndexingHealthz and the run metrics instead.
+type IndexerService struct {
+	l       *zap.Logger
+	name    string
+	run     func(ctx context.Context) error
+	opts    options
+	metrics *metrics
+	trigger chan struct{}
+	mu      sync.Mutex
+	status  Status
+	lastErr error
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewIndexerService creates a service for the given indexer, typically a *typesenseindexing.BaseIndexer
+func NewIndexerService[indexDocument any, returnType any](
+	l *zap.Logger,
+	name string,
+	indexer pkgx.IndexerInterface[indexDocument, returnType],
+	opts ...Option,
+) *IndexerService {
+	o := newOptions(opts...)
+	return &IndexerService{
+		l:       l.With(zap.String("service", name)),
+		name:    name,
+		run:     indexer.Run,
+		opts:    o,
+		metrics: newMetrics(o.registerer, name),
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+func (s *IndexerService) Name() string {
+	return s.name
+}
+
+// Start runs the indexer until the context is canceled or the service is closed
+func (s *IndexerService) Start(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.cancel = cancel
+	s.done = done
+	s.mu.Unlock()
+	defer close(done)
+
+	if s.opts.runOnStart {
+		s.Trigger()
+	}
+
+	var tick <-chan time.Time
+	if s.opts.interval > 0 {
+		ticker := time.NewTicker(s.opts.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+			s.runIndexer(ctx)
+		case <-s.trigger:
+			s.runIndexer(ctx)
+		}
+	}
+}
+
+// Close stops the service and waits for a running indexer to return
+func (s *IndexerService) Close(ctx context.Context) error {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Healthz fails until the service has been started, the indexer runs don't affect it
+func (s *IndexerService) Healthz(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done == nil {
+		return ErrNotStarted
+	}
+	return nil
+}
+
+// IndexingHealthz fails until the first run has completed successfully and reports the error of the last failed
+// run, e.g. for alerts or a status page, it is not meant as readiness check
+func (s *IndexerService) IndexingHealthz(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.lastErr != nil {
+		return s.lastErr
+	}
+	if s.status.LastSuccess.IsZero() {
+		return ErrNotIndexed
+	}
+	return nil
+}
+
+// Trigger schedules an indexer run and returns false if a run is already pending
+func (s *IndexerService) Trigger() bool {
+	select {
+	case s.trigger <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status returns the current state of the service
+func (s *IndexerService) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+func (s *IndexerService) runIndexer(ctx context.Context) {
+	start := time.Now()
+	s.mu.Lock()
+	s.status.Running = true
+	s.mu.Unlock()
+	s.l.Info("starting indexer run")
+
+	err := s.run(ctx)
+	duration := time.Since(start)
+
+	s.mu.Lock()
+	s.status.Running = false
+	s.status.Runs++
+	s.status.LastRun = start
+	s.status.LastDuration = duration
+	s.status.LastError = ""
+	s.lastErr = err
+	if err != nil {
+		s.status.LastError = err.Error()
+	} else {
+		s.status.LastSuccess = start
+	}
+	s.mu.Unlock()
+
+	s.metrics.observe(start, duration, err)
+	if err != nil {
+		s.l.Error("indexer run failed", zap.Duration("duration", duration), zap.Error(err))
+		return
+	}
+	s.l.Info("indexer run completed", zap.Duration("duration", duration))
+}
@@ -0,0 +1,33 @@
+package typesense
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerFieldsContextKey struct{}
+
+// WithLoggerFields attaches fields, e.g. request or trace IDs, to every log line emitted for the returned context
+func WithLoggerFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing := LoggerFields(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, loggerFieldsContextKey{}, merged)
+}
+
+// LoggerFields returns the fields attached to the context
+func LoggerFields(ctx context.Context) []zap.Field {
+	fields, _ := ctx.Value(loggerFieldsContextKey{}).([]zap.Field)
+	return fields
+}
+
+// Logger returns the logger with the fields attached to the context
+func Logger(ctx context.Context, l *zap.Logger) *zap.Logger {
+	fields := LoggerFields(ctx)
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
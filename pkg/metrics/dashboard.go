@@ -0,0 +1,12 @@
+package typesensemetrics
+
+import _ "embed"
+
+//go:embed dashboard.json
+var dashboard []byte
+
+// Dashboard returns the Grafana dashboard of the metrics, it selects the prometheus datasource, service and index
+// with template variables, so one dashboard serves all services using the decorator
+func Dashboard() []byte {
+	return dashboard
+}
@@ -0,0 +1,133 @@
+package typesensemetrics
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+const (
+	resultSuccess = "success"
+	resultZero    = "zero"
+	resultError   = "error"
+)
+
+// Operations recorded in the operation metrics
+const (
+	OperationSearch      = "search"
+	OperationUpsert      = "upsert"
+	OperationNewRevision = "new_revision"
+	OperationCommit      = "commit"
+	OperationRevert      = "revert"
+)
+
+// allIndices is the index label of operations affecting all indices, e.g. commits
+const allIndices = "all"
+
+// ExemplarFunc derives the exemplar labels of an observation from the request context, e.g. {"trace_id": "…"}
+// of the active span, so dashboards can link from latency spikes to traces. Nil or empty labels add no exemplar.
+type ExemplarFunc func(ctx context.Context) prometheus.Labels
+
+type metrics struct {
+	searches          *prometheus.CounterVec
+	duration          *prometheus.HistogramVec
+	operations        *prometheus.CounterVec
+	operationDuration *prometheus.HistogramVec
+	exemplarFunc      ExemplarFunc
+}
+
+func newMetrics(l *zap.Logger, o options) *metrics {
+	var labels prometheus.Labels
+	if o.service != "" {
+		labels = prometheus.Labels{"service": o.service}
+	}
+	m := &metrics{
+		searches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "typesense",
+			Subsystem:   "search",
+			Name:        "queries_total",
+			Help:        "Number of searches by index, preset, variant and result (success, zero, error)",
+			ConstLabels: labels,
+		}, []string{"index", "preset", "variant", "result"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   "typesense",
+			Subsystem:   "search",
+			Name:        "duration_seconds",
+			Help:        "Duration of successful searches by index, preset and variant",
+			ConstLabels: labels,
+			Buckets:     prometheus.DefBuckets,
+		}, []string{"index", "preset", "variant"}),
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "typesense",
+			Subsystem:   "api",
+			Name:        "operations_total",
+			Help:        "Number of API operations by index, operation and result (success, error)",
+			ConstLabels: labels,
+		}, []string{"index", "operation", "result"}),
+		operationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   "typesense",
+			Subsystem:   "api",
+			Name:        "operation_duration_seconds",
+			Help:        "Duration of API operations by index and operation",
+			ConstLabels: labels,
+			Buckets:     prometheus.ExponentialBuckets(0.005, 4, 10),
+		}, []string{"index", "operation"}),
+		exemplarFunc: o.exemplarFunc,
+	}
+	if o.registerer != nil {
+		m.searches = register(l, o.registerer, m.searches)
+		m.duration = register(l, o.registerer, m.duration)
+		m.operations = register(l, o.registerer, m.operations)
+		m.operationDuration = register(l, o.registerer, m.operationDuration)
+	}
+	return m
+}
+
+// inc increments the counter, with the exemplar of the context if there is one
+func (m *metrics) inc(ctx context.Context, counter prometheus.Counter) {
+	if exemplar := m.exemplar(ctx); exemplar != nil {
+		if adder, ok := counter.(prometheus.ExemplarAdder); ok {
+			adder.AddWithExemplar(1, exemplar)
+			return
+		}
+	}
+	counter.Inc()
+}
+
+// observe records the value, with the exemplar of the context if there is one
+func (m *metrics) observe(ctx context.Context, observer prometheus.Observer, value float64) {
+	if exemplar := m.exemplar(ctx); exemplar != nil {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(value, exemplar)
+			return
+		}
+	}
+	observer.Observe(value)
+}
+
+func (m *metrics) exemplar(ctx context.Context) prometheus.Labels {
+	if m.exemplarFunc == nil {
+		return nil
+	}
+	if exemplar := m.exemplarFunc(ctx); len(exemplar) > 0 {
+		return exemplar
+	}
+	return nil
+}
+
+// register registers the collector or returns the already registered one, e.g. when a decorator is recreated.
+// Other registration errors, e.g. conflicting label names, are logged and the unregistered collector is returned.
+func register[T prometheus.Collector](l *zap.Logger, registerer prometheus.Registerer, collector T) T {
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		l.Error("failed to register metrics", zap.Error(err))
+	}
+	return collector
+}
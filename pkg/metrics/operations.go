@@ -0,0 +1,81 @@
+package typesensemetrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+func (a *API[indexDocument, returnType]) UpsertDocuments(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	documents []*indexDocument,
+) error {
+	start := a.opts.clock.Now()
+	err := a.API.UpsertDocuments(ctx, revisionID, indexID, documents)
+	a.observeOperation(ctx, string(indexID), OperationUpsert, a.opts.clock.Now().Sub(start), err)
+	return err
+}
+
+func (a *API[indexDocument, returnType]) NewRevision(ctx context.Context) (pkgx.RevisionID, error) {
+	start := a.opts.clock.Now()
+	revisionID, err := a.API.NewRevision(ctx)
+	a.observeOperation(ctx, allIndices, OperationNewRevision, a.opts.clock.Now().Sub(start), err)
+	return revisionID, err
+}
+
+func (a *API[indexDocument, returnType]) CommitRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	start := a.opts.clock.Now()
+	err := a.API.CommitRevision(ctx, revisionID)
+	a.observeOperation(ctx, allIndices, OperationCommit, a.opts.clock.Now().Sub(start), err)
+	return err
+}
+
+func (a *API[indexDocument, returnType]) RevertRevision(ctx context.Context, revisionID pkgx.RevisionID) error {
+	start := a.opts.clock.Now()
+	err := a.API.RevertRevision(ctx, revisionID)
+	a.observeOperation(ctx, allIndices, OperationRevert, a.opts.clock.Now().Sub(start), err)
+	return err
+}
+
+// DeployCanary forwards to the decorated API, see pkgx.CanaryDeployer
+func (a *API[indexDocument, returnType]) DeployCanary(ctx context.Context, revisionID pkgx.RevisionID) error {
+	deployer, ok := a.API.(pkgx.CanaryDeployer)
+	if !ok {
+		return fmt.Errorf("%w: canary aliases", pkgx.ErrUnsupported)
+	}
+	return deployer.DeployCanary(ctx, revisionID)
+}
+
+// SampleRevision forwards to the decorated API, see pkgx.RevisionSampler
+func (a *API[indexDocument, returnType]) SampleRevision(
+	ctx context.Context,
+	revisionID pkgx.RevisionID,
+	indexID pkgx.IndexID,
+	n int,
+) ([]returnType, error) {
+	sampler, ok := a.API.(pkgx.RevisionSampler[returnType])
+	if !ok {
+		return nil, fmt.Errorf("%w: revision sampling", pkgx.ErrUnsupported)
+	}
+	return sampler.SampleRevision(ctx, revisionID, indexID, n)
+}
+
+// observeOperation records an operation in the operation metrics
+func (a *API[indexDocument, returnType]) observeOperation(
+	ctx context.Context,
+	index string,
+	operation string,
+	duration time.Duration,
+	err error,
+) {
+	result := resultSuccess
+	if err != nil {
+		result = resultError
+	}
+	a.metrics.inc(ctx, a.metrics.operations.WithLabelValues(index, operation, result))
+	a.metrics.observe(ctx, a.metrics.operationDuration.WithLabelValues(index, operation), duration.Seconds())
+}
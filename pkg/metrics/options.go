@@ -0,0 +1,77 @@
+package typesensemetrics
+
+import (
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// Option configures the metrics decorator
+type Option func(o *options)
+
+type options struct {
+	variantFunc  VariantFunc
+	registerer   prometheus.Registerer
+	clock        pkgx.Clock
+	service      string
+	exemplarFunc ExemplarFunc
+	indexPresets map[pkgx.IndexID]*api.PresetUpsertSchema
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		registerer: prometheus.DefaultRegisterer,
+		clock:      pkgx.SystemClock,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithVariantFunc breaks the statistics down by the variant derived from the request context
+func WithVariantFunc(variantFunc VariantFunc) Option {
+	return func(o *options) {
+		o.variantFunc = variantFunc
+	}
+}
+
+// WithRegisterer registers the search metrics with the given registerer, nil disables the registration
+func WithRegisterer(registerer prometheus.Registerer) Option {
+	return func(o *options) {
+		o.registerer = registerer
+	}
+}
+
+// WithClock replaces the system clock used to measure latencies, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// WithService adds the constant "service" label to all metrics, so services sharing a prometheus can be told apart
+// on the shared dashboard, see Dashboard
+func WithService(name string) Option {
+	return func(o *options) {
+		o.service = name
+	}
+}
+
+// WithExemplars attaches exemplars derived from the request context to the counters and histograms,
+// they are exposed with the OpenMetrics format only
+func WithExemplars(exemplarFunc ExemplarFunc) Option {
+	return func(o *options) {
+		o.exemplarFunc = exemplarFunc
+	}
+}
+
+// WithIndexPresets labels simple searches without a preset name with the preset of their index, pass the presets
+// configured with typesenseapi.WithIndexPresets
+func WithIndexPresets(presets map[pkgx.IndexID]*api.PresetUpsertSchema) Option {
+	return func(o *options) {
+		o.indexPresets = presets
+	}
+}
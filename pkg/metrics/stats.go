@@ -0,0 +1,236 @@
+package typesensemetrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	typesenseapi "github.com/foomo/typesense/pkg/api"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"go.uber.org/zap"
+)
+
+var _ pkgx.API[any, any] = (*API[any, any])(nil)
+
+const defaultPreset = "default"
+
+// VariantFunc derives the variant of a search from the request context, e.g. the bucket of an A/B test
+type VariantFunc func(ctx context.Context) string
+
+// Stats are the traffic statistics of an index, preset and variant since the start or the last reset
+type Stats struct {
+	IndexID     pkgx.IndexID `json:"index"`
+	Preset      string       `json:"preset"`
+	Variant     string       `json:"variant,omitempty"`
+	Queries     int          `json:"queries"`
+	ZeroResults int          `json:"zero_results"`
+	Errors      int          `json:"errors"`
+	// ZeroResultRate is the share of successful searches without results
+	ZeroResultRate float64 `json:"zero_result_rate"`
+	// AverageLatency is the average duration of successful searches
+	AverageLatency time.Duration `json:"average_latency"`
+}
+
+type statsKey struct {
+	indexID pkgx.IndexID
+	preset  string
+	variant string
+}
+
+type counters struct {
+	queries     int
+	zeroResults int
+	errors      int
+	latency     time.Duration
+}
+
+// API decorates an API with per index search statistics. Searches are counted by index, preset and variant and
+// exported as prometheus metrics, Stats returns the collected statistics, e.g. for the weekly relevance review.
+// Searches, upserts and revision operations are additionally exported by index and operation, see Dashboard.
+type API[indexDocument any, returnType any] struct {
+	pkgx.API[indexDocument, returnType]
+	l        *zap.Logger
+	opts     options
+	metrics  *metrics
+	mu       sync.Mutex
+	counters map[statsKey]*counters
+}
+
+func NewAPI[indexDocument any, returnType any](
+	l *zap.Logger,
+	api pkgx.API[indexDocument, returnType],
+	opts ...Option,
+) *API[indexDocument, returnType] {
+	o := newOptions(opts...)
+	return &API[indexDocument, returnType]{
+		API:      api,
+		l:        l,
+		opts:     o,
+		metrics:  newMetrics(l, o),
+		counters: map[statsKey]*counters{},
+	}
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) ([]returnType, pkgx.Scores, int, error) {
+	result, err := a.SimpleSearchResult(ctx, index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearch(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) ([]returnType, pkgx.Scores, int, error) {
+	result, err := a.ExpertSearchResult(ctx, index, parameters)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	return result.Results, result.Scores, result.Total, nil
+}
+
+func (a *API[indexDocument, returnType]) SimpleSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *pkgx.SearchParameters,
+) (*pkgx.SearchResult[returnType], error) {
+	preset := ""
+	if parameters != nil {
+		preset = parameters.PresetName
+	}
+	// simple searches without a preset name use the preset of their index
+	if indexPreset := a.opts.indexPresets[index]; indexPreset != nil && preset == "" {
+		preset = typesenseapi.IndexPresetName(index)
+	}
+	start := a.opts.clock.Now()
+	result, err := a.API.SimpleSearchResult(ctx, index, parameters)
+	a.observe(ctx, index, preset, start, result, err)
+	return result, err
+}
+
+func (a *API[indexDocument, returnType]) ExpertSearchResult(
+	ctx context.Context,
+	index pkgx.IndexID,
+	parameters *api.SearchCollectionParams,
+) (*pkgx.SearchResult[returnType], error) {
+	preset := ""
+	if parameters != nil && parameters.Preset != nil {
+		preset = *parameters.Preset
+	}
+	start := a.opts.clock.Now()
+	result, err := a.API.ExpertSearchResult(ctx, index, parameters)
+	a.observe(ctx, index, preset, start, result, err)
+	return result, err
+}
+
+// Stats returns the statistics of all searched indices, presets and variants ordered by index, preset and variant
+func (a *API[indexDocument, returnType]) Stats() []Stats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stats := make([]Stats, 0, len(a.counters))
+	for key, c := range a.counters {
+		s := Stats{
+			IndexID:     key.indexID,
+			Preset:      key.preset,
+			Variant:     key.variant,
+			Queries:     c.queries,
+			ZeroResults: c.zeroResults,
+			Errors:      c.errors,
+		}
+		if succeeded := c.queries - c.errors; succeeded > 0 {
+			s.ZeroResultRate = float64(c.zeroResults) / float64(succeeded)
+			s.AverageLatency = c.latency / time.Duration(succeeded)
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].IndexID != stats[j].IndexID {
+			return stats[i].IndexID < stats[j].IndexID
+		}
+		if stats[i].Preset != stats[j].Preset {
+			return stats[i].Preset < stats[j].Preset
+		}
+		return stats[i].Variant < stats[j].Variant
+	})
+	return stats
+}
+
+// IndexStats returns the statistics of the given index by preset and variant
+func (a *API[indexDocument, returnType]) IndexStats(index pkgx.IndexID) []Stats {
+	var stats []Stats
+	for _, s := range a.Stats() {
+		if s.IndexID == index {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
+
+// Reset clears the collected statistics, e.g. after they have been reported, the prometheus metrics are kept
+func (a *API[indexDocument, returnType]) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	clear(a.counters)
+}
+
+// observe records a search in the statistics and metrics
+func (a *API[indexDocument, returnType]) observe(
+	ctx context.Context,
+	index pkgx.IndexID,
+	preset string,
+	start time.Time,
+	result *pkgx.SearchResult[returnType],
+	err error,
+) {
+	if preset == "" {
+		preset = defaultPreset
+	}
+	variant := ""
+	if a.opts.variantFunc != nil {
+		variant = a.opts.variantFunc(ctx)
+	}
+	duration := a.opts.clock.Now().Sub(start)
+	// redirected searches are not executed and counted as queries only
+	zeroResults := err == nil && result != nil && result.Redirect == "" && result.Total == 0
+
+	a.mu.Lock()
+	key := statsKey{indexID: index, preset: preset, variant: variant}
+	c, ok := a.counters[key]
+	if !ok {
+		c = &counters{}
+		a.counters[key] = c
+	}
+	c.queries++
+	if err != nil {
+		c.errors++
+	} else {
+		c.latency += duration
+	}
+	if zeroResults {
+		c.zeroResults++
+	}
+	a.mu.Unlock()
+
+	labels := []string{string(index), preset, variant}
+	switch {
+	case err != nil:
+		a.metrics.inc(ctx, a.metrics.searches.WithLabelValues(append(labels, resultError)...))
+		a.l.Debug("search failed", zap.String("index", string(index)), zap.String("preset", preset), zap.Error(err))
+	case zeroResults:
+		a.metrics.inc(ctx, a.metrics.searches.WithLabelValues(append(labels, resultZero)...))
+	default:
+		a.metrics.inc(ctx, a.metrics.searches.WithLabelValues(append(labels, resultSuccess)...))
+	}
+	if err == nil {
+		a.metrics.observe(ctx, a.metrics.duration.WithLabelValues(labels...), duration.Seconds())
+	}
+	a.observeOperation(ctx, string(index), OperationSearch, duration, err)
+}
@@ -0,0 +1,32 @@
+package typesenseprotection
+
+import (
+	"fmt"
+)
+
+// StaticKeyProvider provides keys from memory, e.g. loaded from environment variables. The current key is used for
+// new values, the other keys decrypt values encrypted before a key rotation.
+type StaticKeyProvider struct {
+	currentKeyID string
+	keys         map[string][]byte
+}
+
+func NewStaticKeyProvider(currentKeyID string, keys map[string][]byte) *StaticKeyProvider {
+	return &StaticKeyProvider{
+		currentKeyID: currentKeyID,
+		keys:         keys,
+	}
+}
+
+func (p *StaticKeyProvider) CurrentKey() (string, []byte, error) {
+	key, err := p.Key(p.currentKeyID)
+	return p.currentKeyID, key, err
+}
+
+func (p *StaticKeyProvider) Key(keyID string) ([]byte, error) {
+	key, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, keyID)
+	}
+	return key, nil
+}
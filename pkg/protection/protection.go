@@ -0,0 +1,252 @@
+package typesenseprotection
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	apix "github.com/foomo/typesense/pkg/api"
+)
+
+// encryptedPrefix marks encrypted values, followed by the key ID and the base64 encoded nonce and ciphertext
+const encryptedPrefix = "enc:v1:"
+
+// Mode defines how a field is protected before it is indexed
+type Mode string
+
+const (
+	// ModeRedact removes the field
+	ModeRedact Mode = "redact"
+	// ModeHash replaces the value with its hex encoded HMAC-SHA-256, keyed with the current key, so rotating the
+	// key requires a reindex. Unkeyed hashes of guessable values like emails could be reversed by brute force.
+	// Hashed fields can still be filtered by exact value if the filter value is hashed the same way, see
+	// Protector.Hash.
+	ModeHash Mode = "hash"
+	// ModeEncrypt replaces the value with its AES-GCM encryption, it is decrypted again by Rehydrate
+	ModeEncrypt Mode = "encrypt"
+)
+
+var (
+	// ErrUnknownKey is returned by KeyProviders for key IDs they don't know
+	ErrUnknownKey = errors.New("unknown key")
+	// ErrMissingKey is returned for hashed and encrypted fields without a KeyProvider or with an empty key
+	ErrMissingKey = errors.New("missing key")
+	// ErrInvalidKeyID is returned for key IDs containing a colon, which separates the key ID in encrypted values
+	ErrInvalidKeyID = errors.New("invalid key id")
+)
+
+// KeyProvider provides the keys to encrypt and decrypt protected fields, e.g. from a KMS or a secret store.
+// Keys must be 16, 24 or 32 bytes long to select AES-128, AES-192 or AES-256.
+type KeyProvider interface {
+	// CurrentKey returns the key new values are encrypted and hashed with
+	CurrentKey() (keyID string, key []byte, err error)
+	// Key returns the key with the given ID, e.g. to decrypt values encrypted before a key rotation
+	Key(keyID string) ([]byte, error)
+}
+
+// Protector hashes, redacts and encrypts the configured top level fields of documents before they are indexed
+// and decrypts encrypted fields of search hits, so personal data never reaches the cluster in clear text.
+//
+//	protector := typesenseprotection.NewProtector(map[string]typesenseprotection.Mode{
+//		"email": typesenseprotection.ModeHash,
+//		"phone": typesenseprotection.ModeEncrypt,
+//	}, keys)
+//	api := typesenseapi.NewBaseAPI(l, client, collections, presets, converter,
+//		typesenseapi.WithDocumentSerializer(protector.Serializer(nil)),
+//		typesenseapi.WithHitTransformer(protector.Rehydrate),
+//	)
+type Protector struct {
+	fields map[string]Mode
+	keys   KeyProvider
+}
+
+// NewProtector creates a protector for the given fields, keys may be nil if no field is hashed or encrypted
+func NewProtector(fields map[string]Mode, keys KeyProvider) *Protector {
+	return &Protector{
+		fields: fields,
+		keys:   keys,
+	}
+}
+
+// Serializer returns a DocumentSerializer protecting the fields serialized by next, or the JSON fields of the
+// document if next is nil
+func (p *Protector) Serializer(next apix.DocumentSerializer) apix.DocumentSerializer {
+	return func(indexID pkgx.IndexID, document any) (map[string]any, error) {
+		var fields map[string]any
+		if next != nil {
+			var err error
+			if fields, err = next(indexID, document); err != nil {
+				return nil, err
+			}
+		} else {
+			data, err := json.Marshal(document)
+			if err != nil {
+				return nil, err
+			}
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.Protect(fields); err != nil {
+			return nil, err
+		}
+		return fields, nil
+	}
+}
+
+// Protect hashes, redacts and encrypts the configured fields of the document in place
+func (p *Protector) Protect(document map[string]any) error {
+	for field, mode := range p.fields {
+		value, ok := document[field]
+		if !ok || value == nil {
+			continue
+		}
+		switch mode {
+		case ModeRedact:
+			delete(document, field)
+		case ModeHash:
+			hashed, err := p.hash(value)
+			if err != nil {
+				return fmt.Errorf("failed to hash field %q: %w", field, err)
+			}
+			document[field] = hashed
+		case ModeEncrypt:
+			encrypted, err := p.encrypt(value)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt field %q: %w", field, err)
+			}
+			document[field] = encrypted
+		default:
+			return fmt.Errorf("unknown protection mode %q of field %q", mode, field)
+		}
+	}
+	return nil
+}
+
+// Rehydrate decrypts the encrypted fields of a search hit document in place, it matches typesenseapi.HitTransformer
+func (p *Protector) Rehydrate(document map[string]any) error {
+	for field, mode := range p.fields {
+		if mode != ModeEncrypt {
+			continue
+		}
+		value, ok := document[field].(string)
+		if !ok || !strings.HasPrefix(value, encryptedPrefix) {
+			continue
+		}
+		decrypted, err := p.decrypt(value)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt field %q: %w", field, err)
+		}
+		document[field] = decrypted
+	}
+	return nil
+}
+
+// Hash hashes a value like the fields with ModeHash, e.g. to filter by a hashed field
+func (p *Protector) Hash(value any) (string, error) {
+	return p.hash(value)
+}
+
+func (p *Protector) hash(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	_, key, err := p.currentKey()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+func (p *Protector) encrypt(value any) (string, error) {
+	keyID, key, err := p.currentKey()
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, []byte(keyID))
+	return encryptedPrefix + keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (p *Protector) decrypt(value string) (any, error) {
+	if p.keys == nil {
+		return nil, fmt.Errorf("%w: no key provider", ErrMissingKey)
+	}
+	keyID, encoded, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return nil, errors.New("invalid encrypted value")
+	}
+	key, err := p.keys.Key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("invalid encrypted value")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	var decrypted any
+	if err := json.Unmarshal(plaintext, &decrypted); err != nil {
+		return nil, err
+	}
+	return decrypted, nil
+}
+
+// currentKey returns the current key of the provider and validates it
+func (p *Protector) currentKey() (string, []byte, error) {
+	if p.keys == nil {
+		return "", nil, fmt.Errorf("%w: no key provider", ErrMissingKey)
+	}
+	keyID, key, err := p.keys.CurrentKey()
+	if err != nil {
+		return "", nil, err
+	}
+	if len(key) == 0 {
+		return "", nil, fmt.Errorf("%w: %s", ErrMissingKey, keyID)
+	}
+	if strings.Contains(keyID, ":") {
+		return "", nil, fmt.Errorf("%w: %s", ErrInvalidKeyID, keyID)
+	}
+	return keyID, key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,358 @@
+package typesensereplication
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+// revisionIDLength is the length of a revision ID in the YYYY-MM-DD-HH-MM format
+const revisionIDLength = 16
+
+// Replicator copies committed revisions from the primary cluster to secondary clusters, e.g. in other regions,
+// so searches stay geo-local without running the indexing pipeline per region. It is meant to be registered
+// as an indexer extension, every secondary gets the collections of the revision with their overrides, the presets,
+// the stopwords, the aliases and the previous revision as a fallback. Indices whose primary alias does not serve the
+// revision, e.g. pinned ones, are not replicated and the secondaries keep the revisions the primary keeps, so
+// retention, pins and slots carry over. Collections aliased on a secondary are never deleted, a revision served
+// there already is copied into a collection suffixed with the time of the copy.
+type Replicator struct {
+	l           *zap.Logger
+	primary     *typesense.Client
+	secondaries map[string]*typesense.Client
+}
+
+func NewReplicator(
+	l *zap.Logger,
+	primary *typesense.Client,
+	secondaries map[string]*typesense.Client,
+) *Replicator {
+	return &Replicator{
+		l:           l,
+		primary:     primary,
+		secondaries: secondaries,
+	}
+}
+
+// AfterCommit replicates the committed revision of the indices served by it on the primary to all secondary
+// clusters in parallel
+func (r *Replicator) AfterCommit(ctx context.Context, revisionID pkgx.RevisionID, indices []pkgx.IndexID) error {
+	indices, err := r.servedIndices(ctx, revisionID, indices)
+	if err != nil {
+		return err
+	}
+	if len(indices) == 0 {
+		return nil
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for name, secondary := range r.secondaries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.Replicate(ctx, name, secondary, revisionID, indices); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("replication to %s failed: %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// servedIndices returns the indices whose alias on the primary points at the collection of the revision
+func (r *Replicator) servedIndices(ctx context.Context, revisionID pkgx.RevisionID, indices []pkgx.IndexID) ([]pkgx.IndexID, error) {
+	l := pkgx.Logger(ctx, r.l)
+	served := make([]pkgx.IndexID, 0, len(indices))
+	for _, indexID := range indices {
+		alias, err := r.primary.Alias(string(indexID)).Retrieve(ctx)
+		if err != nil {
+			l.Error("failed to retrieve alias", zap.String("alias", string(indexID)), zap.Error(err))
+			return nil, err
+		}
+		if alias.CollectionName != collectionName(indexID, revisionID) {
+			l.Info("skipping replication of index not serving the revision",
+				zap.String("alias", string(indexID)),
+				zap.String("collection", alias.CollectionName),
+			)
+			continue
+		}
+		served = append(served, indexID)
+	}
+	return served, nil
+}
+
+// Replicate copies the collections of the revision to the secondary cluster and points its aliases at them
+func (r *Replicator) Replicate(
+	ctx context.Context,
+	name string,
+	secondary *typesense.Client,
+	revisionID pkgx.RevisionID,
+	indices []pkgx.IndexID,
+) error {
+	l := pkgx.Logger(ctx, r.l).With(zap.String("cluster", name), zap.String("revision", string(revisionID)))
+
+	// Step 1: Copy the presets and stopwords the collections may reference
+	if err := r.copySettings(ctx, l, secondary); err != nil {
+		return err
+	}
+
+	// Step 2: Copy all collections before moving any alias
+	aliased, err := aliasedCollections(ctx, secondary)
+	if err != nil {
+		l.Error("failed to retrieve aliases", zap.Error(err))
+		return err
+	}
+	copies := make(map[pkgx.IndexID]string, len(indices))
+	for _, indexID := range indices {
+		copyName, err := r.copyCollection(ctx, l, secondary, collectionName(indexID, revisionID), aliased)
+		if err != nil {
+			return err
+		}
+		copies[indexID] = copyName
+	}
+
+	// Step 3: Point the aliases at the copies and prune older revisions
+	for _, indexID := range indices {
+		newCollectionName := copies[indexID]
+		previous, err := secondary.Alias(string(indexID)).Retrieve(ctx)
+		previousCollectionName := ""
+		if err == nil {
+			previousCollectionName = previous.CollectionName
+		}
+
+		if _, err := secondary.Aliases().Upsert(ctx, string(indexID), &api.CollectionAliasSchema{
+			CollectionName: newCollectionName,
+		}); err != nil {
+			l.Error("failed to update alias", zap.String("alias", string(indexID)), zap.Error(err))
+			return err
+		}
+		l.Info("updated alias", zap.String("alias", string(indexID)), zap.String("collection", newCollectionName))
+
+		if err := r.pruneCollections(ctx, l, secondary, indexID, newCollectionName, previousCollectionName); err != nil {
+			l.Warn("failed to prune collections", zap.String("alias", string(indexID)), zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// copySettings upserts the presets and stopwords sets of the primary into the secondary cluster
+func (r *Replicator) copySettings(ctx context.Context, l *zap.Logger, secondary *typesense.Client) error {
+	presets, err := r.primary.Presets().Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve presets", zap.Error(err))
+		return err
+	}
+	for _, preset := range presets {
+		schema, err := convert[api.PresetUpsertSchema](preset)
+		if err != nil {
+			return err
+		}
+		if _, err := secondary.Presets().Upsert(ctx, preset.Name, schema); err != nil {
+			l.Error("failed to upsert preset", zap.String("preset", preset.Name), zap.Error(err))
+			return err
+		}
+	}
+
+	stopwords, err := r.primary.Stopwords().Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve stopwords", zap.Error(err))
+		return err
+	}
+	for _, set := range stopwords {
+		if _, err := secondary.Stopwords().Upsert(ctx, set.Id, &api.StopwordsSetUpsertSchema{
+			Locale:    set.Locale,
+			Stopwords: set.Stopwords,
+		}); err != nil {
+			l.Error("failed to upsert stopwords", zap.String("stopwords", set.Id), zap.Error(err))
+			return err
+		}
+	}
+	return nil
+}
+
+// copyCollection creates a copy of the collection on the secondary cluster, streams the documents from the primary,
+// copies its overrides and returns the name of the copy
+func (r *Replicator) copyCollection(
+	ctx context.Context,
+	l *zap.Logger,
+	secondary *typesense.Client,
+	name string,
+	aliased map[string]bool,
+) (string, error) {
+	collection, err := r.primary.Collection(name).Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve collection", zap.String("collection", name), zap.Error(err))
+		return "", err
+	}
+	schema, err := convert[api.CollectionSchema](collection)
+	if err != nil {
+		return "", err
+	}
+
+	copyName := name
+	if aliased[name] {
+		// the revision is served already, copy it aside and switch the alias after the copy is complete
+		copyName = fmt.Sprintf("%s-%d", name, time.Now().Unix())
+	} else if _, err := secondary.Collection(name).Delete(ctx); err == nil {
+		// a previous attempt may have left an incomplete copy
+		l.Info("deleted existing copy", zap.String("collection", name))
+	}
+	schema.Name = copyName
+	if _, err := secondary.Collections().Create(ctx, schema); err != nil {
+		l.Error("failed to create collection", zap.String("collection", copyName), zap.Error(err))
+		return "", err
+	}
+
+	export, err := r.primary.Collection(name).Documents().Export(ctx, &api.ExportDocumentsParams{})
+	if err != nil {
+		l.Error("failed to export documents", zap.String("collection", name), zap.Error(err))
+		return "", err
+	}
+	defer export.Close()
+
+	response, err := secondary.Collection(copyName).Documents().ImportJsonl(ctx, export, &api.ImportDocumentsParams{
+		Action: (*api.IndexAction)(pointer.String("create")),
+	})
+	if err != nil {
+		l.Error("failed to import documents", zap.String("collection", copyName), zap.Error(err))
+		return "", err
+	}
+	defer response.Close()
+
+	imported, failed := 0, 0
+	scanner := bufio.NewScanner(response)
+	for scanner.Scan() {
+		var result api.ImportDocumentResponse
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil || !result.Success {
+			failed++
+			continue
+		}
+		imported++
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if failed > 0 {
+		l.Error("failed to replicate documents", zap.String("collection", copyName), zap.Int("failed_documents", failed))
+		return "", fmt.Errorf("%d documents of %s failed to replicate", failed, name)
+	}
+
+	overrides, err := r.primary.Collection(name).Overrides().Retrieve(ctx)
+	if err != nil {
+		l.Error("failed to retrieve overrides", zap.String("collection", name), zap.Error(err))
+		return "", err
+	}
+	for _, override := range overrides {
+		if override.Id == nil {
+			continue
+		}
+		overrideSchema, err := convert[api.SearchOverrideSchema](override)
+		if err != nil {
+			return "", err
+		}
+		if _, err := secondary.Collection(copyName).Overrides().Upsert(ctx, *override.Id, overrideSchema); err != nil {
+			l.Error("failed to upsert override", zap.String("collection", copyName), zap.String("override", *override.Id), zap.Error(err))
+			return "", err
+		}
+	}
+	l.Info("replicated collection",
+		zap.String("collection", copyName),
+		zap.Int("documents", imported),
+		zap.Int("overrides", len(overrides)),
+	)
+	return copyName, nil
+}
+
+// pruneCollections deletes the revisions of the index on the secondary cluster the primary cluster has pruned,
+// except the current and previous one and collections that are aliased
+func (r *Replicator) pruneCollections(
+	ctx context.Context,
+	l *zap.Logger,
+	secondary *typesense.Client,
+	indexID pkgx.IndexID,
+	keep ...string,
+) error {
+	primaryCollections, err := r.primary.Collections().Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	for _, collection := range primaryCollections {
+		keep = append(keep, collection.Name)
+	}
+	aliased, err := aliasedCollections(ctx, secondary)
+	if err != nil {
+		return err
+	}
+	collections, err := secondary.Collections().Retrieve(ctx)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, collection := range collections {
+		revisionID, ok := strings.CutPrefix(collection.Name, string(indexID)+"-")
+		// copies of served revisions are suffixed with the time of the copy
+		if !ok || len(revisionID) < revisionIDLength || (len(revisionID) > revisionIDLength && revisionID[revisionIDLength] != '-') {
+			continue
+		}
+		if !aliased[collection.Name] && !slices.Contains(keep, collection.Name) {
+			names = append(names, collection.Name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := secondary.Collection(name).Delete(ctx); err != nil {
+			return err
+		}
+		l.Info("deleted old collection", zap.String("collection", name))
+	}
+	return nil
+}
+
+// aliasedCollections returns the collections aliases of the cluster point at
+func aliasedCollections(ctx context.Context, client *typesense.Client) (map[string]bool, error) {
+	aliases, err := client.Aliases().Retrieve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aliased := make(map[string]bool, len(aliases))
+	for _, alias := range aliases {
+		aliased[alias.CollectionName] = true
+	}
+	return aliased, nil
+}
+
+// convert converts a retrieved resource of the primary into the schema to create the copy with
+func convert[T any](resource any) (*T, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	schema := new(T)
+	if err := json.Unmarshal(data, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func collectionName(indexID pkgx.IndexID, revisionID pkgx.RevisionID) string {
+	return fmt.Sprintf("%s-%s", indexID, revisionID)
+}
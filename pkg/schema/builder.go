@@ -0,0 +1,267 @@
+package typesenseschema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// Field types supported by typesense
+const (
+	TypeString      = "string"
+	TypeStringArray = "string[]"
+	TypeInt32       = "int32"
+	TypeInt32Array  = "int32[]"
+	TypeInt64       = "int64"
+	TypeInt64Array  = "int64[]"
+	TypeFloat       = "float"
+	TypeFloatArray  = "float[]"
+	TypeBool        = "bool"
+	TypeBoolArray   = "bool[]"
+	TypeGeopoint    = "geopoint"
+	TypeObject      = "object"
+	TypeObjectArray = "object[]"
+	TypeAuto        = "auto"
+)
+
+// WeightField is the conventional name of the editorial boost field, see Builder.Weight
+const WeightField = "weight"
+
+// ACLField is the conventional name of the document access control list, see Builder.ACL
+const ACLField = "acl"
+
+// FieldOption configures a single schema field
+type FieldOption func(f *api.Field)
+
+// Builder declaratively assembles and validates a collection schema
+type Builder struct {
+	schema api.CollectionSchema
+	locale string
+}
+
+// NewBuilder creates a schema builder, the collection name is set per revision when the collection is created
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Field adds a field with the given name and type
+func (b *Builder) Field(name, fieldType string, opts ...FieldOption) *Builder {
+	field := api.Field{
+		Name: name,
+		Type: fieldType,
+	}
+	for _, opt := range opts {
+		opt(&field)
+	}
+	b.schema.Fields = append(b.schema.Fields, field)
+	return b
+}
+
+// Weight adds the optional, sortable int32 WeightField which providers set to boost documents editorially,
+// see typesenseapi.WithWeightTiebreaker
+func (b *Builder) Weight() *Builder {
+	return b.Field(WeightField, TypeInt32, Optional(), Sort())
+}
+
+// ACL adds the faceted string[] ACLField which providers populate with the roles or groups allowed to find the
+// document, see typesense.ACLFilterProvider. It is mandatory, so documents can't be made public by accident.
+func (b *Builder) ACL() *Builder {
+	return b.Field(ACLField, TypeStringArray, Facet())
+}
+
+// DefaultSortingField sets the field used for sorting when no sort_by is given
+func (b *Builder) DefaultSortingField(name string) *Builder {
+	b.schema.DefaultSortingField = pointer.String(name)
+	return b
+}
+
+// EnableNestedFields allows indexing of object fields
+func (b *Builder) EnableNestedFields() *Builder {
+	b.schema.EnableNestedFields = pointer.True()
+	return b
+}
+
+// TokenSeparators configures additional characters used to split text into tokens
+func (b *Builder) TokenSeparators(separators ...string) *Builder {
+	b.schema.TokenSeparators = &separators
+	return b
+}
+
+// SymbolsToIndex configures special characters that are indexed as is
+func (b *Builder) SymbolsToIndex(symbols ...string) *Builder {
+	b.schema.SymbolsToIndex = &symbols
+	return b
+}
+
+// Locale sets the locale of all string fields without a locale of their own, e.g. "de" or "sv",
+// so language specific characters like umlauts are tokenized and sorted correctly
+func (b *Builder) Locale(locale string) *Builder {
+	b.locale = locale
+	return b
+}
+
+// Build validates the configured fields and returns the collection schema
+func (b *Builder) Build() (*api.CollectionSchema, error) {
+	schema := b.schema
+	schema.Fields = append([]api.Field(nil), b.schema.Fields...)
+	if b.locale != "" {
+		for i, field := range schema.Fields {
+			if isString(field.Type) && field.Locale == nil {
+				schema.Fields[i].Locale = pointer.String(b.locale)
+			}
+		}
+	}
+	if err := Validate(&schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// MustBuild is like Build but panics on invalid schemas, intended for static schema definitions
+func (b *Builder) MustBuild() *api.CollectionSchema {
+	schema, err := b.Build()
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Validate checks the given schema for inconsistencies
+func Validate(schema *api.CollectionSchema) error {
+	var errs []error
+
+	fields := make(map[string]api.Field, len(schema.Fields))
+	for _, field := range schema.Fields {
+		if field.Name == "" {
+			errs = append(errs, errors.New("field name must not be empty"))
+			continue
+		}
+		if _, ok := fields[field.Name]; ok {
+			errs = append(errs, fmt.Errorf("field %q is declared twice", field.Name))
+		}
+		fields[field.Name] = field
+	}
+
+	if schema.DefaultSortingField != nil {
+		if field, ok := fields[*schema.DefaultSortingField]; !ok {
+			errs = append(errs, fmt.Errorf("default sorting field %q does not exist", *schema.DefaultSortingField))
+		} else if !isNumeric(field.Type) {
+			errs = append(errs, fmt.Errorf("default sorting field %q must be numeric", field.Name))
+		}
+	}
+
+	for _, field := range schema.Fields {
+		errs = append(errs, validateEmbedding(field, fields)...)
+		errs = append(errs, validateVector(field)...)
+		if field.Locale != nil && *field.Locale != "" && !isString(field.Type) {
+			errs = append(errs, fmt.Errorf("locale of field %q requires a string type", field.Name))
+		}
+		if field.Index != nil && !*field.Index && field.Store != nil && !*field.Store {
+			errs = append(errs, fmt.Errorf("field %q is neither indexed nor stored", field.Name))
+		}
+		if field.Reference != nil {
+			if collection, referenced, ok := strings.Cut(*field.Reference, "."); !ok || collection == "" || referenced == "" {
+				errs = append(errs, fmt.Errorf("reference of field %q must be <index>.<field>", field.Name))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func isString(fieldType string) bool {
+	return fieldType == TypeString || fieldType == TypeStringArray
+}
+
+func isNumeric(fieldType string) bool {
+	switch fieldType {
+	case TypeInt32, TypeInt64, TypeFloat:
+		return true
+	default:
+		return false
+	}
+}
+
+// Facet enables faceting on the field
+func Facet() FieldOption {
+	return func(f *api.Field) {
+		f.Facet = pointer.True()
+	}
+}
+
+// Optional allows documents without a value for the field
+func Optional() FieldOption {
+	return func(f *api.Field) {
+		f.Optional = pointer.True()
+	}
+}
+
+// Sort enables sorting on the field
+func Sort() FieldOption {
+	return func(f *api.Field) {
+		f.Sort = pointer.True()
+	}
+}
+
+// Infix enables infix search on the field
+func Infix() FieldOption {
+	return func(f *api.Field) {
+		f.Infix = pointer.True()
+	}
+}
+
+// Stem enables stemming on the field
+func Stem() FieldOption {
+	return func(f *api.Field) {
+		f.Stem = pointer.True()
+	}
+}
+
+// RangeIndex optimizes the numeric field for range filters
+func RangeIndex() FieldOption {
+	return func(f *api.Field) {
+		f.RangeIndex = pointer.True()
+	}
+}
+
+// NoIndex stores the field without indexing it
+func NoIndex() FieldOption {
+	return func(f *api.Field) {
+		f.Index = pointer.False()
+		f.Optional = pointer.True()
+	}
+}
+
+// NoStore indexes the field without storing its value on disk, so large text fields are searchable without being
+// returned by searches, see typesenseapi.WithFieldHydrator to fetch them from their source
+func NoStore() FieldOption {
+	return func(f *api.Field) {
+		f.Store = pointer.False()
+	}
+}
+
+// Locale sets the locale used to tokenize and sort the string field, e.g. "de", see Builder.Locale
+func Locale(locale string) FieldOption {
+	return func(f *api.Field) {
+		f.Locale = pointer.String(locale)
+	}
+}
+
+// Reference links the field to the field of another index, e.g. Reference("brands", "id") on "brand_id",
+// so searches can join the referenced documents, see pkg.Join. The reference targets the alias of the index,
+// so joins follow the served revision, which requires a typesense version resolving aliases in references.
+func Reference(indexID, field string) FieldOption {
+	return func(f *api.Field) {
+		f.Reference = pointer.String(indexID + "." + field)
+	}
+}
+
+// NumDim sets the number of dimensions of a vector field
+func NumDim(dimensions int) FieldOption {
+	return func(f *api.Field) {
+		f.NumDim = pointer.Int(dimensions)
+	}
+}
@@ -0,0 +1,157 @@
+package typesenseschema
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+)
+
+// modelConfig is identical to the anonymous model config struct of api.Field, hence the field names
+type modelConfig = struct { //nolint:revive // field names must match api.Field
+	AccessToken    *string `json:"access_token,omitempty"`
+	ApiKey         *string `json:"api_key,omitempty"`
+	ClientId       *string `json:"client_id,omitempty"`
+	ClientSecret   *string `json:"client_secret,omitempty"`
+	IndexingPrefix *string `json:"indexing_prefix,omitempty"`
+	ModelName      string  `json:"model_name"`
+	ProjectId      *string `json:"project_id,omitempty"`
+	QueryPrefix    *string `json:"query_prefix,omitempty"`
+	RefreshToken   *string `json:"refresh_token,omitempty"`
+	Url            *string `json:"url,omitempty"`
+}
+
+// fieldEmbed is identical to the anonymous embed struct of api.Field
+type fieldEmbed = struct {
+	From        []string    `json:"from"`
+	ModelConfig modelConfig `json:"model_config"`
+}
+
+// Distance metrics of vector fields
+const (
+	DistanceCosine       = "cosine"
+	DistanceInnerProduct = "ip"
+)
+
+// VectorDistance sets the distance metric of the HNSW index of a vector field, DistanceCosine by default.
+// DistanceInnerProduct suits embeddings that are normalized already or trained for dot product similarity.
+// The HNSW build parameters are set with HNSWParams, the search time ef with typesenseapi.VectorEf.
+func VectorDistance(metric string) FieldOption {
+	return func(f *api.Field) {
+		f.VecDist = pointer.String(metric)
+	}
+}
+
+// HNSWParams returns the raw field properties setting the HNSW build parameters of a vector field, they are not
+// part of the field model of the typesense client and are applied with typesenseapi.WithFieldOverrides
+func HNSWParams(m, efConstruction int) map[string]any {
+	return map[string]any{
+		"hnsw_params": map[string]any{
+			"M":               m,
+			"ef_construction": efConstruction,
+		},
+	}
+}
+
+// EmbeddingOption configures the model of an auto-embedding field
+type EmbeddingOption func(c *modelConfig)
+
+// AutoEmbedding turns the field into a float[] field that typesense embeds from the given source fields,
+// e.g. AutoEmbedding([]string{"title", "description"}, "ts/all-MiniLM-L12-v2")
+func AutoEmbedding(from []string, modelName string, opts ...EmbeddingOption) FieldOption {
+	return func(f *api.Field) {
+		embed := &fieldEmbed{
+			From: from,
+		}
+		embed.ModelConfig.ModelName = modelName
+		for _, opt := range opts {
+			opt(&embed.ModelConfig)
+		}
+		f.Type = TypeFloatArray
+		f.Embed = embed
+	}
+}
+
+// EmbeddingAPIKey sets the api key of remote embedding models, e.g. openai/text-embedding-3-small
+func EmbeddingAPIKey(apiKey string) EmbeddingOption {
+	return func(c *modelConfig) {
+		c.ApiKey = pointer.String(apiKey)
+	}
+}
+
+// EmbeddingURL sets the url of a custom openai compatible embedding endpoint
+func EmbeddingURL(url string) EmbeddingOption {
+	return func(c *modelConfig) {
+		c.Url = pointer.String(url)
+	}
+}
+
+// EmbeddingPrefixes sets the prefixes prepended to the texts at indexing and query time
+func EmbeddingPrefixes(indexingPrefix, queryPrefix string) EmbeddingOption {
+	return func(c *modelConfig) {
+		c.IndexingPrefix = pointer.String(indexingPrefix)
+		c.QueryPrefix = pointer.String(queryPrefix)
+	}
+}
+
+// EmbeddingGCP configures a google cloud platform embedding model
+func EmbeddingGCP(projectID, accessToken, refreshToken, clientID, clientSecret string) EmbeddingOption {
+	return func(c *modelConfig) {
+		c.ProjectId = pointer.String(projectID)
+		c.AccessToken = pointer.String(accessToken)
+		c.RefreshToken = pointer.String(refreshToken)
+		c.ClientId = pointer.String(clientID)
+		c.ClientSecret = pointer.String(clientSecret)
+	}
+}
+
+// validateEmbedding checks that auto-embedding fields reference existing text fields
+func validateEmbedding(field api.Field, fields map[string]api.Field) []error {
+	if field.Embed == nil {
+		return nil
+	}
+
+	var errs []error
+	if field.Type != TypeFloatArray {
+		errs = append(errs, fmt.Errorf("embedding field %q must be of type %s", field.Name, TypeFloatArray))
+	}
+	if field.Embed.ModelConfig.ModelName == "" {
+		errs = append(errs, fmt.Errorf("embedding field %q has no model name", field.Name))
+	}
+	if len(field.Embed.From) == 0 {
+		errs = append(errs, fmt.Errorf("embedding field %q has no source fields", field.Name))
+	}
+	for _, from := range field.Embed.From {
+		source, ok := fields[from]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Errorf("embedding field %q references unknown field %q", field.Name, from))
+		case !slices.Contains([]string{TypeString, TypeStringArray}, source.Type):
+			errs = append(errs, fmt.Errorf("embedding field %q references non text field %q", field.Name, from))
+		}
+	}
+	return errs
+}
+
+// validateVector checks the dimensions and distance metric of vector fields
+func validateVector(field api.Field) []error {
+	var errs []error
+	if field.NumDim != nil {
+		if field.Type != TypeFloatArray {
+			errs = append(errs, fmt.Errorf("vector field %q must be of type %s", field.Name, TypeFloatArray))
+		}
+		if *field.NumDim < 1 {
+			errs = append(errs, fmt.Errorf("vector field %q must have positive dimensions", field.Name))
+		}
+	}
+	if field.VecDist != nil {
+		if field.NumDim == nil && field.Embed == nil {
+			errs = append(errs, fmt.Errorf("distance metric of field %q requires dimensions or an embedding", field.Name))
+		}
+		if !slices.Contains([]string{DistanceCosine, DistanceInnerProduct}, *field.VecDist) {
+			errs = append(errs, fmt.Errorf("vector field %q has unsupported distance metric %q", field.Name, *field.VecDist))
+		}
+	}
+	return errs
+}
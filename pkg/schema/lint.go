@@ -0,0 +1,154 @@
+package typesenseschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/typesense/typesense-go/v3/typesense/api"
+)
+
+// Lint rules
+const (
+	LintHighCardinalityFacet       = "high_cardinality_facet"
+	LintMissingDefaultSortingField = "missing_default_sorting_field"
+	LintQueryByNotIndexed          = "query_by_not_indexed"
+	LintSortableString             = "sortable_string"
+)
+
+// highCardinalityNames are field names whose values are usually unique per document, faceting on them builds
+// huge facet indices without useful counts
+var highCardinalityNames = []string{"id", "uri", "url", "path", "slug", "title", "name", "description", "body", "content", "text"}
+
+// LintFinding is a likely mistake in a schema or preset, unlike the errors of Validate typesense accepts it
+type LintFinding struct {
+	Rule string
+	// Index is the index of the schema, "" for findings of presets
+	Index string
+	// Preset is the name of the preset, "" for findings of schemas
+	Preset  string
+	Field   string
+	Message string
+}
+
+func (f LintFinding) String() string {
+	if f.Preset != "" {
+		return fmt.Sprintf("preset %s: %s: %s", f.Preset, f.Rule, f.Message)
+	}
+	return fmt.Sprintf("index %s: %s: %s", f.Index, f.Rule, f.Message)
+}
+
+// Lint checks the schemas by index and the presets by name for common mistakes: facets on string fields that are
+// likely unique per document, schemas without default sorting field, presets querying fields that are not indexed
+// or not strings and sortable string fields, which are expensive to keep in memory.
+// The findings are ordered by index, preset and field.
+func Lint(schemas map[string]*api.CollectionSchema, presets map[string]*api.PresetUpsertSchema) []LintFinding {
+	var findings []LintFinding
+
+	indices := make([]string, 0, len(schemas))
+	for index := range schemas {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	fields := map[string][]api.Field{}
+	for _, index := range indices {
+		schema := schemas[index]
+		if schema == nil {
+			continue
+		}
+		if schema.DefaultSortingField == nil || *schema.DefaultSortingField == "" {
+			findings = append(findings, LintFinding{
+				Rule:    LintMissingDefaultSortingField,
+				Index:   index,
+				Message: "no default_sorting_field, hits with equal text match are ordered arbitrarily",
+			})
+		}
+		for _, field := range schema.Fields {
+			fields[field.Name] = append(fields[field.Name], field)
+			if field.Type == TypeString && isTrue(field.Facet) && isHighCardinality(field.Name) {
+				findings = append(findings, LintFinding{
+					Rule:    LintHighCardinalityFacet,
+					Index:   index,
+					Field:   field.Name,
+					Message: fmt.Sprintf("facet on field %q, whose values are likely unique per document", field.Name),
+				})
+			}
+			if isString(field.Type) && isTrue(field.Sort) {
+				findings = append(findings, LintFinding{
+					Rule:    LintSortableString,
+					Index:   index,
+					Field:   field.Name,
+					Message: fmt.Sprintf("sortable string field %q is kept in memory, sort by a numeric field if possible", field.Name),
+				})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, field := range presetQueryBy(presets[name]) {
+			if reason := queryByProblem(fields[field]); reason != "" {
+				findings = append(findings, LintFinding{
+					Rule:    LintQueryByNotIndexed,
+					Preset:  name,
+					Field:   field,
+					Message: fmt.Sprintf("query_by field %q %s", field, reason),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// queryByProblem returns why the declarations of a field can't be searched or "" if they can,
+// fields missing in all schemas are left to Validate and typesenseapi.Preflight
+func queryByProblem(declarations []api.Field) string {
+	for _, field := range declarations {
+		switch {
+		case field.Index != nil && !*field.Index:
+			return "is not indexed"
+		case !isString(field.Type) && field.Type != TypeAuto && field.Embed == nil:
+			return fmt.Sprintf("has type %s, only string and embedding fields can be queried", field.Type)
+		}
+	}
+	return ""
+}
+
+// presetQueryBy returns the query_by fields of the preset
+func presetQueryBy(preset *api.PresetUpsertSchema) []string {
+	if preset == nil {
+		return nil
+	}
+	raw, err := preset.Value.MarshalJSON()
+	if err != nil {
+		return nil
+	}
+	var value struct {
+		QueryBy string `json:"query_by"`
+	}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil
+	}
+	var fields []string
+	for _, field := range strings.Split(value.QueryBy, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func isHighCardinality(name string) bool {
+	name = strings.ToLower(name)
+	return slices.Contains(highCardinalityNames, name) || strings.HasSuffix(name, "_id") || strings.HasSuffix(name, "_url")
+}
+
+func isTrue(value *bool) bool {
+	return value != nil && *value
+}
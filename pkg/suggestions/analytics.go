@@ -0,0 +1,50 @@
+package typesensesuggestions
+
+import (
+	"context"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	analyticsx "github.com/foomo/typesense/pkg/analytics"
+)
+
+// AnalyticsSource provides the top queries recorded by the analytics component,
+// leaving out queries that never returned any hits
+type AnalyticsSource struct {
+	analytics *analyticsx.Analytics
+	limit     int
+}
+
+func NewAnalyticsSource(analytics *analyticsx.Analytics, limit int) *AnalyticsSource {
+	return &AnalyticsSource{
+		analytics: analytics,
+		limit:     limit,
+	}
+}
+
+func (s *AnalyticsSource) Queries(ctx context.Context, indexID pkgx.IndexID) ([]Suggestion, error) {
+	topQueries, err := s.analytics.TopQueries(ctx, indexID, s.limit)
+	if err != nil {
+		return nil, err
+	}
+	zeroResultQueries, err := s.analytics.ZeroResultQueries(ctx, indexID, s.limit)
+	if err != nil {
+		return nil, err
+	}
+
+	zeroResultCounts := make(map[string]int, len(zeroResultQueries))
+	for _, stat := range zeroResultQueries {
+		zeroResultCounts[stat.Query] = stat.Count
+	}
+
+	suggestions := make([]Suggestion, 0, len(topQueries))
+	for _, stat := range topQueries {
+		if zeroResultCounts[stat.Query] >= stat.Count {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{
+			Query:      stat.Query,
+			Popularity: stat.Count,
+		})
+	}
+	return suggestions, nil
+}
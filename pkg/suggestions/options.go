@@ -0,0 +1,31 @@
+package typesensesuggestions
+
+import (
+	pkgx "github.com/foomo/typesense/pkg"
+)
+
+// Option configures the QuerySuggestions
+type Option func(o *options)
+
+type options struct {
+	clock pkgx.Clock
+}
+
+func newOptions(opts ...Option) options {
+	o := options{
+		clock: pkgx.SystemClock,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// WithClock replaces the system clock naming the collections, e.g. in tests
+func WithClock(clock pkgx.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
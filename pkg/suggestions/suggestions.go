@@ -0,0 +1,185 @@
+package typesensesuggestions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	typesenseapi "github.com/foomo/typesense/pkg/api"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+const collectionSuffix = "-queries"
+
+// Suggestion is a single query suggestion as stored in the suggestion collection
+type Suggestion struct {
+	ID         string `json:"id"`
+	Query      string `json:"query"`
+	Popularity int    `json:"popularity"`
+}
+
+// QuerySource provides the queries that should be suggested for an index
+type QuerySource interface {
+	Queries(ctx context.Context, indexID pkgx.IndexID) ([]Suggestion, error)
+}
+
+// QuerySuggestions maintains a collection per index behind the "<index>-queries" alias.
+// It is meant to be registered as an indexer extension so the suggestions are refreshed
+// after every committed revision.
+type QuerySuggestions struct {
+	l      *zap.Logger
+	client *typesense.Client
+	source QuerySource
+	opts   options
+}
+
+func NewQuerySuggestions(
+	l *zap.Logger,
+	client *typesense.Client,
+	source QuerySource,
+	opts ...Option,
+) *QuerySuggestions {
+	return &QuerySuggestions{
+		l:      l,
+		client: client,
+		source: source,
+		opts:   newOptions(opts...),
+	}
+}
+
+// CollectionName returns the name of the suggestion collection for the given index
+func CollectionName(indexID pkgx.IndexID) string {
+	return string(indexID) + collectionSuffix
+}
+
+// AfterCommit refreshes the suggestion collections of all given indices
+func (s *QuerySuggestions) AfterCommit(ctx context.Context, _ pkgx.RevisionID, indices []pkgx.IndexID) error {
+	for _, indexID := range indices {
+		if err := s.Refresh(ctx, indexID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Refresh builds a new suggestion collection of the given index from the query source and points the suggestion
+// alias at it, suggestions keep being served from the previous collection until the new one is complete
+func (s *QuerySuggestions) Refresh(ctx context.Context, indexID pkgx.IndexID) error {
+	suggestions, err := s.source.Queries(ctx, indexID)
+	if err != nil {
+		s.l.Error("failed to retrieve suggestion queries", zap.String("index", string(indexID)), zap.Error(err))
+		return err
+	}
+
+	alias := CollectionName(indexID)
+	collectionName := fmt.Sprintf("%s-%d", alias, s.opts.clock.Now().UnixNano())
+	_, err = s.client.Collections().Create(ctx, &api.CollectionSchema{
+		Name: collectionName,
+		Fields: []api.Field{
+			{Name: "query", Type: "string"},
+			{Name: "popularity", Type: "int32", Sort: pointer.True()},
+		},
+		DefaultSortingField: pointer.String("popularity"),
+	})
+	if err != nil {
+		s.l.Error("failed to create suggestion collection", zap.String("collection", collectionName), zap.Error(err))
+		return err
+	}
+
+	documents := make([]interface{}, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		query := normalizeQuery(suggestion.Query)
+		if query == "" {
+			continue
+		}
+		documents = append(documents, &Suggestion{
+			ID:         query,
+			Query:      query,
+			Popularity: suggestion.Popularity,
+		})
+	}
+	if len(documents) > 0 {
+		_, err = s.client.Collection(collectionName).Documents().Import(ctx, documents, &api.ImportDocumentsParams{
+			Action: (*api.IndexAction)(pointer.String("upsert")),
+		})
+		if err != nil {
+			s.l.Error("failed to import suggestions", zap.String("collection", collectionName), zap.Error(err))
+			if err := typesenseapi.DeleteCollection(ctx, s.client, collectionName); err != nil {
+				s.l.Warn("failed to delete suggestion collection", zap.String("collection", collectionName), zap.Error(err))
+			}
+			return err
+		}
+	}
+
+	if err := typesenseapi.SwapAlias(ctx, s.l, s.client, alias, collectionName); err != nil {
+		return err
+	}
+	s.l.Info("refreshed query suggestions", zap.String("collection", collectionName), zap.Int("queries", len(documents)))
+	return nil
+}
+
+// SuggestQueries returns the most popular queries starting with the given prefix.
+// An empty prefix returns the most popular queries overall, e.g. for the empty state of a search box.
+func (s *QuerySuggestions) SuggestQueries(ctx context.Context, indexID pkgx.IndexID, prefix string, limit int) ([]string, error) {
+	if limit < 1 {
+		limit = 10
+	}
+
+	params := &api.SearchCollectionParams{
+		Q:       pointer.String("*"),
+		QueryBy: pointer.String("query"),
+		SortBy:  pointer.String("popularity:desc"),
+		PerPage: pointer.Int(limit),
+	}
+	if prefix = normalizeQuery(prefix); prefix != "" {
+		params.Q = pointer.String(prefix)
+		params.Prefix = pointer.String("true")
+		params.SortBy = pointer.String("_text_match:desc,popularity:desc")
+	}
+
+	response, err := s.client.Collection(CollectionName(indexID)).Documents().Search(ctx, params)
+	if err != nil {
+		s.l.Error("failed to search query suggestions", zap.String("index", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+	if response.Hits == nil {
+		return nil, nil
+	}
+
+	queries := make([]string, 0, len(*response.Hits))
+	for _, hit := range *response.Hits {
+		if hit.Document == nil {
+			continue
+		}
+		if query, ok := (*hit.Document)["query"].(string); ok {
+			queries = append(queries, query)
+		}
+	}
+	return queries, nil
+}
+
+// StaticSource provides a fixed list of queries per index, ordered by descending popularity
+type StaticSource map[pkgx.IndexID][]string
+
+func (s StaticSource) Queries(_ context.Context, indexID pkgx.IndexID) ([]Suggestion, error) {
+	queries, ok := s[indexID]
+	if !ok {
+		return nil, fmt.Errorf("no suggestion queries configured for index %s", indexID)
+	}
+	suggestions := make([]Suggestion, len(queries))
+	for i, query := range queries {
+		suggestions[i] = Suggestion{
+			Query:      query,
+			Popularity: len(queries) - i,
+		}
+	}
+	return suggestions, nil
+}
+
+func normalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
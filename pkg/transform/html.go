@@ -0,0 +1,115 @@
+package typesensetransform
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// skippedElements contain no visible text
+var skippedElements = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Head:     true,
+	atom.Svg:      true,
+}
+
+// blockElements are separated by a space from their surrounding text
+var blockElements = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true, atom.Br: true,
+	atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true, atom.Figcaption: true,
+	atom.Footer: true, atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true,
+	atom.H4: true, atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true,
+	atom.Li: true, atom.Main: true, atom.Nav: true, atom.Ol: true, atom.P: true,
+	atom.Pre: true, atom.Section: true, atom.Table: true, atom.Td: true, atom.Th: true,
+	atom.Tr: true, atom.Ul: true,
+}
+
+var headingLevels = map[atom.Atom]int{
+	atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6,
+}
+
+// StripHTML returns the visible text of the given html with decoded entities and collapsed whitespace
+func StripHTML(s string) string {
+	var sb strings.Builder
+	skip := 0
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return CollapseWhitespace(sb.String())
+		case html.TextToken:
+			if skip == 0 {
+				sb.Write(tokenizer.Text())
+			}
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			if skippedElements[token.DataAtom] {
+				skip++
+			} else if blockElements[token.DataAtom] {
+				sb.WriteByte(' ')
+			}
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			if skippedElements[token.DataAtom] && skip > 0 {
+				skip--
+			} else if blockElements[token.DataAtom] {
+				sb.WriteByte(' ')
+			}
+		case html.SelfClosingTagToken:
+			if blockElements[tokenizer.Token().DataAtom] {
+				sb.WriteByte(' ')
+			}
+		case html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
+
+// ExtractHeadings returns the text of all headings of the given levels in document order,
+// all levels are returned if none are given
+func ExtractHeadings(s string, levels ...int) []string {
+	wanted := func(level int) bool {
+		if len(levels) == 0 {
+			return true
+		}
+		for _, l := range levels {
+			if l == level {
+				return true
+			}
+		}
+		return false
+	}
+
+	var (
+		headings []string
+		current  *strings.Builder
+		depth    int
+	)
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return headings
+		case html.TextToken:
+			if current != nil {
+				current.Write(tokenizer.Text())
+			}
+		case html.StartTagToken:
+			if level, ok := headingLevels[tokenizer.Token().DataAtom]; ok && wanted(level) && current == nil {
+				current = &strings.Builder{}
+				depth = level
+			}
+		case html.EndTagToken:
+			if level, ok := headingLevels[tokenizer.Token().DataAtom]; ok && current != nil && level == depth {
+				if heading := CollapseWhitespace(current.String()); heading != "" {
+					headings = append(headings, heading)
+				}
+				current = nil
+			}
+		case html.SelfClosingTagToken, html.CommentToken, html.DoctypeToken:
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package typesensetransform
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Func transforms a single text value
+type Func func(s string) string
+
+// Chain combines the given transformers, applying them from left to right
+func Chain(fns ...Func) Func {
+	return func(s string) string {
+		for _, fn := range fns {
+			s = fn(s)
+		}
+		return s
+	}
+}
+
+// Stage applies the transformer to the given string fields of every document.
+// The returned function can be used as typesenseindexing.DocumentStageFunc.
+func Stage[indexDocument any](
+	fn Func,
+	fields ...func(document *indexDocument) *string,
+) func(ctx context.Context, indexID pkgx.IndexID, documents []*indexDocument) ([]*indexDocument, error) {
+	return func(_ context.Context, _ pkgx.IndexID, documents []*indexDocument) ([]*indexDocument, error) {
+		for _, document := range documents {
+			if document == nil {
+				continue
+			}
+			for _, field := range fields {
+				if value := field(document); value != nil {
+					*value = fn(*value)
+				}
+			}
+		}
+		return documents, nil
+	}
+}
+
+// CollapseWhitespace replaces any sequence of whitespace with a single space and trims the result
+func CollapseWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Truncate shortens the text to at most n runes, cutting at the last word boundary if possible
+func Truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)[:n]
+	if i := strings.LastIndexFunc(string(runes), unicode.IsSpace); i > 0 {
+		return strings.TrimRightFunc(string(runes)[:i], unicode.IsSpace)
+	}
+	return string(runes)
+}
+
+// TruncateFunc returns Truncate with a fixed length for use in a Chain
+func TruncateFunc(n int) Func {
+	return func(s string) string {
+		return Truncate(s, n)
+	}
+}
+
+// invisibleReplacer removes or replaces characters that look like whitespace or nothing at all
+var invisibleReplacer = strings.NewReplacer(
+	"\u00a0", " ", // no-break space
+	"\u202f", " ", // narrow no-break space
+	"\u00ad", "", // soft hyphen
+	"\u200b", "", // zero width space
+	"\u200c", "", // zero width non-joiner
+	"\u200d", "", // zero width joiner
+	"\ufeff", "", // byte order mark
+)
+
+// NormalizeUnicode composes characters to NFC, e.g. "u" + combining diaeresis to "ü",
+// and removes invisible characters like soft hyphens and zero width spaces
+func NormalizeUnicode(s string) string {
+	return invisibleReplacer.Replace(norm.NFC.String(s))
+}
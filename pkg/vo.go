@@ -2,6 +2,10 @@ package typesense
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/typesense/typesense-go/v3/typesense/api"
 )
@@ -17,6 +21,18 @@ type Scores map[DocumentID]Score
 type Score struct {
 	ID    DocumentID
 	Index int
+	// Normalized is the score scaled to 0–1 if score normalization is enabled
+	Normalized float64
+	// Version tells which indexing run produced the hit, see typesenseapi.WithDocumentVersioning
+	Version DocumentVersion
+	// VectorDistance is the distance of the hit to the query vector of vector and hybrid searches
+	VectorDistance *float32
+}
+
+// DocumentVersion identifies the indexing run that imported a document
+type DocumentVersion struct {
+	RevisionID RevisionID
+	IndexedAt  time.Time
 }
 
 type DocumentProviderFunc[indexDocument any] func(
@@ -31,9 +47,260 @@ type DocumentInfo struct {
 	DocumentID   DocumentID
 }
 
+// Scope selects a part of an index for a partial rebuild, e.g. a restructured section of the site
+type Scope struct {
+	// Selector is interpreted by the provider, e.g. the ID of the node whose subtree is provided or a path prefix
+	Selector string
+	// FilterBy selects the documents of the scope in the index,
+	// documents matching it that are no longer provided are deleted
+	FilterBy string
+}
+
 type SearchParameters struct {
 	Query      string
 	Page       int
 	PresetName string
-	Modify     func(params *api.SearchCollectionParams)
+	// SortBy are comma separated criteria like "price:asc,rating:desc", validated against the schema
+	SortBy   string
+	FilterBy string
+	// GroupBy groups the hits by the given fields, GroupLimit limits the hits per group
+	// and GroupMissingValues controls if documents without a value form groups of their own
+	GroupBy            string
+	GroupLimit         int
+	GroupMissingValues *bool
+	// FacetBy requests the value counts of the given comma separated facet fields, see SearchResult.Facets,
+	// MaxFacetValues limits the values per field
+	FacetBy        string
+	MaxFacetValues int
+	// Stopwords is the name of a stopwords set whose words are removed from the query, see typesenseapi.WithStopwords
+	Stopwords string
+	// Profile selects a projection profile of the index, see typesenseapi.WithProjectionProfiles
+	Profile string
+	// Joins filter by and include the documents of referenced indices, see typesenseschema.Reference
+	Joins []Join
+	// Overrides are typesense search parameters by name merged on top of the preset, e.g. {"per_page": 50}.
+	// The fields above and Modify take precedence, a "filter_by" override is combined with the preset filter.
+	Overrides map[string]any
+	Modify    func(params *api.SearchCollectionParams) `json:"-"`
+}
+
+// Join joins the documents of a referenced index into a search, the included fields are nested
+// under the name of the referenced index in the hits
+type Join struct {
+	IndexID IndexID
+	// FilterBy restricts the hits to documents whose referenced document matches, e.g. "country:=DE"
+	FilterBy string
+	// IncludeFields are the fields of the referenced document to include, "*" includes all
+	IncludeFields []string
+}
+
+// SearchResult wraps the converted documents of a search together with additional response information
+type SearchResult[returnType any] struct {
+	Results []returnType
+	Scores  Scores
+	Total   int
+	// Facets holds the facet counts of the requested facet fields by field name
+	Facets      map[string]Facet
+	Suggestions []string
+	Pagination  Pagination
+	// Partial is set if typesense stopped searching because the latency budget was spent, the hits are incomplete,
+	// see typesenseapi.WithLatencyBudget
+	Partial bool
+	// Redirect is the URL to navigate to instead of showing results, the search is skipped if it is set
+	Redirect string
+	// Fallback is the name of the fallback whose relaxed search returned the results, "" if the search itself did,
+	// see typesenseapi.WithFallbacks
+	Fallback string
+	// ConversionErrors lists the hits that could not be converted and are missing in Results
+	ConversionErrors []ConversionError
+	// Highlights holds the highlighted snippets of the matched fields of the hits by document ID and field,
+	// e.g. "<mark>red</mark> shoes"
+	Highlights map[DocumentID]map[string][]string
+	// Metadata holds auxiliary information attached to the hits by their document ID, e.g. badges,
+	// tracking payloads or debug information, see AttachMetadata
+	Metadata map[DocumentID]Metadata
+	// Debug describes the executed search if debugging was requested, see typesenseapi.WithSearchDebug
+	Debug *SearchDebug
+}
+
+// SearchDebug describes the search sent to typesense and the ranking of its hits
+type SearchDebug struct {
+	Collection string `json:"collection"`
+	// Parameters are the parameters sent to typesense, including defaults, curation and enforced filters
+	Parameters *api.SearchCollectionParams `json:"parameters"`
+	// SearchTime is the duration reported by typesense, Duration the duration including the network round trip
+	SearchTime time.Duration `json:"search_time"`
+	Duration   time.Duration `json:"duration"`
+	Cutoff     bool          `json:"cutoff"`
+	// Hits are the hits of the returned response, i.e. of the fallback search if a fallback recovered the search
+	Hits []HitDebug `json:"hits"`
+	// Fallbacks are the fallback searches executed after the search returned no hits
+	Fallbacks []FallbackDebug `json:"fallbacks,omitempty"`
+}
+
+// FallbackDebug describes a fallback search sent to typesense
+type FallbackDebug struct {
+	Name       string                      `json:"name"`
+	Parameters *api.SearchCollectionParams `json:"parameters"`
+	Duration   time.Duration               `json:"duration"`
+	Found      int                         `json:"found"`
+}
+
+// HitDebug holds the ranking information typesense returned for a hit
+type HitDebug struct {
+	Position          int            `json:"position"`
+	DocumentID        DocumentID     `json:"document_id"`
+	TextMatch         int64          `json:"text_match"`
+	BestFieldScore    string         `json:"best_field_score,omitempty"`
+	BestFieldWeight   int            `json:"best_field_weight,omitempty"`
+	FieldsMatched     int            `json:"fields_matched,omitempty"`
+	TokensMatched     int            `json:"tokens_matched,omitempty"`
+	TypoPrefixScore   int            `json:"typo_prefix_score,omitempty"`
+	NumTokensDropped  int            `json:"num_tokens_dropped,omitempty"`
+	VectorDistance    *float32       `json:"vector_distance,omitempty"`
+	GeoDistanceMeters map[string]int `json:"geo_distance_meters,omitempty"`
+}
+
+// Metadata is auxiliary information attached to a search hit
+type Metadata map[string]any
+
+// AttachMetadata sets the metadata value of the hit with the given document ID, e.g. in result processors
+func (r *SearchResult[returnType]) AttachMetadata(documentID DocumentID, key string, value any) {
+	if r.Metadata == nil {
+		r.Metadata = map[DocumentID]Metadata{}
+	}
+	if r.Metadata[documentID] == nil {
+		r.Metadata[documentID] = Metadata{}
+	}
+	r.Metadata[documentID][key] = value
 }
+
+// ConversionError describes a search hit that could not be decoded or converted
+type ConversionError struct {
+	// Position is the position of the hit in the search response
+	Position int
+	// DocumentID is the "id" of the hit if it is known
+	DocumentID DocumentID
+	Err        error
+}
+
+func (e ConversionError) Error() string {
+	return fmt.Sprintf("failed to convert hit %d (%s): %v", e.Position, e.DocumentID, e.Err)
+}
+
+func (e ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnsupported is returned for optional operations, e.g. CanaryDeployer, the decorated API does not implement
+var ErrUnsupported = errors.New("operation not supported by the typesense api")
+
+// ErrPinned is matched by errors of operations refused because the index is pinned to its revision
+var ErrPinned = errors.New("revision is pinned")
+
+// PinnedError is returned by CommitRevision if all indices were committed except the pinned ones,
+// which keep serving their revision
+type PinnedError struct {
+	Indices []IndexID
+}
+
+func (e *PinnedError) Error() string {
+	indices := make([]string, len(e.Indices))
+	for i, indexID := range e.Indices {
+		indices[i] = string(indexID)
+	}
+	return fmt.Sprintf("%v: %s", ErrPinned, strings.Join(indices, ", "))
+}
+
+func (e *PinnedError) Is(target error) bool {
+	return target == ErrPinned
+}
+
+// Facet holds the value counts of a facet field
+type Facet struct {
+	Field  string       `json:"field"`
+	Values []FacetValue `json:"values"`
+	// Stats are only returned for numeric fields
+	Stats *FacetStats `json:"stats,omitempty"`
+}
+
+// FacetValue is the number of matching documents with the given value
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// FacetStats summarizes the values of a numeric facet field
+type FacetStats struct {
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Avg         float64 `json:"avg"`
+	Sum         float64 `json:"sum"`
+	TotalValues int     `json:"total_values"`
+}
+
+// Pagination describes the position of a search result page
+type Pagination struct {
+	Page       int  `json:"page"`
+	PerPage    int  `json:"per_page"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasPrev    bool `json:"has_prev"`
+	HasNext    bool `json:"has_next"`
+	// NextOffset is the offset of the first hit of the next page, 0 if there is none
+	NextOffset int `json:"next_offset"`
+}
+
+// NewPagination computes the pagination of a page, maxHits limits the reachable hits if greater than 0
+func NewPagination(page, perPage, total, maxHits int) Pagination {
+	p := Pagination{
+		Page:    max(page, 1),
+		PerPage: max(perPage, 1),
+		Total:   total,
+	}
+	reachable := total
+	if maxHits > 0 {
+		reachable = min(total, maxHits)
+	}
+	p.TotalPages = (reachable + p.PerPage - 1) / p.PerPage
+	p.HasPrev = p.Page > 1
+	p.HasNext = p.Page < p.TotalPages
+	if p.HasNext {
+		p.NextOffset = p.Page * p.PerPage
+	}
+	return p
+}
+
+// IndexedSearchRequest is a search on an index of a multi search, see typesenseapi.MultiSearch
+type IndexedSearchRequest struct {
+	IndexID    IndexID
+	Parameters *api.SearchCollectionParams
+}
+
+// MultiSearchHit is a single converted document of a search spanning several indices
+type MultiSearchHit[returnType any] struct {
+	IndexID  IndexID
+	Document returnType
+	Score    Score
+	// NormalizedScore is the score relative to the best hit of the same index, between 0 and 1
+	NormalizedScore float64
+}
+
+// MultiSearchResult contains the merged hits of a search spanning several indices
+type MultiSearchResult[returnType any] struct {
+	Hits   []MultiSearchHit[returnType]
+	Totals map[IndexID]int
+	Total  int
+	// Partial lists the indices whose search exceeded the latency budget and returned partial hits
+	Partial []IndexID
+}
+
+// ClockFunc adapts a function to the Clock interface
+type ClockFunc func() time.Time
+
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the default Clock returning the current system time
+var SystemClock Clock = ClockFunc(time.Now)
@@ -0,0 +1,133 @@
+package typesensevocabulary
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"unicode"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	"go.uber.org/zap"
+)
+
+// minTermLength excludes single characters from the vocabulary
+const minTermLength = 2
+
+// TextFunc returns the text of the document the vocabulary is extracted from, e.g. title and body
+type TextFunc[indexDocument any] func(document *indexDocument) string
+
+// Collector extracts the terms and their frequencies from the provided documents during indexing and replaces the
+// vocabulary collections once the revision has been committed. Add Middleware to the provider pipeline and register
+// the collector as indexer extension.
+type Collector[indexDocument any] struct {
+	l            *zap.Logger
+	vocabulary   *Vocabulary
+	textFunc     TextFunc[indexDocument]
+	minFrequency int
+	mu           sync.Mutex
+	terms        map[pkgx.IndexID]map[string]int
+}
+
+// NewCollector creates a collector, terms occurring less than minFrequency times are left out
+func NewCollector[indexDocument any](
+	l *zap.Logger,
+	vocabulary *Vocabulary,
+	textFunc TextFunc[indexDocument],
+	minFrequency int,
+) *Collector[indexDocument] {
+	return &Collector[indexDocument]{
+		l:            l,
+		vocabulary:   vocabulary,
+		textFunc:     textFunc,
+		minFrequency: minFrequency,
+		terms:        map[pkgx.IndexID]map[string]int{},
+	}
+}
+
+// Middleware returns the provider middleware collecting the terms of all provided documents.
+// The terms of an index are reset whenever its documents are provided from the start.
+func (c *Collector[indexDocument]) Middleware() pkgx.DocumentProviderMiddleware[indexDocument] {
+	return func(next pkgx.DocumentProvider[indexDocument]) pkgx.DocumentProvider[indexDocument] {
+		return &collectingProvider[indexDocument]{next: next, collector: c}
+	}
+}
+
+// AfterCommit replaces the vocabulary collections of the committed indices with the collected terms
+func (c *Collector[indexDocument]) AfterCommit(ctx context.Context, _ pkgx.RevisionID, indices []pkgx.IndexID) error {
+	for _, indexID := range indices {
+		c.mu.Lock()
+		terms, ok := c.terms[indexID]
+		delete(c.terms, indexID)
+		c.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		for term, frequency := range terms {
+			if frequency < c.minFrequency {
+				delete(terms, term)
+			}
+		}
+		if err := c.vocabulary.Replace(ctx, indexID, terms); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collect counts the terms of the documents
+func (c *Collector[indexDocument]) collect(indexID pkgx.IndexID, documents []*indexDocument, reset bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	terms, ok := c.terms[indexID]
+	if !ok || reset {
+		terms = map[string]int{}
+		c.terms[indexID] = terms
+	}
+	for _, document := range documents {
+		if document == nil {
+			continue
+		}
+		for _, term := range Tokenize(c.textFunc(document)) {
+			terms[term]++
+		}
+	}
+}
+
+// Tokenize splits the text into lower case terms of letters and digits, dropping single characters and numbers
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := fields[:0]
+	for _, field := range fields {
+		if len([]rune(field)) < minTermLength || strings.IndexFunc(field, unicode.IsLetter) < 0 {
+			continue
+		}
+		terms = append(terms, field)
+	}
+	return terms
+}
+
+type collectingProvider[indexDocument any] struct {
+	next      pkgx.DocumentProvider[indexDocument]
+	collector *Collector[indexDocument]
+}
+
+func (p *collectingProvider[indexDocument]) Provide(ctx context.Context, indexID pkgx.IndexID) ([]*indexDocument, error) {
+	documents, err := p.next.Provide(ctx, indexID)
+	if err != nil {
+		return nil, err
+	}
+	p.collector.collect(indexID, documents, true)
+	return documents, nil
+}
+
+func (p *collectingProvider[indexDocument]) ProvidePaged(ctx context.Context, indexID pkgx.IndexID, offset int) ([]*indexDocument, int, error) {
+	documents, nextOffset, err := p.next.ProvidePaged(ctx, indexID, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	p.collector.collect(indexID, documents, offset == 0)
+	return documents, nextOffset, nil
+}
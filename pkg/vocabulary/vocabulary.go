@@ -0,0 +1,228 @@
+package typesensevocabulary
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pkgx "github.com/foomo/typesense/pkg"
+	typesenseapi "github.com/foomo/typesense/pkg/api"
+	"github.com/typesense/typesense-go/v3/typesense"
+	"github.com/typesense/typesense-go/v3/typesense/api"
+	"github.com/typesense/typesense-go/v3/typesense/api/pointer"
+	"go.uber.org/zap"
+)
+
+const collectionSuffix = "-vocabulary"
+
+// Term is a single vocabulary entry with its number of occurrences in the index
+type Term struct {
+	ID        string `json:"id"`
+	Term      string `json:"term"`
+	Frequency int    `json:"frequency"`
+}
+
+// Vocabulary manages a term collection per index behind an alias and provides "did you mean" suggestions
+// by running typo tolerant queries against it
+type Vocabulary struct {
+	l              *zap.Logger
+	client         *typesense.Client
+	maxSuggestions int
+	opts           options
+}
+
+func NewVocabulary(
+	l *zap.Logger,
+	client *typesense.Client,
+	maxSuggestions int,
+	opts ...Option,
+) *Vocabulary {
+	if maxSuggestions < 1 {
+		maxSuggestions = 1
+	}
+	return &Vocabulary{
+		l:              l,
+		client:         client,
+		maxSuggestions: maxSuggestions,
+		opts:           newOptions(opts...),
+	}
+}
+
+// CollectionName returns the name of the vocabulary collection for the given index
+func CollectionName(indexID pkgx.IndexID) string {
+	return string(indexID) + collectionSuffix
+}
+
+// Replace builds a new vocabulary collection of the given index with the given terms and frequencies and points the
+// vocabulary alias at it, lookups keep using the previous terms until the new collection is complete
+func (v *Vocabulary) Replace(ctx context.Context, indexID pkgx.IndexID, terms map[string]int) error {
+	alias := CollectionName(indexID)
+	collectionName := fmt.Sprintf("%s-%d", alias, v.opts.clock.Now().UnixNano())
+
+	_, err := v.client.Collections().Create(ctx, &api.CollectionSchema{
+		Name: collectionName,
+		Fields: []api.Field{
+			{Name: "term", Type: "string"},
+			{Name: "frequency", Type: "int32", Sort: pointer.True()},
+		},
+		DefaultSortingField: pointer.String("frequency"),
+	})
+	if err != nil {
+		v.l.Error("failed to create vocabulary collection", zap.String("collection", collectionName), zap.Error(err))
+		return err
+	}
+
+	documents := make([]interface{}, 0, len(terms))
+	for term, frequency := range terms {
+		if term == "" {
+			continue
+		}
+		documents = append(documents, &Term{
+			ID:        term,
+			Term:      term,
+			Frequency: frequency,
+		})
+	}
+	if len(documents) > 0 {
+		_, err = v.client.Collection(collectionName).Documents().Import(ctx, documents, &api.ImportDocumentsParams{
+			Action: (*api.IndexAction)(pointer.String("upsert")),
+		})
+		if err != nil {
+			v.l.Error("failed to import vocabulary", zap.String("collection", collectionName), zap.Error(err))
+			if err := typesenseapi.DeleteCollection(ctx, v.client, collectionName); err != nil {
+				v.l.Warn("failed to delete vocabulary collection", zap.String("collection", collectionName), zap.Error(err))
+			}
+			return err
+		}
+	}
+
+	if err := typesenseapi.SwapAlias(ctx, v.l, v.client, alias, collectionName); err != nil {
+		return err
+	}
+	v.l.Info("updated vocabulary", zap.String("collection", collectionName), zap.Int("terms", len(documents)))
+	return nil
+}
+
+// Suggest returns corrected versions of the given query. Every token is replaced by the most frequent
+// vocabulary term within typo distance, tokens that are known or have no match are kept as they are.
+func (v *Vocabulary) Suggest(ctx context.Context, indexID pkgx.IndexID, query string) ([]string, error) {
+	tokens := strings.Fields(strings.ToLower(query))
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	// single token queries may return several alternatives, multi token queries one corrected query
+	limit := 1
+	if len(tokens) == 1 {
+		limit = v.maxSuggestions
+	}
+
+	corrected := false
+	alternatives := make([][]string, len(tokens))
+	for i, token := range tokens {
+		terms, err := v.lookup(ctx, indexID, token, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(terms) == 0 || terms[0] == token {
+			alternatives[i] = []string{token}
+			continue
+		}
+		corrected = true
+		alternatives[i] = terms
+	}
+
+	if !corrected {
+		return nil, nil
+	}
+
+	if len(tokens) == 1 {
+		return alternatives[0], nil
+	}
+
+	suggestion := make([]string, len(tokens))
+	for i, terms := range alternatives {
+		suggestion[i] = terms[0]
+	}
+	return []string{strings.Join(suggestion, " ")}, nil
+}
+
+// UnknownTokens returns the tokens of the query that are not part of the vocabulary of the index
+func (v *Vocabulary) UnknownTokens(ctx context.Context, indexID pkgx.IndexID, query string) ([]string, error) {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	values := make([]any, len(tokens))
+	for i, token := range tokens {
+		values[i] = token
+	}
+	response, err := v.client.Collection(CollectionName(indexID)).Documents().Search(ctx, &api.SearchCollectionParams{
+		Q:        pointer.String("*"),
+		FilterBy: pointer.String(pkgx.NewFilterBuilder().In("term", values...).Build()),
+		PerPage:  pointer.Int(len(tokens)),
+	})
+	if err != nil {
+		v.l.Warn("failed to query vocabulary", zap.String("index", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	if response.Hits != nil {
+		for _, hit := range *response.Hits {
+			if hit.Document == nil {
+				continue
+			}
+			if term, ok := (*hit.Document)["term"].(string); ok {
+				known[term] = true
+			}
+		}
+	}
+	var unknown []string
+	for _, token := range tokens {
+		if !known[token] {
+			unknown = append(unknown, token)
+		}
+	}
+	return unknown, nil
+}
+
+// IsUnknownQuery checks if the query consists entirely of tokens that do not occur in the index,
+// e.g. to show "did you mean" suggestions instead of searching
+func (v *Vocabulary) IsUnknownQuery(ctx context.Context, indexID pkgx.IndexID, query string) (bool, error) {
+	unknown, err := v.UnknownTokens(ctx, indexID, query)
+	if err != nil {
+		return false, err
+	}
+	return len(unknown) > 0 && len(unknown) == len(Tokenize(query)), nil
+}
+
+func (v *Vocabulary) lookup(ctx context.Context, indexID pkgx.IndexID, token string, limit int) ([]string, error) {
+	response, err := v.client.Collection(CollectionName(indexID)).Documents().Search(ctx, &api.SearchCollectionParams{
+		Q:        pointer.String(token),
+		QueryBy:  pointer.String("term"),
+		NumTypos: pointer.String("2"),
+		Prefix:   pointer.String("false"),
+		SortBy:   pointer.String("_text_match:desc,frequency:desc"),
+		PerPage:  pointer.Int(limit),
+	})
+	if err != nil {
+		v.l.Warn("failed to query vocabulary", zap.String("index", string(indexID)), zap.Error(err))
+		return nil, err
+	}
+	if response.Hits == nil {
+		return nil, nil
+	}
+
+	terms := make([]string, 0, len(*response.Hits))
+	for _, hit := range *response.Hits {
+		if hit.Document == nil {
+			continue
+		}
+		if term, ok := (*hit.Document)["term"].(string); ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms, nil
+}